@@ -26,10 +26,58 @@ var ApplyCmd = &cobra.Command{
 func init() {
 	ApplyCmd.Flags().StringVarP(&options.SimonConfig, "simon-config", "f", options.SimonConfig, "path to the cluster kube-config file used to connect cluster, one of both kube-config and cluster-config must exist.")
 	ApplyCmd.Flags().StringVar(&options.DefaultSchedulerConfigFile, "default-scheduler-config", options.DefaultSchedulerConfigFile, "path to JSON or YAML file containing scheduler configuration.")
+	ApplyCmd.Flags().StringVar(&options.DumpSchedulerConfig, "dump-scheduler-config", options.DumpSchedulerConfig, "write the effective KubeSchedulerConfiguration the simulation runs with, after merging the config file and the simulator plugins, as YAML to the given file, '-' for stdout")
 	ApplyCmd.Flags().StringVar(&options.OutputFile, "output-file", options.OutputFile, "save report to output file.")
+	ApplyCmd.Flags().StringVar(&options.OutputPath, "output-path", options.OutputPath, "save report to the given path; a directory gets one file per app list named after the deployed apps, any other path is truncated and rewritten")
 	ApplyCmd.Flags().BoolVar(&options.UseGreed, "use-greed", false, "use greedy algorithm when queue pods")
 	ApplyCmd.Flags().BoolVarP(&options.Interactive, "interactive", "i", false, "interactive mode")
-	ApplyCmd.Flags().StringSliceVar(&options.ExtendedResources, "extended-resources", nil, "show extended resources when reporting, e.g. open-local")
+	ApplyCmd.Flags().StringSliceVar(&options.Namespaces, "namespaces", nil, "restrict the Pod Info and App Info rows to pods in these namespaces; node totals still account for all pods")
+	ApplyCmd.Flags().StringSliceVar(&options.ExtendedResources, "extended-resources", nil, "show extended resources when reporting: open-local, gpu, ephemeral-storage, or any node-advertised resource name like nvidia.com/gpu")
+	ApplyCmd.Flags().BoolVar(&options.ValidateConstraints, "validate-constraints", false, "verify after scheduling that no placement violates required pod affinity/anti-affinity")
+	ApplyCmd.Flags().StringSliceVar(&options.ResourceWeights, "resource-weights", nil, "resource weights used by the packing analysis, e.g. cpu=1,memory=2,nvidia.com/gpu=4")
+	ApplyCmd.Flags().BoolVar(&options.ValidateOnly, "validate-only", false, "only validate the config, charts and manifests without running the simulation")
+	ApplyCmd.Flags().StringVar(&options.RecommendationsFile, "recommendations-file", options.RecommendationsFile, "export VPA-style allocation recommendations per workload to the given file")
+	ApplyCmd.Flags().StringVar(&options.Output, "output", options.Output, "output mode, 'count' prints only the number of added nodes with the verdict in the exit code, 'describe' prints a kubectl-describe-like block per node, 'bars' prints one line per node with compact utilization bars, 'json' and 'yaml' print a versioned machine-readable report suitable for piping, 'csv' emits the node and pod tables for spreadsheets, 'markdown' emits them as GitHub-flavored Markdown for PR comments and wikis, 'html' renders a self-contained HTML page with summary cards and collapsible node sections")
+	ApplyCmd.Flags().BoolVar(&options.AnalyzeStartup, "analyze-startup", false, "report nodes where concurrent pod startup demand (init containers included) exceeds the steady-state demand of their pods")
+	ApplyCmd.Flags().StringSliceVar(&options.PodOverhead, "pod-overhead", nil, "per-pod resource tax accounted on top of requests, e.g. cpu=100m,memory=50Mi")
+	ApplyCmd.Flags().StringSliceVar(&options.SystemOverhead, "system-overhead", nil, "per-node capacity reserved for system pods (kube-proxy, CNI, node-local DNS) on every node including generated ones, e.g. cpu=200m,memory=250Mi")
+	ApplyCmd.Flags().StringVar(&options.PoolLabel, "pool-label", options.PoolLabel, "node label the pool rollup groups by, defaults to node.kubernetes.io/instance-type")
+	ApplyCmd.Flags().BoolVar(&options.Strict, "strict", false, "treat warnings emitted during validation, decoding or simulation as errors")
+	ApplyCmd.Flags().BoolVar(&options.DaemonSetCoverage, "daemonset-coverage", false, "skip the simulation and report per DaemonSet how many intended nodes it covers")
+	ApplyCmd.Flags().BoolVar(&options.IgnorePendingPods, "ignore-pending-pods", false, "drop pods from the cluster snapshot that are still pending instead of scheduling them")
+	ApplyCmd.Flags().StringVar(&options.IgnorePods, "ignore-pods", "", "label selector of pods to exclude from scheduling and accounting, e.g. planning.skip=true; the capacity they would use stays free")
+	ApplyCmd.Flags().StringVar(&options.ScoreDumpFile, "score-dump", options.ScoreDumpFile, "write the per-pod, per-node, per-plugin scheduler scores as CSV to the given file (can be large)")
+	ApplyCmd.Flags().StringVar(&options.DebugDumpFile, "debug-dump", options.DebugDumpFile, "write the raw per-pod scheduler framework outcome (prefilter status, per-node filter verdicts, chosen node) as JSON to the given file (can be large)")
+	ApplyCmd.Flags().StringVar(&options.Webhook, "webhook", options.Webhook, "POST the structured JSON result to the given URL after the run")
+	ApplyCmd.Flags().StringSliceVar(&options.WebhookHeaders, "webhook-header", nil, "extra header sent with the webhook request as Name=Value, e.g. Authorization=Bearer xyz")
+	ApplyCmd.Flags().StringSliceVar(&options.MaxUtilization, "max-utilization", nil, "refuse placements that push a node above the given utilization percent, e.g. cpu=80,memory=90")
+	ApplyCmd.Flags().StringVar(&options.AttributionFile, "attribution", "", "write a nested JSON attribution of requests by the --attribution-label hierarchy, suitable for a treemap or flamegraph")
+	ApplyCmd.Flags().StringSliceVar(&options.AttributionLabels, "attribution-label", nil, "pod labels forming the attribution hierarchy, outermost first, e.g. team,app")
+	ApplyCmd.Flags().StringVar(&options.ExportKwokDir, "export-kwok", options.ExportKwokDir, "write the simulated nodes and placed pods as kwok-loadable manifests into the given directory")
+	ApplyCmd.Flags().StringVar(&options.BundleOnFailure, "bundle-on-failure", "", "directory that receives a reproducer bundle (config, cluster snapshot, app manifests, scheduler config, diagnostics) when the run fails")
+	ApplyCmd.Flags().StringVar(&options.Expander, "expander", "", "cluster-autoscaler expander strategy used to pick the node template at each scale-out step: random, most-pods, least-waste or best-fit; best-fit is the default when several templates are parsed")
+	ApplyCmd.Flags().StringVar(&options.VGSelectionPolicy, "vg-selection-policy", "", "VG selection policy for open-local volumes: first-fit, least-allocated or most-allocated")
+	ApplyCmd.Flags().StringVar(&options.ScoringStrategy, "scoring-strategy", "", "node resource scoring strategy: most-allocated packs pods onto the fewest nodes, least-allocated (the default) spreads them, balanced levels cpu and memory usage; only node ranking changes, --use-greed pod ordering is unaffected")
+	ApplyCmd.Flags().StringVar(&options.SuccessCriteria, "success-criteria", "", "success criteria expression evaluated once all pods fit, e.g. 'cpu<=85 && node-memory<90'; metrics: cpu, memory, vg, gpu, node-cpu, node-memory; replaces the MaxCPU/MaxMemory/MaxVG env thresholds")
+	ApplyCmd.Flags().StringVar(&options.ShapeCatalogFile, "cheapest-node-shape", "", "node shape catalog file; packs the apps greenfield onto every listed shape and reports the cheapest one instead of simulating against the cluster")
+	ApplyCmd.Flags().StringSliceVar(&options.EvictionThresholds, "eviction-threshold", nil, "kubelet-style eviction thresholds, e.g. 'memory.available<500Mi,nodefs.available<10%'; flags nodes whose requests approach eviction levels")
+	ApplyCmd.Flags().StringVar(&options.DrainNode, "drain-node", "", "simulate draining the given node and report whether its pods fit on the remaining nodes")
+	ApplyCmd.Flags().StringSliceVar(&options.RequestMultipliers, "request-multiplier", nil, "scale an app's resource requests without changing its replica counts, e.g. canary-app=0.1")
+	ApplyCmd.Flags().BoolVar(&options.TerminationOverlap, "termination-overlap", false, "with --drain-node, keep the drained pods' capacity occupied while their replacements are placed, capturing the peak demand of graceful termination")
+	ApplyCmd.Flags().StringVar(&options.RemoveNode, "remove-node", "", "simulate removing the given node entirely; reports pods that cannot be rescheduled and PodDisruptionBudgets the simultaneous eviction would violate")
+	ApplyCmd.Flags().BoolVar(&options.Deschedule, "deschedule", false, "after the simulation, rebalance pods from nodes above 80% utilization onto nodes below 20% and report the before/after utilization distribution")
+	ApplyCmd.Flags().StringSliceVar(&options.ScalablePools, "scalable-pools", nil, "restrict the scaling loop to new-node templates of these pools; a template's pool is its pool-label value, or its name when unlabeled")
+	ApplyCmd.Flags().IntVar(&options.Repeat, "repeat", 1, "re-run the whole simulation this many times and report pods whose placement differs between runs")
+	ApplyCmd.Flags().IntVar(&options.MaxNewNodes, "max-new-nodes", 0, "cap on the number of nodes the scaling loop may add, 0 means the built-in default of 100")
+	ApplyCmd.Flags().StringVar(&options.NodeNamePrefix, "node-name-prefix", "", "DNS-compatible name prefix of the nodes the scaling loop generates, so they do not collide with real nodes named simon-*; a template's namePrefix takes precedence")
+	ApplyCmd.Flags().BoolVar(&options.PrioritySort, "priority-sort", false, "queue pods by pod priority first and resource size second")
+	ApplyCmd.Flags().StringVar(&options.PodOrder, "pod-order", "", "order pods are fed to the scheduler within each app: greedy (dominant share of cluster capacity, what --use-greed selected), largest-first (descending cpu+memory requests), app-declared (manifest order, skips the built-in pre-sorts), priority-class (descending pod priority)")
+	ApplyCmd.Flags().BoolVar(&options.ExplainScale, "explain-scale", false, "explain per added node which previously unscheduled pods it enabled")
+	ApplyCmd.Flags().BoolVar(&options.VerboseFailures, "verbose-failures", false, "break scheduling failures down to which filter plugin rejected the pod on which node")
+	ApplyCmd.Flags().StringVar(&options.MetricsFile, "metrics-file", options.MetricsFile, "write Prometheus exposition-format metrics of the run to the given file, '-' for stdout")
+	ApplyCmd.Flags().StringVar(&options.ExportResultFile, "export-result", options.ExportResultFile, "write the raw simulation result as JSON to the given file, for later comparison with simon diff")
+	ApplyCmd.Flags().BoolVar(&options.NoChartCache, "no-chart-cache", false, "always re-render helm charts instead of reusing the on-disk cache of a previous run")
+	ApplyCmd.Flags().StringSliceVar(&options.DisplayUnits, "display-unit", nil, "render report quantities in fixed units, e.g. memory=Gi,cpu=m (units: Gi, Mi, m, cores)")
 
 	if err := ApplyCmd.MarkFlagRequired("simon-config"); err != nil {
 		log.Fatal("failed to init ApplyCmd on simon-config flag")
@@ -0,0 +1,37 @@
+package batch
+
+import (
+	"os"
+
+	applypkg "github.com/alibaba/open-simulator/pkg/apply"
+	"github.com/pterm/pterm"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var options = applypkg.Options{}
+var configsFile string
+
+var BatchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Run many simon configs in one invocation and print a combined summary",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := applypkg.RunBatch(configsFile, options); err != nil {
+			pterm.FgRed.Printf("batch error: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	BatchCmd.Flags().StringVar(&configsFile, "configs", configsFile, "path to a file listing one simon config per line, lines starting with # are skipped")
+	BatchCmd.Flags().StringVar(&options.DefaultSchedulerConfigFile, "default-scheduler-config", options.DefaultSchedulerConfigFile, "path to JSON or YAML file containing scheduler configuration.")
+	BatchCmd.Flags().BoolVar(&options.UseGreed, "use-greed", false, "use greedy algorithm when queue pods")
+	BatchCmd.Flags().StringSliceVar(&options.PodOverhead, "pod-overhead", nil, "per-pod resource tax accounted on top of requests, e.g. cpu=100m,memory=50Mi")
+	BatchCmd.Flags().BoolVar(&options.IgnorePendingPods, "ignore-pending-pods", false, "drop pods from the cluster snapshot that are still pending instead of scheduling them")
+	BatchCmd.Flags().StringSliceVar(&options.MaxUtilization, "max-utilization", nil, "refuse placements that push a node above the given utilization percent, e.g. cpu=80,memory=90")
+
+	if err := BatchCmd.MarkFlagRequired("configs"); err != nil {
+		log.Fatal("failed to init BatchCmd on configs flag")
+	}
+}
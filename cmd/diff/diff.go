@@ -0,0 +1,78 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+var DiffCmd = &cobra.Command{
+	Use:   "diff <result-a.json> <result-b.json>",
+	Short: "Compare two simulation results exported with simon apply --export-result",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := run(args[0], args[1]); err != nil {
+			pterm.FgRed.Printf("diff error: %s\n", err.Error())
+			os.Exit(1)
+		}
+	},
+}
+
+func run(pathA, pathB string) error {
+	resultA, err := loadResult(pathA)
+	if err != nil {
+		return err
+	}
+	resultB, err := loadResult(pathB)
+	if err != nil {
+		return err
+	}
+
+	diff := simulator.DiffResults(resultA, resultB)
+	if diff.Empty() {
+		pterm.FgGreen.Println("the two results place every pod the same way")
+		return nil
+	}
+
+	if len(diff.PodMoves) != 0 {
+		pterm.FgYellow.Println("Pod Moves")
+		for _, move := range diff.PodMoves {
+			pterm.FgLightWhite.Printf("pod %s moved from %s to %s\n", move.Pod, move.From, move.To)
+		}
+		pterm.FgYellow.Println()
+	}
+	if len(diff.UtilizationChanges) != 0 {
+		pterm.FgYellow.Println("Utilization Changes")
+		for _, change := range diff.UtilizationChanges {
+			pterm.FgLightWhite.Printf("node %s: %d%% -> %d%%\n", change.Node, change.Before, change.After)
+		}
+		pterm.FgYellow.Println()
+	}
+	if len(diff.NewlyUnscheduled) != 0 {
+		pterm.FgRed.Printf("%d pod(s) became unscheduled:\n", len(diff.NewlyUnscheduled))
+		for _, pod := range diff.NewlyUnscheduled {
+			pterm.FgLightWhite.Println(pod)
+		}
+		return fmt.Errorf("placement regressed between %s and %s ", pathA, pathB)
+	}
+	return nil
+}
+
+// loadResult reads one exported simulation result from disk
+func loadResult(path string) (*simulator.SimulateResult, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result file: %v ", err)
+	}
+	var result simulator.SimulateResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal result file %s: %v ", path, err)
+	}
+	return &result, nil
+}
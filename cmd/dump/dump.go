@@ -0,0 +1,38 @@
+package dump
+
+import (
+	"os"
+
+	"github.com/pterm/pterm"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	dumppkg "github.com/alibaba/open-simulator/pkg/dump"
+)
+
+var options = dumppkg.Options{}
+
+var DumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Dump a cluster snapshot as YAML files that can be fed back into simon apply",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := dumppkg.Dump(options); err != nil {
+			pterm.FgRed.Printf("dump error: %s\n", err.Error())
+			os.Exit(1)
+		}
+		pterm.FgGreen.Printf("cluster snapshot written to %s\n", options.OutputDir)
+	},
+}
+
+func init() {
+	DumpCmd.Flags().StringVar(&options.Kubeconfig, "kubeconfig", options.Kubeconfig, "path to the kube-config file of the cluster to snapshot")
+	DumpCmd.Flags().StringVarP(&options.OutputDir, "output-dir", "o", options.OutputDir, "directory the snapshot YAML files are written to")
+	DumpCmd.Flags().BoolVar(&options.Anonymize, "anonymize", false, "replace namespace/pod/node names, label values and images with hashed placeholders while preserving resource shapes and topology")
+
+	if err := DumpCmd.MarkFlagRequired("kubeconfig"); err != nil {
+		log.Fatal("failed to init DumpCmd on kubeconfig flag")
+	}
+	if err := DumpCmd.MarkFlagRequired("output-dir"); err != nil {
+		log.Fatal("failed to init DumpCmd on output-dir flag")
+	}
+}
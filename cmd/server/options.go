@@ -6,12 +6,14 @@ import (
 
 // Options is the combined set of options for all operating modes.
 type Options struct {
-	Kubeconfig string
-	Master     string
+	Kubeconfig               string
+	Master                   string
+	MaxConcurrentSimulations int
 }
 
 // AddFlags will add the flag to the pflag.FlagSet
 func (options *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&options.Kubeconfig, "kubeconfig", options.Kubeconfig, "Path to the kubeconfig file to use for the analysis.")
 	fs.StringVar(&options.Master, "master", options.Master, "URL/IP for master.")
+	fs.IntVar(&options.MaxConcurrentSimulations, "max-concurrent-simulations", 1, "Maximum number of simulations allowed to run at once; excess requests are rejected with 429.")
 }
@@ -27,7 +27,7 @@ func init() {
 }
 
 func run(opt *Options) error {
-	server, err := serverpkg.NewServer(opt.Kubeconfig, opt.Master)
+	server, err := serverpkg.NewServer(opt.Kubeconfig, opt.Master, opt.MaxConcurrentSimulations)
 	if err != nil {
 		return err
 	}
@@ -5,7 +5,10 @@ import (
 	"os"
 
 	"github.com/alibaba/open-simulator/cmd/apply"
+	"github.com/alibaba/open-simulator/cmd/batch"
+	"github.com/alibaba/open-simulator/cmd/diff"
 	"github.com/alibaba/open-simulator/cmd/doc"
+	"github.com/alibaba/open-simulator/cmd/dump"
 	"github.com/alibaba/open-simulator/cmd/server"
 	"github.com/alibaba/open-simulator/cmd/version"
 	log "github.com/sirupsen/logrus"
@@ -33,6 +36,9 @@ func NewSimonCommand() *cobra.Command {
 	simonCmd.AddCommand(
 		version.VersionCmd,
 		apply.ApplyCmd,
+		batch.BatchCmd,
+		diff.DiffCmd,
+		dump.DumpCmd,
 		doc.GenDoc.DocCmd,
 		server.ServerCmd,
 	)
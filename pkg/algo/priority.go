@@ -0,0 +1,91 @@
+package algo
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+)
+
+// PrioritySizeQueue is used to sort pods by pod priority first and resource size second,
+// so simulation order matches scheduler admission on priority-heavy clusters
+type PrioritySizeQueue struct {
+	pods []*corev1.Pod
+}
+
+// NewPrioritySizeQueue return a PrioritySizeQueue
+func NewPrioritySizeQueue(pods []*corev1.Pod) *PrioritySizeQueue {
+	return &PrioritySizeQueue{
+		pods: pods,
+	}
+}
+
+func (pri *PrioritySizeQueue) Len() int      { return len(pri.pods) }
+func (pri *PrioritySizeQueue) Swap(i, j int) { pri.pods[i], pri.pods[j] = pri.pods[j], pri.pods[i] }
+func (pri *PrioritySizeQueue) Less(i, j int) bool {
+	if podPriority(pri.pods[i]) != podPriority(pri.pods[j]) {
+		return podPriority(pri.pods[i]) > podPriority(pri.pods[j])
+	}
+	return podSize(pri.pods[i]) > podSize(pri.pods[j])
+}
+
+// LargestRequestQueue is used to sort pods by descending cpu+memory request magnitude,
+// so the hardest pods to place are fed to the scheduler first
+type LargestRequestQueue struct {
+	pods []*corev1.Pod
+}
+
+// NewLargestRequestQueue return a LargestRequestQueue
+func NewLargestRequestQueue(pods []*corev1.Pod) *LargestRequestQueue {
+	return &LargestRequestQueue{
+		pods: pods,
+	}
+}
+
+func (largest *LargestRequestQueue) Len() int { return len(largest.pods) }
+func (largest *LargestRequestQueue) Swap(i, j int) {
+	largest.pods[i], largest.pods[j] = largest.pods[j], largest.pods[i]
+}
+func (largest *LargestRequestQueue) Less(i, j int) bool {
+	return podSize(largest.pods[i]) > podSize(largest.pods[j])
+}
+
+// PriorityClassQueue is used to sort pods by descending resolved pod priority, the
+// order an admission-resolved scheduler queue would hand them out in
+type PriorityClassQueue struct {
+	pods []*corev1.Pod
+}
+
+// NewPriorityClassQueue return a PriorityClassQueue
+func NewPriorityClassQueue(pods []*corev1.Pod) *PriorityClassQueue {
+	return &PriorityClassQueue{
+		pods: pods,
+	}
+}
+
+func (pri *PriorityClassQueue) Len() int { return len(pri.pods) }
+func (pri *PriorityClassQueue) Swap(i, j int) {
+	pri.pods[i], pri.pods[j] = pri.pods[j], pri.pods[i]
+}
+func (pri *PriorityClassQueue) Less(i, j int) bool {
+	return podPriority(pri.pods[i]) > podPriority(pri.pods[j])
+}
+
+// podPriority returns the resolved pod priority, falling back to the built-in critical
+// classes for manifests that only name the class; pods without either rank lowest
+func podPriority(pod *corev1.Pod) int32 {
+	if pod.Spec.Priority != nil {
+		return *pod.Spec.Priority
+	}
+	switch pod.Spec.PriorityClassName {
+	case "system-node-critical":
+		return 2000001000
+	case "system-cluster-critical":
+		return 2000000000
+	}
+	return 0
+}
+
+// podSize combines the cpu and memory requests into one magnitude for ordering
+func podSize(pod *corev1.Pod) int64 {
+	podReq, _ := resourcehelper.PodRequestsAndLimits(pod)
+	return podReq.Cpu().MilliValue() + podReq.Memory().Value()/(1<<20)
+}
@@ -0,0 +1,69 @@
+package algo
+
+import (
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/test"
+)
+
+func TestPrioritySizeQueue(t *testing.T) {
+	highPriority := int32(1000)
+	lowPriority := int32(10)
+
+	smallHigh := test.MakeFakePod("small-high", "default", "500m", "512Mi")
+	smallHigh.Spec.Priority = &highPriority
+	largeLow := test.MakeFakePod("large-low", "default", "4", "8Gi")
+	largeLow.Spec.Priority = &lowPriority
+	smallLow := test.MakeFakePod("small-low", "default", "500m", "512Mi")
+	smallLow.Spec.Priority = &lowPriority
+	noPriority := test.MakeFakePod("no-priority", "default", "8", "16Gi")
+
+	pods := []*corev1.Pod{noPriority, smallLow, largeLow, smallHigh}
+	sort.Sort(NewPrioritySizeQueue(pods))
+
+	want := []string{"small-high", "large-low", "small-low", "no-priority"}
+	for i, name := range want {
+		if pods[i].Name != name {
+			t.Errorf("pods[%d] = %s, want %s", i, pods[i].Name, name)
+		}
+	}
+}
+
+func TestLargestRequestQueue(t *testing.T) {
+	small := test.MakeFakePod("small", "default", "500m", "512Mi")
+	medium := test.MakeFakePod("medium", "default", "2", "4Gi")
+	large := test.MakeFakePod("large", "default", "8", "16Gi")
+
+	pods := []*corev1.Pod{small, large, medium}
+	sort.Sort(NewLargestRequestQueue(pods))
+
+	want := []string{"large", "medium", "small"}
+	for i, name := range want {
+		if pods[i].Name != name {
+			t.Errorf("pods[%d] = %s, want %s", i, pods[i].Name, name)
+		}
+	}
+}
+
+func TestPriorityClassQueue(t *testing.T) {
+	highPriority := int32(1000)
+
+	resolved := test.MakeFakePod("resolved", "default", "1", "1Gi")
+	resolved.Spec.Priority = &highPriority
+	critical := test.MakeFakePod("critical", "default", "1", "1Gi")
+	critical.Spec.PriorityClassName = "system-cluster-critical"
+	unranked := test.MakeFakePod("unranked", "default", "8", "16Gi")
+
+	pods := []*corev1.Pod{unranked, resolved, critical}
+	sort.Sort(NewPriorityClassQueue(pods))
+
+	want := []string{"critical", "resolved", "unranked"}
+	for i, name := range want {
+		if pods[i].Name != name {
+			t.Errorf("pods[%d] = %s, want %s", i, pods[i].Name, name)
+		}
+	}
+}
@@ -1,20 +1,104 @@
 package v1alpha1
 
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
 type AppInfo struct {
 	Name  string `json:"name"`
 	Path  string `json:"path"`
 	Chart bool   `json:"chart,omitempty"`
+	// Version is the tag pulled when the path is an oci:// registry reference
+	Version string `json:"version,omitempty"`
+	// Kustomize marks the path as a kustomization directory or file to render instead
+	// of a plain YAML directory
+	Kustomize bool `json:"kustomize,omitempty"`
+	// ValuesFiles are helm values files merged in order before rendering the chart
+	ValuesFiles []string `json:"valuesFiles,omitempty"`
+	// SetValues are key=value overrides applied on top of the values files
+	SetValues []string `json:"setValues,omitempty"`
+	// DependsOn lists names of apps that must be deployed before this one
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 type Cluster struct {
 	CustomCluster string `json:"customConfig,omitempty"`
 	KubeConfig    string `json:"kubeConfig,omitempty"`
+	// SnapshotDir is a directory of YAML dumps of the cluster objects, e.g. exported
+	// with kubectl get -o yaml, used as the cluster source in air-gapped environments
+	SnapshotDir string `json:"snapshotDir,omitempty"`
+}
+
+// NodeTemplate declares one candidate node shape for scaling a heterogeneous cluster
+type NodeTemplate struct {
+	// Name, when set and the path yields a single node, renames that node so reports
+	// and expander decisions identify the template
+	Name string `json:"name,omitempty"`
+	Path string `json:"path"`
+	// MaxCount caps how many nodes of this template the scaling loop may add, 0 means
+	// no per-template limit
+	MaxCount int `json:"maxCount,omitempty"`
+	// NamePrefix names the generated nodes of this template <namePrefix>-<ordinal>
+	// instead of using the built-in simon prefix, so they do not collide with real
+	// nodes sharing that prefix; it must be a valid DNS subdomain
+	NamePrefix string `json:"namePrefix,omitempty"`
+	// TaintPolicy controls what happens to the taints of the template node when fake
+	// nodes are generated from it: preserve (the default) copies them wholesale, strip
+	// removes them, replace swaps them for Taints. Preserved taints are checked against
+	// pod tolerations by the scheduler and by the NodeShouldRunPod affinity check on the
+	// failure path, so an untolerated taint makes every node of the template unusable.
+	TaintPolicy string `json:"taintPolicy,omitempty"`
+	// Taints is the taint list the generated nodes get under the replace policy
+	Taints []corev1.Taint `json:"taints,omitempty"`
+	// KubeReserved is capacity set aside for kubernetes system daemons, mirroring the
+	// kubelet --kube-reserved flag; together with SystemReserved it is subtracted from
+	// the template's capacity to derive the allocatable of the generated nodes
+	KubeReserved corev1.ResourceList `json:"kubeReserved,omitempty"`
+	// SystemReserved is capacity set aside for OS system daemons, mirroring the
+	// kubelet --system-reserved flag
+	SystemReserved corev1.ResourceList `json:"systemReserved,omitempty"`
+}
+
+// SpreadRequirement caps the share of a service's pods allowed in one failure domain
+type SpreadRequirement struct {
+	// Service is the namespace/name of the service whose pods are grouped
+	Service string `json:"service"`
+	// TopologyKey is the node label defining the failure domain, defaults to topology.kubernetes.io/zone
+	TopologyKey string `json:"topologyKey,omitempty"`
+	// MaxPercent is the maximum share of the service's pods allowed in one domain, in percent
+	MaxPercent int `json:"maxPercent"`
+}
+
+// ResourceThresholds caps the average occupancy rates, in percent, the scheduled
+// cluster may reach for the run to count as a success. Unset values fall back to the
+// MaxCPU, MaxMemory and MaxVG environment variables, then to 100.
+type ResourceThresholds struct {
+	CPU    int `json:"cpu,omitempty"`
+	Memory int `json:"memory,omitempty"`
+	VG     int `json:"vg,omitempty"`
+	GPU    int `json:"gpu,omitempty"`
 }
 
 type SimonSpec struct {
 	Cluster Cluster   `json:"cluster"`
 	AppList []AppInfo `json:"appList,omitempty"`
-	NewNode string    `json:"newNode,omitempty"`
+	// NewNode is either a single path string, the original form, or a list of
+	// NodeTemplate entries declaring several candidate node shapes
+	NewNode json.RawMessage `json:"newNode,omitempty"`
+	// ResourceThresholds keeps the scenario's acceptance criteria in the config file
+	// instead of environment variables
+	ResourceThresholds ResourceThresholds `json:"resourceThresholds,omitempty"`
+	// NamespaceNodeSelectors maps a namespace to the default node selector injected into
+	// its pods before scheduling, mimicking the PodNodeSelector admission plugin
+	NamespaceNodeSelectors map[string]map[string]string `json:"namespaceNodeSelectors,omitempty"`
+	// SpreadRequirements lists per-service failure-domain concentration limits that are
+	// validated against the simulation result
+	SpreadRequirements []SpreadRequirement `json:"spreadRequirements,omitempty"`
+	// Waves groups app names into ordered rollout waves; the report then shows how
+	// capacity is consumed after each wave. Apps not listed form a final implicit wave.
+	Waves [][]string `json:"waves,omitempty"`
 }
 
 type SimonMetaData struct {
@@ -0,0 +1,425 @@
+package apply
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// ResourceWeights maps a resource name to the weight it carries in the packing analysis.
+// A weight above 1 makes the resource more likely to be reported as the bottleneck even
+// at a lower occupancy percentage.
+type ResourceWeights map[corev1.ResourceName]float64
+
+// ParseResourceWeights parses flag values of the form "cpu=1,memory=2,nvidia.com/gpu=4"
+func ParseResourceWeights(items []string) (ResourceWeights, error) {
+	weights := make(ResourceWeights, len(items))
+	for _, item := range items {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid resource weight %q, expect <resource>=<weight> ", item)
+		}
+		weight, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("invalid weight %q for resource %s, expect a positive number ", parts[1], parts[0])
+		}
+		weights[corev1.ResourceName(parts[0])] = weight
+	}
+	return weights, nil
+}
+
+func (weights ResourceWeights) weightOf(name corev1.ResourceName) float64 {
+	if weight, exist := weights[name]; exist {
+		return weight
+	}
+	return 1
+}
+
+// PackingAnalysis summarizes how well the pod requests pack into the cluster
+type PackingAnalysis struct {
+	// Bottleneck is the resource with the highest weighted occupancy
+	Bottleneck corev1.ResourceName
+	// Score is the weighted average occupancy in percent, i.e. the packing efficiency
+	Score float64
+	// Fractions records the unweighted occupancy in percent per resource
+	Fractions map[corev1.ResourceName]float64
+}
+
+// analyzePacking computes cluster-wide occupancy per resource and determines the bottleneck
+// resource according to the given weights. CPU and memory are always analyzed; any other
+// resource mentioned in weights is analyzed as well if some node advertises it.
+func analyzePacking(nodeStatuses []simulator.NodeStatus, weights ResourceWeights) PackingAnalysis {
+	analyzedResources := map[corev1.ResourceName]struct{}{
+		corev1.ResourceCPU:    {},
+		corev1.ResourceMemory: {},
+	}
+	for name := range weights {
+		analyzedResources[name] = struct{}{}
+	}
+
+	allocatable := make(map[corev1.ResourceName]*resource.Quantity)
+	requested := make(map[corev1.ResourceName]*resource.Quantity)
+	for name := range analyzedResources {
+		allocatable[name] = resource.NewQuantity(0, resource.DecimalSI)
+		requested[name] = resource.NewQuantity(0, resource.DecimalSI)
+	}
+
+	for _, status := range nodeStatuses {
+		for name := range analyzedResources {
+			if quantity, exist := status.Node.Status.Allocatable[name]; exist {
+				allocatable[name].Add(quantity)
+			}
+		}
+		for _, pod := range status.Pods {
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			for name := range analyzedResources {
+				if quantity, exist := podReqs[name]; exist {
+					requested[name].Add(quantity)
+				}
+			}
+		}
+	}
+
+	analysis := PackingAnalysis{
+		Fractions: make(map[corev1.ResourceName]float64, len(analyzedResources)),
+	}
+	maxWeightedFraction := float64(-1)
+	totalWeight := float64(0)
+	weightedSum := float64(0)
+	for name := range analyzedResources {
+		if allocatable[name].MilliValue() == 0 {
+			// no node advertises the resource, leave it out of the analysis
+			continue
+		}
+		fraction := float64(requested[name].MilliValue()) / float64(allocatable[name].MilliValue()) * 100
+		analysis.Fractions[name] = fraction
+
+		weight := weights.weightOf(name)
+		weightedSum += fraction * weight
+		totalWeight += weight
+		if fraction*weight > maxWeightedFraction {
+			maxWeightedFraction = fraction * weight
+			analysis.Bottleneck = name
+		}
+	}
+	if totalWeight > 0 {
+		analysis.Score = weightedSum / totalWeight
+	}
+
+	return analysis
+}
+
+// reportPackingAnalysis prints the cluster-wide packing analysis
+func reportPackingAnalysis(nodeStatuses []simulator.NodeStatus, weights ResourceWeights) {
+	analysis := analyzePacking(nodeStatuses, weights)
+
+	pterm.FgYellow.Println("Packing Analysis")
+	var names []string
+	for name := range analysis.Fractions {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		marker := ""
+		if corev1.ResourceName(name) == analysis.Bottleneck {
+			marker = " (bottleneck)"
+		}
+		pterm.FgLightWhite.Printf("%s: %d%% occupied, weight %v%s\n", name, int64(analysis.Fractions[corev1.ResourceName(name)]), weights.weightOf(corev1.ResourceName(name)), marker)
+	}
+	pterm.FgLightWhite.Printf("packing efficiency score: %d%%\n", int64(analysis.Score))
+	pterm.FgYellow.Println()
+}
+
+// StartupSpike flags a node whose concurrently starting pods demand more of a resource
+// than the steady-state requests the node settles into; the scheduler reserves the
+// startup peak, so the difference is capacity the node needs only while pods start
+type StartupSpike struct {
+	Node        string
+	Resource    corev1.ResourceName
+	Peak        resource.Quantity
+	SteadyState resource.Quantity
+	Allocatable resource.Quantity
+}
+
+// podStartupDemand returns the cpu and memory a pod demands while starting (the
+// effective request: the maximum of its largest init-container request and the sum of
+// its container requests) next to what it settles into afterwards (the container sum
+// alone). The spread between the two is what concurrent startup spikes by.
+func podStartupDemand(pod *corev1.Pod) (corev1.ResourceList, corev1.ResourceList) {
+	startup := corev1.ResourceList{}
+	steady := corev1.ResourceList{}
+	podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		startup[name] = podReqs[name]
+		steadyQuantity := resource.Quantity{}
+		for _, container := range pod.Spec.Containers {
+			if containerQuantity, exist := container.Resources.Requests[name]; exist {
+				steadyQuantity.Add(containerQuantity)
+			}
+		}
+		steady[name] = steadyQuantity
+	}
+	return startup, steady
+}
+
+// analyzeStartupSpikes sums the startup and steady-state demand of the pods on each node
+// assuming they all start at the same time and returns the nodes where the startup peak
+// exceeds the steady state, i.e. where init containers dominate the reservation
+func analyzeStartupSpikes(nodeStatuses []simulator.NodeStatus) []StartupSpike {
+	var spikes []StartupSpike
+	for _, status := range nodeStatuses {
+		peak := map[corev1.ResourceName]*resource.Quantity{
+			corev1.ResourceCPU:    resource.NewQuantity(0, resource.DecimalSI),
+			corev1.ResourceMemory: resource.NewQuantity(0, resource.BinarySI),
+		}
+		settled := map[corev1.ResourceName]*resource.Quantity{
+			corev1.ResourceCPU:    resource.NewQuantity(0, resource.DecimalSI),
+			corev1.ResourceMemory: resource.NewQuantity(0, resource.BinarySI),
+		}
+		for _, pod := range status.Pods {
+			startup, steady := podStartupDemand(pod)
+			for name := range peak {
+				startupQuantity, steadyQuantity := startup[name], steady[name]
+				peak[name].Add(startupQuantity)
+				settled[name].Add(steadyQuantity)
+			}
+		}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			if peak[name].Cmp(*settled[name]) > 0 {
+				spikes = append(spikes, StartupSpike{
+					Node:        status.Node.Name,
+					Resource:    name,
+					Peak:        *peak[name],
+					SteadyState: *settled[name],
+					Allocatable: status.Node.Status.Allocatable[name],
+				})
+			}
+		}
+	}
+	return spikes
+}
+
+// reportStartupSpikeAnalysis prints nodes whose concurrent pod startup demand exceeds
+// the steady-state demand of their pods
+func reportStartupSpikeAnalysis(nodeStatuses []simulator.NodeStatus) {
+	spikes := analyzeStartupSpikes(nodeStatuses)
+	pterm.FgYellow.Println("Startup Spike Analysis")
+	if len(spikes) == 0 {
+		pterm.FgGreen.Println("startup demand equals the steady-state demand on every node")
+		pterm.FgYellow.Println()
+		return
+	}
+	pterm.FgRed.Printf("%d node/resource pair(s) demand more during concurrent pod startup than at steady state:\n", len(spikes))
+	for i, spike := range spikes {
+		pterm.FgLightWhite.Printf("%4d %s: startup %s demand %s exceeds the steady-state demand %s, allocatable %s\n",
+			i, spike.Node, spike.Resource, spike.Peak.String(), spike.SteadyState.String(), spike.Allocatable.String())
+	}
+	pterm.FgYellow.Println()
+}
+
+// ParseResourceList parses flag values of the form "cpu=100m,memory=50Mi"
+func ParseResourceList(items []string) (corev1.ResourceList, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	resourceList := make(corev1.ResourceList, len(items))
+	for _, item := range items {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid resource %q, expect <resource>=<quantity> ", item)
+		}
+		quantity, err := resource.ParseQuantity(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for resource %s: %v ", parts[1], parts[0], err)
+		}
+		resourceList[corev1.ResourceName(parts[0])] = quantity
+	}
+	return resourceList, nil
+}
+
+// ParseDisplayUnits parses "<resource>=<unit>" items, e.g. "memory=Gi,cpu=m", into the
+// units the report tables render quantities with
+func ParseDisplayUnits(items []string) (map[corev1.ResourceName]string, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	validUnits := map[string]bool{"Gi": true, "Mi": true, "m": true, "cores": true}
+	displayUnits := make(map[corev1.ResourceName]string, len(items))
+	for _, item := range items {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid display unit %q, expect <resource>=<unit> ", item)
+		}
+		if !validUnits[parts[1]] {
+			return nil, fmt.Errorf("invalid unit %q for resource %s, expect one of Gi, Mi, m, cores ", parts[1], parts[0])
+		}
+		displayUnits[corev1.ResourceName(parts[0])] = parts[1]
+	}
+	return displayUnits, nil
+}
+
+// formatQuantity renders a quantity in the configured display unit for its resource,
+// falling back to the canonical form so the default output stays unchanged
+func formatQuantity(quantity *resource.Quantity, resourceName corev1.ResourceName, displayUnits map[corev1.ResourceName]string) string {
+	switch displayUnits[resourceName] {
+	case "Gi":
+		return fmt.Sprintf("%.1fGi", float64(quantity.Value())/(1<<30))
+	case "Mi":
+		return fmt.Sprintf("%dMi", quantity.Value()/(1<<20))
+	case "m":
+		return fmt.Sprintf("%dm", quantity.MilliValue())
+	case "cores":
+		return fmt.Sprintf("%.2f", float64(quantity.MilliValue())/1000)
+	default:
+		return quantity.String()
+	}
+}
+
+// ParseUtilizationCaps parses "<resource>=<percent>" items, e.g. "cpu=80", into
+// per-resource node utilization caps
+func ParseUtilizationCaps(items []string) (map[corev1.ResourceName]int64, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	caps := make(map[corev1.ResourceName]int64, len(items))
+	for _, item := range items {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid utilization cap %q, expect <resource>=<percent> ", item)
+		}
+		percent, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return nil, fmt.Errorf("invalid percent %q for resource %s, expect an integer in (0, 100] ", parts[1], parts[0])
+		}
+		caps[corev1.ResourceName(parts[0])] = percent
+	}
+	return caps, nil
+}
+
+// reportPodOverheadTax prints the total per-pod overhead added across the scheduled pods
+func reportPodOverheadTax(nodeStatuses []simulator.NodeStatus, podOverhead corev1.ResourceList) {
+	scheduledPods := 0
+	for _, status := range nodeStatuses {
+		scheduledPods += len(status.Pods)
+	}
+	pterm.FgYellow.Println("Pod Overhead")
+	var names []string
+	for name := range podOverhead {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		perPod := podOverhead[corev1.ResourceName(name)]
+		total := resource.NewQuantity(0, perPod.Format)
+		for i := 0; i < scheduledPods; i++ {
+			total.Add(perPod)
+		}
+		pterm.FgLightWhite.Printf("%s: %s per pod, %s in total across %d pod(s)\n", name, perPod.String(), total.String(), scheduledPods)
+	}
+	pterm.FgYellow.Println()
+}
+
+// PoolSummary aggregates the scheduling result of the nodes sharing a pool label value
+type PoolSummary struct {
+	Pool        string
+	Nodes       int
+	Pods        int
+	CpuFraction float64
+	MemFraction float64
+}
+
+// analyzePools groups nodes by the value of the pool label (nodes without the label fall
+// into the "-" pool) and aggregates node count, pod count and utilization per pool
+func analyzePools(nodeStatuses []simulator.NodeStatus, poolLabel string) []PoolSummary {
+	type poolAccumulator struct {
+		nodes          int
+		pods           int
+		cpuAllocatable resource.Quantity
+		cpuRequested   resource.Quantity
+		memAllocatable resource.Quantity
+		memRequested   resource.Quantity
+	}
+	pools := make(map[string]*poolAccumulator)
+	for _, status := range nodeStatuses {
+		pool := status.Node.Labels[poolLabel]
+		if pool == "" {
+			pool = status.Node.Labels[corev1.LabelInstanceType]
+		}
+		if pool == "" {
+			pool = "-"
+		}
+		accumulator, exist := pools[pool]
+		if !exist {
+			accumulator = &poolAccumulator{}
+			pools[pool] = accumulator
+		}
+		accumulator.nodes++
+		accumulator.pods += len(status.Pods)
+		accumulator.cpuAllocatable.Add(*status.Node.Status.Allocatable.Cpu())
+		accumulator.memAllocatable.Add(*status.Node.Status.Allocatable.Memory())
+		for _, pod := range status.Pods {
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			accumulator.cpuRequested.Add(*podReqs.Cpu())
+			accumulator.memRequested.Add(*podReqs.Memory())
+		}
+	}
+
+	var names []string
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var summaries []PoolSummary
+	for _, name := range names {
+		accumulator := pools[name]
+		summary := PoolSummary{
+			Pool:  name,
+			Nodes: accumulator.nodes,
+			Pods:  accumulator.pods,
+		}
+		if accumulator.cpuAllocatable.MilliValue() > 0 {
+			summary.CpuFraction = float64(accumulator.cpuRequested.MilliValue()) / float64(accumulator.cpuAllocatable.MilliValue()) * 100
+		}
+		if accumulator.memAllocatable.Value() > 0 {
+			summary.MemFraction = float64(accumulator.memRequested.Value()) / float64(accumulator.memAllocatable.Value()) * 100
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// reportPoolInfo prints the pool-level rollup of the scheduling result
+func reportPoolInfo(nodeStatuses []simulator.NodeStatus, poolLabel string) {
+	if poolLabel == "" {
+		poolLabel = corev1.LabelInstanceTypeStable
+	}
+	summaries := analyzePools(nodeStatuses, poolLabel)
+
+	pterm.FgYellow.Printf("Pool Info (grouped by %s)\n", poolLabel)
+	poolTable := pterm.DefaultTable.WithHasHeader()
+	poolTableData := [][]string{
+		{"Pool", "Nodes", "Pods", "CPU Requests", "Memory Requests"},
+	}
+	for _, summary := range summaries {
+		poolTableData = append(poolTableData, []string{
+			summary.Pool,
+			strconv.Itoa(summary.Nodes),
+			strconv.Itoa(summary.Pods),
+			fmt.Sprintf("%d%%", int64(summary.CpuFraction)),
+			fmt.Sprintf("%d%%", int64(summary.MemFraction)),
+		})
+	}
+	if err := poolTable.WithData(poolTableData).Render(); err != nil {
+		pterm.FgRed.Printf("fail to render pool table: %s\n", err.Error())
+	}
+	pterm.FgYellow.Println()
+}
@@ -0,0 +1,49 @@
+package apply
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+)
+
+func TestAnalyzeStartupSpikes(t *testing.T) {
+	// the init container dominates the reservation: the pod starts at 3 cpu and
+	// settles into 1, the plain pod starts and settles at 1
+	initHeavy := test.MakeFakePod("init-heavy", "default", "1", "1Gi",
+		test.WithPodNodeName("node-1"),
+		test.WithPodInitContainers([]corev1.Container{{
+			Name: "init",
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("3")},
+			},
+		}}))
+	plain := test.MakeFakePod("plain", "default", "1", "1Gi", test.WithPodNodeName("node-1"))
+
+	nodeStatuses := []simulator.NodeStatus{
+		{
+			Node: test.MakeFakeNode("node-1", "8", "16Gi"),
+			Pods: []*corev1.Pod{initHeavy, plain},
+		},
+		{
+			Node: test.MakeFakeNode("node-2", "8", "16Gi"),
+			Pods: []*corev1.Pod{test.MakeFakePod("settled", "default", "1", "1Gi", test.WithPodNodeName("node-2"))},
+		},
+	}
+
+	spikes := analyzeStartupSpikes(nodeStatuses)
+	if len(spikes) != 1 {
+		t.Fatalf("analyzeStartupSpikes() = %+v, want only node-1's cpu flagged", spikes)
+	}
+	spike := spikes[0]
+	if spike.Node != "node-1" || spike.Resource != corev1.ResourceCPU {
+		t.Errorf("spike = %+v, want node-1 cpu", spike)
+	}
+	// startup demand is 3 (init) + 1 (plain), the steady state is 1 + 1
+	if spike.Peak.MilliValue() != 4000 || spike.SteadyState.MilliValue() != 2000 {
+		t.Errorf("spike peak = %s, steady state = %s, want 4 over 2", spike.Peak.String(), spike.SteadyState.String())
+	}
+}
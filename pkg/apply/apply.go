@@ -7,12 +7,15 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 
 	survey "github.com/AlecAivazis/survey/v2"
 	"github.com/pquerna/ffjson/ffjson"
 	"github.com/pterm/pterm"
 	log "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	resourcehelper "k8s.io/kubectl/pkg/util/resource"
 	"sigs.k8s.io/yaml"
@@ -31,6 +34,28 @@ const (
 	SurveyAddNode     = "add node(s)"
 	SurveyShowResults = "show error event of unscheduled pods"
 	SurveyExit        = "exit"
+
+	// OutputModeCount suppresses all tables and prints only the number of added nodes
+	OutputModeCount = "count"
+	// OutputModeDescribe renders one kubectl-describe-like text block per node instead
+	// of the tables
+	OutputModeDescribe = "describe"
+	// OutputModeBars renders one line per node with compact ASCII utilization bars
+	// instead of the tables
+	OutputModeBars = "bars"
+	// OutputModeJSON suppresses all prose and prints the versioned structured report as
+	// JSON so the result can be piped into CI pipelines and dashboards
+	OutputModeJSON = "json"
+	// OutputModeYAML is OutputModeJSON with YAML serialization
+	OutputModeYAML = "yaml"
+	// OutputModeCSV emits the Node Info and Pod Info tables as CSV for spreadsheets
+	OutputModeCSV = "csv"
+	// OutputModeMarkdown emits the Node Info and Pod Info tables as GitHub-flavored
+	// Markdown with the verdict as a heading, for pasting into PR comments and wikis
+	OutputModeMarkdown = "markdown"
+	// OutputModeHTML renders the report as one self-contained HTML page with summary
+	// cards and collapsible node sections, for sharing outside the terminal
+	OutputModeHTML = "html"
 )
 
 type Options struct {
@@ -39,18 +64,129 @@ type Options struct {
 	UseGreed                   bool
 	Interactive                bool
 	ExtendedResources          []string
+	Namespaces                 []string
 	OutputFile                 string
+	OutputPath                 string
+	ValidateConstraints        bool
+	ResourceWeights            []string
+	ValidateOnly               bool
+	RecommendationsFile        string
+	Output                     string
+	AnalyzeStartup             bool
+	PodOverhead                []string
+	PoolLabel                  string
+	Strict                     bool
+	DaemonSetCoverage          bool
+	IgnorePendingPods          bool
+	IgnorePods                 string
+	ScoreDumpFile              string
+	Webhook                    string
+	WebhookHeaders             []string
+	MaxUtilization             []string
+	ExportKwokDir              string
+	AttributionFile            string
+	AttributionLabels          []string
+	Expander                   string
+	BundleOnFailure            string
+	VGSelectionPolicy          string
+	ScoringStrategy            string
+	SuccessCriteria            string
+	ShapeCatalogFile           string
+	EvictionThresholds         []string
+	ScalablePools              []string
+	DrainNode                  string
+	TerminationOverlap         bool
+	RemoveNode                 string
+	Deschedule                 bool
+	RequestMultipliers         []string
+	DebugDumpFile              string
+	SystemOverhead             []string
+	PrioritySort               bool
+	PodOrder                   string
+	Repeat                     int
+	MaxNewNodes                int
+	NodeNamePrefix             string
+	VerboseFailures            bool
+	MetricsFile                string
+	ExportResultFile           string
+	NoChartCache               bool
+	DumpSchedulerConfig        string
+	ExplainScale               bool
+	DisplayUnits               []string
 }
 
 type Applier struct {
-	cluster           v1alpha1.Cluster
-	appList           []v1alpha1.AppInfo
-	newNodePath       string
-	schedulerConfig   string
-	useGreed          bool
-	interactive       bool
-	extendedResources []string
-	outputFile        *os.File
+	cluster                v1alpha1.Cluster
+	appList                []v1alpha1.AppInfo
+	newNodePath            string
+	newNodeTemplates       []v1alpha1.NodeTemplate
+	schedulerConfig        string
+	dumpSchedulerConfig    string
+	useGreed               bool
+	interactive            bool
+	extendedResources      []string
+	namespaces             []string
+	outputFile             *os.File
+	validateConstraints    bool
+	resourceWeights        ResourceWeights
+	validateOnly           bool
+	recommendationsFile    string
+	namespaceNodeSelectors map[string]map[string]string
+	spreadRequirements     []v1alpha1.SpreadRequirement
+	resourceThresholds     v1alpha1.ResourceThresholds
+	outputMode             string
+	analyzeStartup         bool
+	podOverhead            corev1.ResourceList
+	poolLabel              string
+	strict                 bool
+	daemonSetCoverage      bool
+	ignorePendingPods      bool
+	ignorePods             string
+	scoreDumpFile          string
+	webhook                string
+	webhookHeaders         map[string]string
+	utilizationCaps        map[corev1.ResourceName]int64
+	exportKwokDir          string
+	attributionFile        string
+	attributionLabels      []string
+	expander               string
+	vgSelectionPolicy      string
+	scoringStrategy        string
+	successCriteria        []successClause
+	shapeCatalogFile       string
+	evictionThresholds     []evictionThreshold
+	scalablePools          []string
+	drainNode              string
+	terminationOverlap     bool
+	removeNode             string
+	deschedule             bool
+	requestMultipliers     map[string]float64
+	debugDumpFile          string
+	systemOverhead         corev1.ResourceList
+	prioritySort           bool
+	podOrder               string
+	repeat                 int
+	maxNewNodes            int
+	nodeNamePrefix         string
+	verboseFailures        bool
+	outputPath             string
+	metricsFile            string
+	exportResultFile       string
+	explainScale           bool
+	displayUnits           map[corev1.ResourceName]string
+	waves                  [][]string
+	// bundleOnFailure, when set, is the directory a reproducer bundle is written to
+	// when the run fails
+	bundleOnFailure string
+	// configPath remembers the simon config file for the failure bundle
+	configPath string
+	// bundleSnapshot captures what the run had resolved before it failed
+	bundleSnapshot failureBundle
+	// batch makes Run behave like count mode but without printing, so RunBatch can
+	// collect the outcome of many configs and render one summary
+	batch bool
+	// addedNodes records how many nodes the last Run had to add
+	addedNodes int
 }
 
 type Interface interface {
@@ -59,54 +195,252 @@ type Interface interface {
 
 // NewApplier returns a default applier that has passed the validity test
 func NewApplier(opts Options) Interface {
+	applier, err := newApplier(opts)
+	if err != nil {
+		pterm.FgRed.Printf("%v", err)
+		os.Exit(1)
+	}
+	return applier
+}
+
+// newApplier builds an applier from the options and surfaces configuration problems as
+// errors, so callers like batch mode can isolate a bad config instead of exiting
+func newApplier(opts Options) (*Applier, error) {
 	simonCR := &v1alpha1.Simon{}
 	configFile, err := ioutil.ReadFile(opts.SimonConfig)
 	if err != nil {
-		log.Fatalf("failed to read config file(%s): %v", opts.SimonConfig, err)
+		return nil, fmt.Errorf("failed to read config file(%s): %v ", opts.SimonConfig, err)
 	}
 	configJSON, err := yaml.YAMLToJSON(configFile)
 	if err != nil {
-		log.Fatalf("failed to unmarshal config file(%s) to json: %v", opts.SimonConfig, err)
+		return nil, fmt.Errorf("failed to unmarshal config file(%s) to json: %v ", opts.SimonConfig, err)
 	}
 
 	if err := json.Unmarshal(configJSON, simonCR); err != nil {
-		log.Fatalf("failed to unmarshal config json to object: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal config json to object: %v ", err)
 	}
 
 	var outputFile *os.File = nil
 	if opts.OutputFile != "" {
 		outputFile, err = os.Create(opts.OutputFile)
 		if err != nil {
-			log.Fatalf("failed to open file %s: %s", opts.OutputFile, err.Error())
+			return nil, fmt.Errorf("failed to open file %s: %s ", opts.OutputFile, err.Error())
+		}
+	}
+	if opts.OutputPath != "" {
+		if outputFile != nil {
+			return nil, fmt.Errorf("only one of --output-file and --output-path may be set ")
+		}
+		// csv mode places its files itself, a directory may receive one file per table
+		if opts.Output != OutputModeCSV {
+			path, err := resolveOutputPath(opts.OutputPath, simonCR.Spec.AppList)
+			if err != nil {
+				return nil, err
+			}
+			outputFile, err = os.Create(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open file %s: %s ", path, err.Error())
+			}
+		}
+	}
+
+	resourceWeights, err := ParseResourceWeights(opts.ResourceWeights)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse resource weights: %v ", err)
+	}
+
+	podOverhead, err := ParseResourceList(opts.PodOverhead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pod overhead: %v ", err)
+	}
+
+	webhookHeaders, err := parseWebhookHeaders(opts.WebhookHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook headers: %v ", err)
+	}
+
+	utilizationCaps, err := ParseUtilizationCaps(opts.MaxUtilization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse max utilization: %v ", err)
+	}
+
+	displayUnits, err := ParseDisplayUnits(opts.DisplayUnits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse display units: %v ", err)
+	}
+
+	if err := ValidateExpander(opts.Expander); err != nil {
+		return nil, err
+	}
+
+	if err := simulator.ValidateScoringStrategy(opts.ScoringStrategy); err != nil {
+		return nil, err
+	}
+
+	if err := simulator.ValidatePodOrder(opts.PodOrder); err != nil {
+		return nil, err
+	}
+
+	if opts.AttributionFile != "" && len(opts.AttributionLabels) == 0 {
+		return nil, fmt.Errorf("attribution export needs at least one label, set --attribution-label ")
+	}
+
+	var successCriteria []successClause
+	if opts.SuccessCriteria != "" {
+		if successCriteria, err = parseSuccessCriteria(opts.SuccessCriteria); err != nil {
+			return nil, err
 		}
 	}
 
+	evictionThresholds, err := ParseEvictionThresholds(opts.EvictionThresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	requestMultipliers, err := ParseRequestMultipliers(opts.RequestMultipliers)
+	if err != nil {
+		return nil, err
+	}
+
+	systemOverhead, err := ParseResourceList(opts.SystemOverhead)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse system overhead: %v ", err)
+	}
+
+	newNodePath, newNodeTemplates, err := parseNewNode(simonCR.Spec.NewNode)
+	if err != nil {
+		return nil, err
+	}
+
+	// the render cache is package state in chart, so the escape hatch is applied here
+	// once instead of being threaded through every render call
+	chart.SetDiskCacheDisabled(opts.NoChartCache)
+
+	if err := validateNodeNamePrefix(opts.NodeNamePrefix); err != nil {
+		return nil, err
+	}
+
 	applier := &Applier{
-		cluster:           simonCR.Spec.Cluster,
-		appList:           simonCR.Spec.AppList,
-		newNodePath:       simonCR.Spec.NewNode,
-		schedulerConfig:   opts.DefaultSchedulerConfigFile,
-		useGreed:          opts.UseGreed,
-		interactive:       opts.Interactive,
-		extendedResources: opts.ExtendedResources,
-		outputFile:        outputFile,
+		cluster:                simonCR.Spec.Cluster,
+		appList:                simonCR.Spec.AppList,
+		newNodePath:            newNodePath,
+		newNodeTemplates:       newNodeTemplates,
+		schedulerConfig:        opts.DefaultSchedulerConfigFile,
+		dumpSchedulerConfig:    opts.DumpSchedulerConfig,
+		useGreed:               opts.UseGreed,
+		interactive:            opts.Interactive,
+		extendedResources:      opts.ExtendedResources,
+		namespaces:             opts.Namespaces,
+		outputFile:             outputFile,
+		validateConstraints:    opts.ValidateConstraints,
+		resourceWeights:        resourceWeights,
+		validateOnly:           opts.ValidateOnly,
+		recommendationsFile:    opts.RecommendationsFile,
+		namespaceNodeSelectors: simonCR.Spec.NamespaceNodeSelectors,
+		spreadRequirements:     simonCR.Spec.SpreadRequirements,
+		resourceThresholds:     simonCR.Spec.ResourceThresholds,
+		waves:                  simonCR.Spec.Waves,
+		outputMode:             opts.Output,
+		analyzeStartup:         opts.AnalyzeStartup,
+		podOverhead:            podOverhead,
+		poolLabel:              opts.PoolLabel,
+		strict:                 opts.Strict,
+		daemonSetCoverage:      opts.DaemonSetCoverage,
+		ignorePendingPods:      opts.IgnorePendingPods,
+		ignorePods:             opts.IgnorePods,
+		scoreDumpFile:          opts.ScoreDumpFile,
+		webhook:                opts.Webhook,
+		webhookHeaders:         webhookHeaders,
+		utilizationCaps:        utilizationCaps,
+		exportKwokDir:          opts.ExportKwokDir,
+		attributionFile:        opts.AttributionFile,
+		attributionLabels:      opts.AttributionLabels,
+		expander:               opts.Expander,
+		vgSelectionPolicy:      opts.VGSelectionPolicy,
+		scoringStrategy:        opts.ScoringStrategy,
+		successCriteria:        successCriteria,
+		shapeCatalogFile:       opts.ShapeCatalogFile,
+		evictionThresholds:     evictionThresholds,
+		scalablePools:          opts.ScalablePools,
+		drainNode:              opts.DrainNode,
+		removeNode:             opts.RemoveNode,
+		deschedule:             opts.Deschedule,
+		terminationOverlap:     opts.TerminationOverlap,
+		requestMultipliers:     requestMultipliers,
+		debugDumpFile:          opts.DebugDumpFile,
+		systemOverhead:         systemOverhead,
+		bundleOnFailure:        opts.BundleOnFailure,
+		configPath:             opts.SimonConfig,
+		prioritySort:           opts.PrioritySort,
+		podOrder:               opts.PodOrder,
+		repeat:                 opts.Repeat,
+		maxNewNodes:            opts.MaxNewNodes,
+		nodeNamePrefix:         opts.NodeNamePrefix,
+		verboseFailures:        opts.VerboseFailures,
+		outputPath:             opts.OutputPath,
+		metricsFile:            opts.MetricsFile,
+		exportResultFile:       opts.ExportResultFile,
+		explainScale:           opts.ExplainScale,
+		displayUnits:           displayUnits,
 	}
 
 	if err := validate(applier); err != nil {
-		pterm.FgRed.Printf("%v", err)
-		os.Exit(1)
+		return nil, err
+	}
+	if applier.maxNewNodes == 0 {
+		applier.maxNewNodes = simontype.MaxNumNewNode
 	}
 
-	return applier
+	return applier, nil
+}
+
+// Run executes the simulation and, when a reproducer bundle is requested, packages the
+// resolved inputs into a directory on failure so the run can be replayed elsewhere
+func (applier *Applier) Run() error {
+	err := applier.run()
+	if err != nil && applier.bundleOnFailure != "" {
+		if bundleErr := applier.writeFailureBundle(err); bundleErr != nil {
+			pterm.FgRed.Printf("failed to write failure bundle: %v\n", bundleErr)
+		} else {
+			pterm.FgLightWhite.Printf("reproducer bundle written to %s\n", applier.bundleOnFailure)
+		}
+	}
+	return err
 }
 
-func (applier *Applier) Run() (err error) {
+func (applier *Applier) run() (err error) {
 	// Step 0: init
+	// reset warnings collected by earlier runs so strict mode only sees this run
+	utils.DrainWarnings()
 	disablePTerm := false
 	if applier.outputFile != nil {
 		pterm.SetDefaultOutput(applier.outputFile)
 		disablePTerm = true
 	}
+	countOnly := applier.outputMode == OutputModeCount || applier.batch
+	structuredOnly := applier.outputMode == OutputModeJSON || applier.outputMode == OutputModeYAML
+	csvOnly := applier.outputMode == OutputModeCSV
+	markdownOnly := applier.outputMode == OutputModeMarkdown
+	htmlOnly := applier.outputMode == OutputModeHTML
+	if countOnly || structuredOnly || csvOnly || markdownOnly || htmlOnly {
+		// in count and structured modes nothing but the final result may reach stdout
+		pterm.SetDefaultOutput(ioutil.Discard)
+		disablePTerm = true
+	}
+
+	// the effective scheduler config explains placements, so it goes out before any
+	// simulation starts
+	if applier.dumpSchedulerConfig != "" {
+		content, err := simulator.EffectiveSchedulerConfig(applier.schedulerConfig, applier.scoringStrategy)
+		if err != nil {
+			return err
+		}
+		if applier.dumpSchedulerConfig == "-" {
+			fmt.Print(string(content))
+		} else if err := ioutil.WriteFile(applier.dumpSchedulerConfig, content, 0644); err != nil {
+			return fmt.Errorf("failed to write scheduler config dump %s: %v ", applier.dumpSchedulerConfig, err)
+		}
+	}
 
 	// Step 1: convert the cluster files into the kubernetes objects and generate a ResourceTypes struct
 	// cluster resource generated by two types of cluster, custom cluster and real cluster
@@ -120,51 +454,159 @@ func (applier *Applier) Run() (err error) {
 		if clusterResourceCopy, err = simulator.CreateClusterResourceFromClient(kubeclient, disablePTerm); err != nil {
 			return err
 		}
+	} else if applier.cluster.SnapshotDir != "" {
+		if clusterResourceCopy, err = simulator.CreateClusterResourceFromSnapshotDir(applier.cluster.SnapshotDir); err != nil {
+			return err
+		}
 	} else {
 		if clusterResourceCopy, err = simulator.CreateClusterResourceFromClusterConfig(applier.cluster.CustomCluster); err != nil {
 			return err
 		}
 	}
 
-	// Step 2: convert the application files into the kubernetes objects and generate a ResourceTypes struct, then make a resource list
-	var appResource simulator.ResourceTypes
+	// system pods (kube-proxy, CNI, node-local DNS) consume capacity no app manifest
+	// declares; reserve it on every node, including nodes generated later in the run
+	if len(applier.systemOverhead) != 0 {
+		clusterResourceCopy.CapacityReservations = append(clusterResourceCopy.CapacityReservations, simulator.CapacityReservation{
+			Name:      "system-pods",
+			Resources: applier.systemOverhead,
+		})
+	}
+
+	applier.bundleSnapshot.cluster = clusterResourceCopy
+
+	// Step 2: convert the application files into the kubernetes objects and generate a ResourceTypes struct, then make a resource list;
+	// the apps are parsed concurrently, the resource list keeps the list order
 	var resourceList []string
 	var content []string
 	resourceMap := make(map[string]simulator.ResourceTypes)
-	for _, app := range applier.appList {
-		// process separately chart and other file
-		if app.Chart {
-			// parse and render chart as a yaml array
-			if content, err = chart.ProcessChart(app.Name, app.Path); err != nil {
+	dependsOnMap := make(map[string][]string)
+	appResources, err := parseAppResources(applier.appList)
+	if err != nil {
+		return err
+	}
+	for index, app := range applier.appList {
+		resourceMap[app.Name] = appResources[index]
+		dependsOnMap[app.Name] = app.DependsOn
+		resourceList = append(resourceList, app.Name)
+	}
+
+	// Step 3: convert the path of the new node to be added into the kubernetes objects;
+	// every parsed node is a candidate template for the scaling loop
+	var nodeResource simulator.ResourceTypes
+	var newNode *corev1.Node
+	// per template node name, how many nodes of it the scaling loop may add at most
+	templateMaxCounts := make(map[string]int)
+	// per template node name, the naming prefix of the generated nodes; unlisted
+	// templates fall back to --node-name-prefix, then to the built-in simon prefix
+	templateNamePrefixes := make(map[string]string)
+	if len(applier.newNodeTemplates) != 0 {
+		for _, template := range applier.newNodeTemplates {
+			if content, err = utils.GetYamlContentFromDirectory(template.Path); err != nil {
 				return err
 			}
-		} else {
-			if content, err = utils.GetYamlContentFromDirectory(app.Path); err != nil {
+			var templateResource simulator.ResourceTypes
+			if templateResource, err = simulator.GetObjectFromYamlContent(content); err != nil {
 				return err
 			}
+			if len(templateResource.Nodes) == 0 {
+				return fmt.Errorf("newNode template %s contains no node ", template.Path)
+			}
+			simulator.MatchAndSetLocalStorageAnnotationOnNode(templateResource.Nodes, template.Path)
+			if template.Name != "" && len(templateResource.Nodes) == 1 {
+				templateResource.Nodes[0].Name = template.Name
+			}
+			for _, node := range templateResource.Nodes {
+				applyTaintPolicy(node, template)
+				if err := applyReservedResources(node, template); err != nil {
+					return err
+				}
+				if template.MaxCount > 0 {
+					templateMaxCounts[node.Name] = template.MaxCount
+				}
+				if template.NamePrefix != "" {
+					templateNamePrefixes[node.Name] = template.NamePrefix
+				}
+				nodeResource.Nodes = append(nodeResource.Nodes, node)
+			}
 		}
-		if appResource, err = simulator.GetObjectFromYamlContent(content); err != nil {
+		newNode = nodeResource.Nodes[0]
+	} else if applier.newNodePath != "" {
+		if content, err = utils.GetYamlContentFromDirectory(applier.newNodePath); err != nil {
 			return err
 		}
+		if nodeResource, err = simulator.GetObjectFromYamlContent(content); err != nil {
+			return err
+		}
+		simulator.MatchAndSetLocalStorageAnnotationOnNode(nodeResource.Nodes, applier.newNodePath)
+		// the first template is the fallback when only one type is configured
+		newNode = nodeResource.Nodes[0]
+	}
+	newNodeTemplates := nodeResource.Nodes
 
-		resourceMap[app.Name] = appResource
-		resourceList = append(resourceList, app.Name)
+	// operators may only be allowed to grow certain pools, so drop every template
+	// outside of them before the scaling loop sees the candidates
+	if len(applier.scalablePools) != 0 && newNode != nil {
+		newNodeTemplates = filterScalablePoolTemplates(newNodeTemplates, applier.poolLabel, applier.scalablePools)
+		if len(newNodeTemplates) == 0 {
+			return fmt.Errorf("no new-node template belongs to the scalable pools %s ", strings.Join(applier.scalablePools, ", "))
+		}
+		newNode = newNodeTemplates[0]
 	}
 
-	// Step 3: convert the path of the new node to be added into the kubernetes object
-	// only support temporarily one type of node at present
-	var nodeResource simulator.ResourceTypes
-	var newNode *corev1.Node
-	if applier.newNodePath != "" {
-		if content, err = utils.GetYamlContentFromDirectory(applier.newNodePath); err != nil {
+	// with several templates and no expander configured, pick per scale-out step the
+	// template that can host the most of the remaining unscheduled pods instead of
+	// silently cloning the first one
+	expander := applier.expander
+	// per-template limits also need the per-step chooser, even with a single template
+	if expander == "" && (len(newNodeTemplates) > 1 || len(templateMaxCounts) != 0) {
+		expander = ExpanderBestFit
+	}
+
+	// daemonset coverage mode skips the simulation and only checks on which of the
+	// intended nodes each DaemonSet would actually run
+	if applier.daemonSetCoverage {
+		daemonSets := append([]*appsv1.DaemonSet{}, clusterResourceCopy.DaemonSets...)
+		for _, name := range resourceList {
+			daemonSets = append(daemonSets, resourceMap[name].DaemonSets...)
+		}
+		if err := reportDaemonSetCoverage(daemonSets, clusterResourceCopy.Nodes); err != nil {
 			return err
 		}
-		if nodeResource, err = simulator.GetObjectFromYamlContent(content); err != nil {
+		return applier.checkWarnings()
+	}
+
+	// drain mode skips deploying apps and instead reschedules the pods of one node,
+	// optionally keeping old and new pod alive at once to capture the transient peak
+	if applier.drainNode != "" {
+		if err := applier.reportDrain(clusterResourceCopy, disablePTerm); err != nil {
 			return err
 		}
-		simulator.MatchAndSetLocalStorageAnnotationOnNode(nodeResource.Nodes, applier.newNodePath)
-		// only support temporarily adding a type of node at present
-		newNode = nodeResource.Nodes[0]
+		return applier.checkWarnings()
+	}
+
+	// remove mode takes one node out of the cluster entirely and reschedules its pods,
+	// checking the simultaneous eviction against the disruption budgets of the apps
+	if applier.removeNode != "" {
+		var pdbs []*policyv1beta1.PodDisruptionBudget
+		for _, name := range resourceList {
+			pdbs = append(pdbs, resourceMap[name].PodDisruptionBudgets...)
+		}
+		if err := applier.reportRemoveNode(clusterResourceCopy, pdbs, disablePTerm); err != nil {
+			return err
+		}
+		return applier.checkWarnings()
+	}
+
+	// validate-only mode stops once the config, charts and manifests have been parsed
+	// successfully, leaving only a summary of what was parsed
+	if applier.validateOnly {
+		if err := applier.checkWarnings(); err != nil {
+			return err
+		}
+		reportParseSummary(clusterResourceCopy, resourceList, resourceMap, newNodeTemplates)
+		pterm.FgGreen.Println("Validation success!")
+		return nil
 	}
 
 	// confirm the list of applications that needed to be deployed in interactive mode
@@ -189,26 +631,112 @@ func (applier *Applier) Run() (err error) {
 	}
 	for _, name := range selectedAppNameList {
 		selectedResourceList = append(selectedResourceList, simulator.AppResource{
-			Name:     name,
-			Resource: resourceMap[name],
+			Name:      name,
+			Resource:  resourceMap[name],
+			DependsOn: dependsOnMap[name],
 		})
 	}
 
+	applier.bundleSnapshot.apps = selectedResourceList
+
+	// cheapest-shape mode packs the selected apps greenfield onto every catalog shape
+	// and only reports the recommendation instead of simulating against the cluster
+	if applier.shapeCatalogFile != "" {
+		if err := applier.reportCheapestNodeShape(selectedResourceList); err != nil {
+			return err
+		}
+		return applier.checkWarnings()
+	}
+
+	// fail fast if even the new-node template cannot host the largest pod; the scaling
+	// loop could otherwise only run into its node limit
+	if newNode != nil {
+		var appPods []*corev1.Pod
+		for _, selected := range selectedResourceList {
+			pods, err := simulator.GetValidPodExcludeDaemonSet(selected.Resource)
+			if err != nil {
+				return err
+			}
+			appPods = append(appPods, pods...)
+		}
+		if err := utils.CheckNodeTemplateFitsPods(newNodeTemplates, appPods); err != nil {
+			return err
+		}
+	}
+
 	// Step 4: determining that the current cluster can deploy selected applications and meets the given requests,
 	// If everything is ok, output the result. Otherwise we adjust the scale of cluster by adding node
 	var result *simulator.SimulateResult
 	canBeScheduled := false
 	newNodeNum := 0
 	choose := ""
+	// pod key to the last reason it went unscheduled, feeding the scale explanation
+	unscheduledHistory := make(map[string]string)
+	// set when the final state violates the resource thresholds, so the error names
+	// the violated threshold instead of a pod count
+	thresholdReason := ""
+	// the cluster of the last simulation, including the added nodes, kept for re-runs
+	// like the wave analysis
+	var finalClusterResource simulator.ResourceTypes
+	// per scale-out step: the template the expander picked and why
+	var chosenTemplates []*corev1.Node
+	var expanderDecisions []string
 	for {
 		if choose != SurveyShowResults {
 			newClusterResource := clusterResourceCopy
-			nodes, err := utils.NewFakeNodes(newNode, newNodeNum)
-			if err != nil {
-				return err
+			var nodes []*corev1.Node
+			if expander != "" && newNode != nil {
+				// pick one template per scale-out step, judged against the pods the
+				// previous round could not place, the way the autoscaler would
+				for len(chosenTemplates) < newNodeNum {
+					var pendingPods []*corev1.Pod
+					if result != nil {
+						for _, unscheduledPod := range result.UnscheduledPods {
+							pendingPods = append(pendingPods, unscheduledPod.Pod)
+						}
+					}
+					available := remainingNodeTemplates(newNodeTemplates, chosenTemplates, templateMaxCounts)
+					if len(available) == 0 {
+						return fmt.Errorf("every newNode template reached its maxCount after adding %d node(s) ", len(chosenTemplates))
+					}
+					template, decision, err := chooseNodeTemplate(expander, available, pendingPods)
+					if err != nil {
+						return err
+					}
+					chosenTemplates = append(chosenTemplates, template)
+					expanderDecisions = append(expanderDecisions, fmt.Sprintf("step %d: %s", len(chosenTemplates), decision))
+				}
+				chosenTemplates = chosenTemplates[:newNodeNum]
+				expanderDecisions = expanderDecisions[:newNodeNum]
+				for _, template := range chosenTemplates {
+					naming := utils.NodeNaming{Prefix: applier.nodeNamePrefix}
+					if prefix, ok := templateNamePrefixes[template.Name]; ok {
+						naming.Prefix = prefix
+					}
+					newNodes, err := utils.NewFakeNodesWithNaming(template, 1, naming, append(clusterResourceCopy.Nodes, nodes...))
+					if err != nil {
+						return err
+					}
+					nodes = append(nodes, newNodes...)
+				}
+			} else {
+				naming := utils.NodeNaming{Prefix: applier.nodeNamePrefix}
+				// newNode stays nil when the config has no newNode entry; the zero
+				// node count below keeps that a no-op until the failure handling
+				// reports it
+				if newNode != nil {
+					if prefix, ok := templateNamePrefixes[newNode.Name]; ok {
+						naming.Prefix = prefix
+					}
+				}
+				nodes, err = utils.NewFakeNodesWithNaming(newNode, newNodeNum, naming, clusterResourceCopy.Nodes)
+				if err != nil {
+					return err
+				}
 			}
 			newClusterResource.Nodes = append(newClusterResource.Nodes, nodes...)
-			result, err = simulator.Simulate(newClusterResource, selectedResourceList, simulator.DisablePTerm(disablePTerm), simulator.WithKubeConfig(applier.cluster.KubeConfig))
+			finalClusterResource = newClusterResource
+			result, err = simulator.Simulate(newClusterResource, selectedResourceList, simulator.DisablePTerm(disablePTerm), simulator.WithKubeConfig(applier.cluster.KubeConfig), simulator.WithNamespaceNodeSelectors(applier.namespaceNodeSelectors), simulator.WithPodOverhead(applier.podOverhead), simulator.WithIgnorePendingPods(applier.ignorePendingPods), simulator.WithIgnorePodsSelector(applier.ignorePods), simulator.WithScoreDump(applier.scoreDumpFile), simulator.WithDebugDump(applier.debugDumpFile), simulator.WithUtilizationCaps(applier.utilizationCaps), simulator.WithPrioritySort(applier.prioritySort), simulator.WithPodOrder(applier.podOrder), simulator.WithVGSelectionPolicy(applier.vgSelectionPolicy), simulator.WithScoringStrategy(applier.scoringStrategy), simulator.WithAppRequestMultipliers(applier.requestMultipliers), simulator.WithVerboseFailures(applier.verboseFailures))
 
 			if err != nil {
 				return err
@@ -216,6 +744,43 @@ func (applier *Applier) Run() (err error) {
 		}
 
 		if len(result.UnscheduledPods) != 0 {
+			applier.bundleSnapshot.unscheduledPods = result.UnscheduledPods
+			for _, unscheduledPod := range result.UnscheduledPods {
+				key := fmt.Sprintf("%s/%s", unscheduledPod.Pod.Namespace, unscheduledPod.Pod.Name)
+				unscheduledHistory[key] = unscheduledPod.Reason
+			}
+			// adding nodes can never help pods that need an extended resource the
+			// node template does not advertise, so fail fast instead of looping
+			if newNode != nil {
+				var unscheduledPods []*corev1.Pod
+				for _, unscheduledPod := range result.UnscheduledPods {
+					unscheduledPods = append(unscheduledPods, unscheduledPod.Pod)
+				}
+				if err := utils.CheckExtendedResourcesOfNodeTemplate(newNode, unscheduledPods); err != nil {
+					return err
+				}
+			}
+			if countOnly || structuredOnly || csvOnly || markdownOnly || htmlOnly {
+				// keep adding nodes without prompting; the verdict ends up in the exit code
+				if newNode == nil {
+					// in structured and csv modes the failure still yields parseable output
+					if structuredOnly || csvOnly || markdownOnly || htmlOnly {
+						break
+					}
+					return fmt.Errorf("%d pod(s) can not be scheduled and no new node is configured", len(result.UnscheduledPods))
+				}
+				if newNodeNum >= applier.maxNewNodes {
+					if structuredOnly || csvOnly || markdownOnly || htmlOnly {
+						break
+					}
+					if len(applier.scalablePools) != 0 {
+						return fmt.Errorf("%d pod(s) can not be scheduled even after adding %d node(s) from the scalable pools %s", len(result.UnscheduledPods), newNodeNum, strings.Join(applier.scalablePools, ", "))
+					}
+					return fmt.Errorf("%d pod(s) can not be scheduled even after adding %d node(s)", len(result.UnscheduledPods), newNodeNum)
+				}
+				newNodeNum++
+				continue
+			}
 			prompt := &survey.Select{
 				Message: fmt.Sprintf("there are still %d pod(s) that can not be scheduled when add %d nodes, you can:", len(result.UnscheduledPods), newNodeNum),
 				Options: []string{SurveyShowResults, SurveyAddNode, SurveyExit},
@@ -230,6 +795,9 @@ func (applier *Applier) Run() (err error) {
 				for i, pod := range result.UnscheduledPods {
 					pterm.FgLightWhite.Printf("%4d %s/%s: %s\n", i, pod.Pod.Namespace, pod.Pod.Name, pod.Reason)
 				}
+				if applier.verboseFailures {
+					reportVerboseFailures(result.UnscheduledPods)
+				}
 			case SurveyAddNode:
 				num := 0
 				prompt := &survey.Input{
@@ -247,29 +815,196 @@ func (applier *Applier) Run() (err error) {
 				break
 			}
 		} else {
-			if ok, reason, err := satisfyResourceSetting(result.NodeStatus); err != nil {
+			ok, reason, err := applier.checkSuccess(result.NodeStatus)
+			if err != nil {
 				return err
-			} else if !ok {
-				pterm.FgLightWhite.Println(reason)
-			} else {
+			}
+			if ok {
 				canBeScheduled = true
 				break
 			}
+			pterm.FgLightWhite.Println(reason)
+			// the thresholds judge the final state and adding more nodes needs an
+			// explicit choice, so surface the verdict through the exit code instead
+			// of re-simulating the same cluster
+			thresholdReason = strings.TrimSpace(reason)
+			if structuredOnly || csvOnly || markdownOnly || htmlOnly {
+				break
+			}
+			return fmt.Errorf("all pods can be scheduled but the result violates the resource thresholds: %s ", thresholdReason)
 		}
 	}
 
+	applier.addedNodes = newNodeNum
+	if applier.webhook != "" && result != nil {
+		postWebhook(applier.webhook, applier.webhookHeaders, buildSimulationReport(result, selectedAppNameList, newNodeNum, canBeScheduled))
+	}
+	if applier.metricsFile != "" && result != nil {
+		if err := applier.writeMetrics(result, newNodeNum); err != nil {
+			return err
+		}
+	}
+	if applier.exportResultFile != "" && result != nil {
+		if err := exportResult(applier.exportResultFile, result); err != nil {
+			return err
+		}
+	}
+
+	if structuredOnly || csvOnly || markdownOnly || htmlOnly {
+		if csvOnly {
+			err = applier.writeCSVOutput(result)
+		} else if markdownOnly {
+			err = applier.writeMarkdownOutput(result, newNodeNum, canBeScheduled)
+		} else if htmlOnly {
+			err = applier.writeHTMLOutput(result, newNodeNum, canBeScheduled)
+		} else {
+			err = applier.writeStructuredOutput(result, selectedAppNameList, newNodeNum, canBeScheduled)
+		}
+		if err != nil {
+			return err
+		}
+		if !canBeScheduled {
+			if thresholdReason != "" {
+				return fmt.Errorf("all pods can be scheduled but the result violates the resource thresholds: %s ", thresholdReason)
+			}
+			return fmt.Errorf("%d pod(s) can not be scheduled ", len(result.UnscheduledPods))
+		}
+		return applier.checkWarnings()
+	}
+	if canBeScheduled && countOnly {
+		if err := applier.checkWarnings(); err != nil {
+			return err
+		}
+		if !applier.batch {
+			fmt.Println(newNodeNum)
+		}
+		return nil
+	}
 	if canBeScheduled {
 		pterm.FgGreen.Println("Simulation success!")
-		report(result.NodeStatus, applier.extendedResources, selectedAppNameList)
+		for resourceName, cap := range applier.utilizationCaps {
+			pterm.FgLightWhite.Printf("node utilization capped at %d%% %s\n", cap, resourceName)
+		}
+		if expander != "" && len(expanderDecisions) != 0 {
+			pterm.FgYellow.Println("Expander Decisions")
+			for _, decision := range expanderDecisions {
+				pterm.FgLightWhite.Println(decision)
+			}
+			pterm.FgYellow.Println()
+		}
+		if applier.explainScale {
+			reportScaleExplanation(result.NodeStatus, unscheduledHistory)
+		}
+		if applier.validateConstraints {
+			reportConstraintViolations(result.NodeStatus)
+		}
+		if len(applier.spreadRequirements) != 0 {
+			services := append([]*corev1.Service{}, clusterResourceCopy.Services...)
+			for _, selected := range selectedResourceList {
+				services = append(services, selected.Resource.Services...)
+			}
+			reportSpreadViolations(result.NodeStatus, services, applier.spreadRequirements)
+		}
+		reportTopologySpread(result.NodeStatus)
+		reportAntiAffinity(result.NodeStatus)
+		if len(applier.podOverhead) != 0 {
+			reportPodOverheadTax(result.NodeStatus, applier.podOverhead)
+		}
+		if len(applier.evictionThresholds) != 0 {
+			reportEvictionRisk(result.NodeStatus, applier.evictionThresholds)
+		}
+		if len(applier.requestMultipliers) != 0 {
+			if err := reportEffectiveAppDemand(result.NodeStatus, applier.requestMultipliers); err != nil {
+				return err
+			}
+		}
+		if len(applier.systemOverhead) != 0 {
+			reportSystemOverhead(result.NodeStatus, applier.systemOverhead)
+		}
+		reportReservedResources(result.NodeStatus)
+		if err := reportVolumePlacement(result.NodeStatus); err != nil {
+			return err
+		}
+		if err := reportPVCBindings(result.PVCBindings); err != nil {
+			return err
+		}
+		reportClusterTotals(result.NodeStatus, applier.displayUnits)
+		if applier.deschedule {
+			if err := reportDeschedule(result); err != nil {
+				return err
+			}
+		}
+		if len(applier.waves) != 0 {
+			if err := applier.reportWaveAnalysis(finalClusterResource, selectedResourceList); err != nil {
+				return err
+			}
+		}
+		if applier.repeat > 1 {
+			if err := applier.reportPlacementStability(finalClusterResource, selectedResourceList); err != nil {
+				return err
+			}
+		}
+		reportPoolInfo(result.NodeStatus, applier.poolLabel)
+		reportPackingAnalysis(result.NodeStatus, applier.resourceWeights)
+		if applier.analyzeStartup {
+			reportStartupSpikeAnalysis(result.NodeStatus)
+		}
+		if applier.recommendationsFile != "" {
+			if err := writeRecommendations(applier.recommendationsFile, result.NodeStatus); err != nil {
+				return err
+			}
+		}
+		if applier.exportKwokDir != "" {
+			if err := exportKwokManifests(applier.exportKwokDir, result.NodeStatus); err != nil {
+				return err
+			}
+		}
+		if applier.attributionFile != "" {
+			if err := writeAttribution(applier.attributionFile, result.NodeStatus, applier.attributionLabels); err != nil {
+				return err
+			}
+		}
+		if applier.outputMode == OutputModeDescribe {
+			reportDescribe(result.NodeStatus)
+		} else if applier.outputMode == OutputModeBars {
+			reportBars(result.NodeStatus)
+		} else {
+			report(result.NodeStatus, applier.extendedResources, selectedAppNameList, applier.displayUnits, applier.namespaces)
+		}
 	}
 
-	return nil
+	// reaching this point without success means the user exited the survey with pods
+	// still pending; CI gating needs that verdict in the exit code, not only in red text
+	if !canBeScheduled {
+		return fmt.Errorf("simulation exited with %d pod(s) unscheduled ", len(result.UnscheduledPods))
+	}
+
+	return applier.checkWarnings()
+}
+
+// checkWarnings enumerates the warnings collected during the run and turns them into an
+// error in strict mode; outside strict mode warnings are dropped silently
+func (applier *Applier) checkWarnings() error {
+	warnings := utils.DrainWarnings()
+	if !applier.strict || len(warnings) == 0 {
+		return nil
+	}
+	pterm.FgRed.Printf("%d warning(s) treated as errors in strict mode:\n", len(warnings))
+	for i, warning := range warnings {
+		pterm.FgLightWhite.Printf("%4d [%s] %s\n", i, warning.Source, warning.Message)
+	}
+	return fmt.Errorf("strict mode: %d warning(s) emitted during the run ", len(warnings))
 }
 
 func validate(applier *Applier) error {
-	if len(applier.cluster.KubeConfig) == 0 && len(applier.cluster.CustomCluster) == 0 ||
-		len(applier.cluster.KubeConfig) != 0 && len(applier.cluster.CustomCluster) != 0 {
-		return fmt.Errorf("only one of values of both kubeConfig and customConfig must exist ")
+	clusterSources := 0
+	for _, source := range []string{applier.cluster.KubeConfig, applier.cluster.CustomCluster, applier.cluster.SnapshotDir} {
+		if len(source) != 0 {
+			clusterSources++
+		}
+	}
+	if clusterSources != 1 {
+		return fmt.Errorf("exactly one of kubeConfig, customConfig and snapshotDir must exist ")
 	}
 
 	if len(applier.cluster.KubeConfig) != 0 {
@@ -284,18 +1019,40 @@ func validate(applier *Applier) error {
 		}
 	}
 
+	if len(applier.cluster.SnapshotDir) != 0 {
+		if _, err := os.Stat(applier.cluster.SnapshotDir); err != nil {
+			return fmt.Errorf("invalid path of snapshotDir: %v ", err)
+		}
+	}
+
 	if len(applier.schedulerConfig) != 0 {
 		if _, err := os.Stat(applier.schedulerConfig); err != nil {
 			return fmt.Errorf("invalid path of scheduler config: %v ", err)
 		}
 	}
 
+	if applier.maxNewNodes < 0 {
+		return fmt.Errorf("invalid max new nodes %d, must be non-negative ", applier.maxNewNodes)
+	}
+
+	switch applier.outputMode {
+	case "", OutputModeCount, OutputModeDescribe, OutputModeBars, OutputModeJSON, OutputModeYAML, OutputModeCSV, OutputModeMarkdown, OutputModeHTML:
+	default:
+		return fmt.Errorf("invalid output mode %s, supported: %s, %s, %s, %s, %s, %s, %s, %s ", applier.outputMode, OutputModeCount, OutputModeDescribe, OutputModeBars, OutputModeJSON, OutputModeYAML, OutputModeCSV, OutputModeMarkdown, OutputModeHTML)
+	}
+
 	if len(applier.newNodePath) != 0 {
 		if _, err := os.Stat(applier.newNodePath); err != nil {
 			return fmt.Errorf("invalid path of newNode: %v ", err)
 		}
 	}
 
+	for _, template := range applier.newNodeTemplates {
+		if _, err := os.Stat(template.Path); err != nil {
+			return fmt.Errorf("invalid path of newNode template: %v ", err)
+		}
+	}
+
 	for _, app := range applier.appList {
 		if _, err := os.Stat(app.Path); err != nil {
 			return fmt.Errorf("invalid path of %s app: %v ", app.Name, err)
@@ -305,14 +1062,225 @@ func validate(applier *Applier) error {
 	return nil
 }
 
+// reportConstraintViolations prints pods whose placement breaks a required pod (anti-)affinity term
+func reportConstraintViolations(nodeStatuses []simulator.NodeStatus) {
+	violations := simulator.ValidateAffinityConstraints(nodeStatuses)
+	if len(violations) == 0 {
+		pterm.FgGreen.Println("no placement violates required pod affinity/anti-affinity")
+		return
+	}
+	pterm.FgRed.Printf("%d placement(s) violate required pod affinity/anti-affinity:\n", len(violations))
+	for i, violation := range violations {
+		pterm.FgLightWhite.Printf("%4d %s/%s on %s: %s\n", i, violation.Pod.Namespace, violation.Pod.Name, violation.Node, violation.Message)
+	}
+}
+
+// reportTopologySpread prints, for every topologySpreadConstraint declared by a scheduled
+// pod, whether the final placement honors it. Nothing is printed when no pod declares one.
+func reportTopologySpread(nodeStatuses []simulator.NodeStatus) {
+	declared := false
+	for _, status := range nodeStatuses {
+		for _, pod := range status.Pods {
+			if len(pod.Spec.TopologySpreadConstraints) != 0 {
+				declared = true
+				break
+			}
+		}
+	}
+	if !declared {
+		return
+	}
+
+	pterm.FgYellow.Println("Topology Spread")
+	violations := simulator.ValidateTopologySpread(nodeStatuses)
+	if len(violations) == 0 {
+		pterm.FgGreen.Println("all topology spread constraints are honored")
+	} else {
+		pterm.FgRed.Printf("%d topology spread constraint violation(s):\n", len(violations))
+		for i, violation := range violations {
+			pterm.FgLightWhite.Printf("%4d %s\n", i, violation.Message)
+		}
+	}
+	pterm.FgYellow.Println()
+}
+
+// reportAntiAffinity prints, per scheduled pod with pod anti-affinity, whether its
+// terms held in the final placement, keeping the soft preferred-term violations apart
+// from the hard required-term failures. Nothing is printed when no pod declares one.
+func reportAntiAffinity(nodeStatuses []simulator.NodeStatus) {
+	statuses := simulator.AnalyzeAntiAffinity(nodeStatuses)
+	if len(statuses) == 0 {
+		return
+	}
+
+	pterm.FgYellow.Println("Anti-Affinity")
+	satisfied := 0
+	requiredFailures := 0
+	coLocated := 0
+	for _, status := range statuses {
+		if status.Satisfied() {
+			satisfied++
+			continue
+		}
+		requiredFailures += len(status.RequiredViolations)
+		coLocated += status.CoLocatedPeers
+		for _, violation := range status.RequiredViolations {
+			pterm.FgRed.Printf("%s on %s: %s\n", status.Pod, status.Node, violation)
+		}
+		for _, violation := range status.PreferredViolations {
+			pterm.FgLightWhite.Printf("%s on %s: %s\n", status.Pod, status.Node, violation)
+		}
+	}
+	if satisfied == len(statuses) {
+		pterm.FgGreen.Printf("all %d pod(s) with anti-affinity are placed as declared\n", len(statuses))
+	} else {
+		pterm.FgLightWhite.Printf("%d of %d pod(s) satisfied, %d required failure(s), %d co-location(s) against preferred terms\n",
+			satisfied, len(statuses), requiredFailures, coLocated)
+	}
+	pterm.FgYellow.Println()
+}
+
+// reportSpreadViolations prints services whose pods concentrate in one failure domain
+// beyond the configured limit
+func reportSpreadViolations(nodeStatuses []simulator.NodeStatus, services []*corev1.Service, requirements []v1alpha1.SpreadRequirement) {
+	var spreadRequirements []simulator.ServiceSpreadRequirement
+	for _, requirement := range requirements {
+		spreadRequirements = append(spreadRequirements, simulator.ServiceSpreadRequirement{
+			Service:     requirement.Service,
+			TopologyKey: requirement.TopologyKey,
+			MaxPercent:  requirement.MaxPercent,
+		})
+	}
+	violations := simulator.ValidateServiceSpread(nodeStatuses, services, spreadRequirements)
+	if len(violations) == 0 {
+		pterm.FgGreen.Println("no service exceeds its failure-domain spread requirement")
+		return
+	}
+	pterm.FgRed.Printf("%d failure-domain spread requirement violation(s):\n", len(violations))
+	for i, violation := range violations {
+		pterm.FgLightWhite.Printf("%4d %s: %s\n", i, violation.Service, violation.Message)
+	}
+}
+
 // report print out scheduling result of pods
-func report(nodeStatuses []simulator.NodeStatus, extendedResources []string, appNameList []string) {
-	reportClusterInfo(nodeStatuses, extendedResources)
-	reportNodeInfo(nodeStatuses, extendedResources)
-	reportAppInfo(nodeStatuses, appNameList)
+func report(nodeStatuses []simulator.NodeStatus, extendedResources []string, appNameList []string, displayUnits map[corev1.ResourceName]string, namespaces []string) {
+	// a node with zero allocatable would turn the fractions below into NaN, so keep it
+	// out of the tables and call it out instead
+	nodeStatuses, misconfigured := splitMisconfiguredNodes(nodeStatuses)
+	reportMisconfiguredNodes(misconfigured)
+	// the node totals deliberately account for every pod, only the per-pod tables below
+	// are narrowed to the requested namespaces
+	reportClusterInfo(nodeStatuses, extendedResources, displayUnits)
+	reportProfileInfo(nodeStatuses)
+	reportNodeInfo(nodeStatuses, extendedResources, displayUnits, namespaces)
+	reportAppInfo(nodeStatuses, appNameList, namespaces)
+}
+
+// reportParseSummary shows in validate-only mode what the cluster files, app manifests
+// and node templates parsed into, so a CI run can sanity-check the inputs without paying
+// for a simulation
+func reportParseSummary(cluster simulator.ResourceTypes, resourceList []string, resourceMap map[string]simulator.ResourceTypes, newNodeTemplates []*corev1.Node) {
+	pterm.FgYellow.Println("Parse Summary")
+	pterm.FgLightWhite.Printf("cluster: %d node(s), %d pod(s), %d pvc(s)\n", len(cluster.Nodes), len(cluster.Pods), len(cluster.PersistentVolumeClaims))
+	for _, name := range resourceList {
+		appResource := resourceMap[name]
+		pterm.FgLightWhite.Printf("app %s: %d pod(s), %d deployment(s), %d statefulset(s), %d daemonset(s), %d job(s), %d pvc(s)\n",
+			name, len(appResource.Pods), len(appResource.Deployments), len(appResource.StatefulSets), len(appResource.DaemonSets), len(appResource.Jobs), len(appResource.PersistentVolumeClaims))
+	}
+	pterm.FgLightWhite.Printf("new node: %d template(s)\n", len(newNodeTemplates))
+	pterm.FgYellow.Println()
+}
+
+// reportScaleExplanation explains, per added node, which previously unscheduled pods it
+// enabled, derived from comparing the unscheduled sets across the scaling iterations
+func reportScaleExplanation(nodeStatuses []simulator.NodeStatus, unscheduledHistory map[string]string) {
+	if len(unscheduledHistory) == 0 {
+		return
+	}
+	pterm.FgYellow.Println("Scale Explanation")
+	explained := false
+	for _, status := range nodeStatuses {
+		if _, exist := status.Node.Labels[simontype.LabelNewNode]; !exist {
+			continue
+		}
+		for _, pod := range status.Pods {
+			key := fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)
+			reason, wasUnscheduled := unscheduledHistory[key]
+			if !wasUnscheduled {
+				continue
+			}
+			pterm.FgLightWhite.Printf("%s added to host %s (%s)\n", status.Node.Name, key, boundResource(reason))
+			explained = true
+		}
+	}
+	if !explained {
+		pterm.FgLightWhite.Println("the added node(s) freed up capacity indirectly, no unscheduled pod landed on them")
+	}
+	pterm.FgYellow.Println()
+}
+
+// boundResource condenses a scheduling failure reason to the resource that blocked the
+// pod, e.g. "memory-bound", falling back to the raw reason when none is recognizable
+func boundResource(reason string) string {
+	for _, resourceName := range []string{"cpu", "memory", "ephemeral-storage"} {
+		if strings.Contains(reason, "Insufficient "+resourceName) {
+			return resourceName + "-bound"
+		}
+	}
+	if index := strings.Index(reason, "Insufficient "); index != -1 {
+		rest := reason[index+len("Insufficient "):]
+		if end := strings.IndexAny(rest, ",;)( "); end != -1 {
+			rest = rest[:end]
+		}
+		return rest + "-bound"
+	}
+	return reason
+}
+
+// reportProfileInfo segments the placements per scheduler profile; it only shows up when
+// the config routes pods to more than one profile
+func reportProfileInfo(nodeStatuses []simulator.NodeStatus) {
+	profilePods := make(map[string]int)
+	profileNodes := make(map[string]map[string]struct{})
+	for _, status := range nodeStatuses {
+		for _, pod := range status.Pods {
+			profilePods[pod.Spec.SchedulerName]++
+			if profileNodes[pod.Spec.SchedulerName] == nil {
+				profileNodes[pod.Spec.SchedulerName] = make(map[string]struct{})
+			}
+			profileNodes[pod.Spec.SchedulerName][status.Node.Name] = struct{}{}
+		}
+	}
+	if len(profilePods) <= 1 {
+		return
+	}
+
+	var profiles []string
+	for profileName := range profilePods {
+		profiles = append(profiles, profileName)
+	}
+	sort.Strings(profiles)
+
+	pterm.FgYellow.Println("Scheduler Profile Info")
+	profileTable := pterm.DefaultTable.WithHasHeader()
+	profileTableData := [][]string{
+		{"Profile", "Pods", "Nodes Used"},
+	}
+	for _, profileName := range profiles {
+		profileTableData = append(profileTableData, []string{
+			profileName,
+			strconv.Itoa(profilePods[profileName]),
+			strconv.Itoa(len(profileNodes[profileName])),
+		})
+	}
+	if err := profileTable.WithData(profileTableData).Render(); err != nil {
+		pterm.FgRed.Printf("fail to render profile table: %s\n", err.Error())
+		os.Exit(1)
+	}
+	pterm.FgYellow.Println()
 }
 
-func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []string) {
+func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []string, displayUnits map[corev1.ResourceName]string) {
 	pterm.FgYellow.Println("Node Info")
 	clusterTable := pterm.DefaultTable.WithHasHeader()
 	var clusterTableData [][]string
@@ -320,21 +1288,40 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 		"Node",
 		"CPU Allocatable",
 		"CPU Requests",
+		"CPU Free",
 		"Memory Allocatable",
 		"Memory Requests",
+		"Memory Free",
 	}
 	if containGpu(extendedResources) {
 		nodeTableHeader = append(nodeTableHeader, []string{
 			"GPU Mem Allocatable",
 			"GPU Mem Requests",
+			"GPU Mem Free",
 		}...)
 	}
+	if containEphemeralStorage(extendedResources) {
+		nodeTableHeader = append(nodeTableHeader, []string{
+			"Ephemeral Storage Allocatable",
+			"Ephemeral Storage Requests",
+			"Ephemeral Storage Free",
+		}...)
+	}
+	// any further extended resource a node advertises gets one requests/allocatable column
+	genericResources := genericExtendedResources(extendedResources, nodeStatuses)
+	for _, name := range genericResources {
+		nodeTableHeader = append(nodeTableHeader, string(name))
+	}
 	nodeTableHeader = append(nodeTableHeader, []string{
+		"Fragmentation",
 		"Pod Count",
 		"New Node",
 	}...)
 	clusterTableData = append(clusterTableData, nodeTableHeader)
 
+	// the largest pod shape of the run is the yardstick for stranded free capacity
+	shape := largestPodShape(nodeStatuses)
+
 	nodeReq := make(map[string]map[corev1.ResourceName]resource.Quantity, len(nodeStatuses))
 	for _, status := range nodeStatuses {
 		reqs := make(map[corev1.ResourceName]resource.Quantity)
@@ -362,13 +1349,21 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 		if _, exist := node.Labels[simontype.LabelNewNode]; exist {
 			newNode = "√"
 		}
+		// free capacity as absolute quantities makes stranded resources visible,
+		// e.g. plenty of memory left but no cpu
+		nodeCpuFree := allocatable.Cpu().DeepCopy()
+		nodeCpuFree.Sub(nodeCpuReq)
+		nodeMemoryFree := allocatable.Memory().DeepCopy()
+		nodeMemoryFree.Sub(nodeMemoryReq)
 
 		data := []string{
 			node.Name,
-			allocatable.Cpu().String(),
-			fmt.Sprintf("%s(%d%%)", nodeCpuReq.String(), int64(nodeCpuReqFraction)),
-			allocatable.Memory().String(),
-			fmt.Sprintf("%s(%d%%)", nodeMemoryReq.String(), int64(nodeMemoryReqFraction)),
+			formatQuantity(allocatable.Cpu(), corev1.ResourceCPU, displayUnits),
+			fmt.Sprintf("%s(%d%%)", formatQuantity(&nodeCpuReq, corev1.ResourceCPU, displayUnits), int64(nodeCpuReqFraction)),
+			formatQuantity(&nodeCpuFree, corev1.ResourceCPU, displayUnits),
+			formatQuantity(allocatable.Memory(), corev1.ResourceMemory, displayUnits),
+			fmt.Sprintf("%s(%d%%)", formatQuantity(&nodeMemoryReq, corev1.ResourceMemory, displayUnits), int64(nodeMemoryReqFraction)),
+			formatQuantity(&nodeMemoryFree, corev1.ResourceMemory, displayUnits),
 		}
 		if containGpu(extendedResources) {
 			nodeGpuMemReq := resource.NewQuantity(0, resource.BinarySI)
@@ -378,12 +1373,44 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 				nodeGpuMemReq.Add(*gpuMemReq)
 			}
 			nodeGpuMemFraction := float64(nodeGpuMemReq.Value()) / float64(allocatable.Name(gpushareutils.ResourceName, resource.BinarySI).Value()) * 100
+			nodeGpuMemFree := allocatable.Name(gpushareutils.ResourceName, resource.BinarySI).DeepCopy()
+			nodeGpuMemFree.Sub(*nodeGpuMemReq)
 			data = append(data, []string{
 				allocatable.Name(gpushareutils.ResourceName, resource.BinarySI).String(),
 				fmt.Sprintf("%s(%d%%)", nodeGpuMemReq.String(), int64(nodeGpuMemFraction)),
+				nodeGpuMemFree.String(),
 			}...)
 		}
+		if containEphemeralStorage(extendedResources) {
+			nodeEphemeralReq := reqs[corev1.ResourceEphemeralStorage]
+			nodeEphemeralFraction := float64(0)
+			if allocatable.StorageEphemeral().Value() > 0 {
+				nodeEphemeralFraction = float64(nodeEphemeralReq.Value()) / float64(allocatable.StorageEphemeral().Value()) * 100
+			}
+			nodeEphemeralFree := allocatable.StorageEphemeral().DeepCopy()
+			nodeEphemeralFree.Sub(nodeEphemeralReq)
+			data = append(data, []string{
+				allocatable.StorageEphemeral().String(),
+				fmt.Sprintf("%s(%d%%)", nodeEphemeralReq.String(), int64(nodeEphemeralFraction)),
+				nodeEphemeralFree.String(),
+			}...)
+		}
+		for _, name := range genericResources {
+			allocQuantity, exist := allocatable[name]
+			if !exist {
+				// the node does not advertise this resource
+				data = append(data, "-")
+				continue
+			}
+			reqQuantity := reqs[name]
+			fraction := float64(0)
+			if allocQuantity.Value() > 0 {
+				fraction = float64(reqQuantity.Value()) / float64(allocQuantity.Value()) * 100
+			}
+			data = append(data, fmt.Sprintf("%s/%s(%d%%)", reqQuantity.String(), allocQuantity.String(), int64(fraction)))
+		}
 		data = append(data, []string{
+			fmt.Sprintf("%d%%", fragmentationPercent(status, shape)),
 			fmt.Sprintf("%d", len(status.Pods)),
 			newNode,
 		}...)
@@ -394,6 +1421,7 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 		os.Exit(1)
 	}
 	pterm.FgYellow.Println()
+	reportFragmentationSummary(nodeStatuses, shape)
 
 	// report extended resource info (e.g., node storage, GPU)
 	if len(extendedResources) != 0 {
@@ -523,7 +1551,7 @@ func reportClusterInfo(nodeStatuses []simulator.NodeStatus, extendedResources []
 	}
 }
 
-func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []string) {
+func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []string, displayUnits map[corev1.ResourceName]string, namespaces []string) {
 	var nodeList []string
 	var selectedNodeList []string
 	selectedNodeMap := make(map[string]struct{})
@@ -551,6 +1579,7 @@ func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []str
 		"Pod",
 		"CPU Requests",
 		"Memory Requests",
+		"QoS",
 	}
 	if containLocalStorage(extendedResources) {
 		header = append(header, "Volume Request")
@@ -558,6 +1587,9 @@ func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []str
 	if containGpu(extendedResources) {
 		header = append(header, "GPU Mem Requests")
 	}
+	if containEphemeralStorage(extendedResources) {
+		header = append(header, "Ephemeral Storage Requests")
+	}
 	header = append(header, "APP Name")
 
 	for _, status := range nodeStatuses {
@@ -574,6 +1606,9 @@ func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []str
 			if pod.Spec.NodeName != node.Name {
 				continue
 			}
+			if !namespaceSelected(namespaces, pod.Namespace) {
+				continue
+			}
 			req, limit := resourcehelper.PodRequestsAndLimits(pod)
 			cpuReq, _, memoryReq, _ := req[corev1.ResourceCPU], limit[corev1.ResourceCPU], req[corev1.ResourceMemory], limit[corev1.ResourceMemory]
 			fractionCpuReq := float64(cpuReq.MilliValue()) / float64(allocatable.Cpu().MilliValue()) * 100
@@ -586,8 +1621,9 @@ func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []str
 			}
 			data := []string{
 				fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
-				fmt.Sprintf("%s(%d%%)", cpuReq.String(), int64(fractionCpuReq)),
-				fmt.Sprintf("%s(%d%%)", memoryReq.String(), int64(fractionMemoryReq)),
+				fmt.Sprintf("%s(%d%%)", formatQuantity(&cpuReq, corev1.ResourceCPU, displayUnits), int64(fractionCpuReq)),
+				fmt.Sprintf("%s(%d%%)", formatQuantity(&memoryReq, corev1.ResourceMemory, displayUnits), int64(fractionMemoryReq)),
+				string(utils.GetPodQoSClass(pod)),
 			}
 
 			// Storage
@@ -614,6 +1650,16 @@ func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []str
 				data = append(data, fmt.Sprintf("%s(%d%%)", gpuMemReq.String(), int64(fractionGpuMemReq)))
 			}
 
+			// Ephemeral storage
+			if containEphemeralStorage(extendedResources) {
+				ephemeralReq := req[corev1.ResourceEphemeralStorage]
+				fractionEphemeralReq := float64(0)
+				if allocatable.StorageEphemeral().Value() > 0 {
+					fractionEphemeralReq = float64(ephemeralReq.Value()) / float64(allocatable.StorageEphemeral().Value()) * 100
+				}
+				data = append(data, fmt.Sprintf("%s(%d%%)", ephemeralReq.String(), int64(fractionEphemeralReq)))
+			}
+
 			data = append(data, appname)
 			podTableData = append(podTableData, data)
 		}
@@ -625,7 +1671,7 @@ func reportNodeInfo(nodeStatuses []simulator.NodeStatus, extendedResources []str
 	}
 }
 
-func reportAppInfo(nodeStatuses []simulator.NodeStatus, appNameList []string) {
+func reportAppInfo(nodeStatuses []simulator.NodeStatus, appNameList []string, namespaces []string) {
 	var selectedAppNameList []string
 	if len(appNameList) == 0 {
 		return
@@ -660,6 +1706,9 @@ func reportAppInfo(nodeStatuses []simulator.NodeStatus, appNameList []string) {
 			var podTableData [][]string
 			podTableData = append(podTableData, header)
 			for _, pod := range status.Pods {
+				if !namespaceSelected(namespaces, pod.Namespace) {
+					continue
+				}
 				// app name
 				appname := ""
 				if str, exist := pod.Labels[simontype.LabelAppName]; exist {
@@ -686,38 +1735,9 @@ func reportAppInfo(nodeStatuses []simulator.NodeStatus, appNameList []string) {
 	}
 }
 
-func satisfyResourceSetting(nodeStatuses []simulator.NodeStatus) (bool, string, error) {
-	var err error
-	var maxcpu int = 100
-	var maxmem int = 100
-	var maxvg int = 100
-	if str := os.Getenv(simontype.EnvMaxCPU); str != "" {
-		if maxcpu, err = strconv.Atoi(str); err != nil {
-			return false, "", fmt.Errorf("failed to convert env %s to int: %s ", simontype.EnvMaxCPU, err.Error())
-		}
-		if maxcpu > 100 || maxcpu < 0 {
-			maxcpu = 100
-		}
-	}
-
-	if str := os.Getenv(simontype.EnvMaxMemory); str != "" {
-		if maxmem, err = strconv.Atoi(str); err != nil {
-			return false, "", fmt.Errorf("failed to convert env %s to int: %s ", simontype.EnvMaxMemory, err.Error())
-		}
-		if maxmem > 100 || maxmem < 0 {
-			maxmem = 100
-		}
-	}
-
-	if str := os.Getenv(simontype.EnvMaxVG); str != "" {
-		if maxvg, err = strconv.Atoi(str); err != nil {
-			return false, "", fmt.Errorf("failed to convert env %s to int: %s ", simontype.EnvMaxVG, err.Error())
-		}
-		if maxvg > 100 || maxvg < 0 {
-			maxvg = 100
-		}
-	}
-
+// clusterResourceTotals sums allocatable and requested cpu, memory and VG storage across
+// the whole cluster
+func clusterResourceTotals(nodeStatuses []simulator.NodeStatus) (map[corev1.ResourceName]*resource.Quantity, map[corev1.ResourceName]*resource.Quantity, localcache.SharedResource, error) {
 	totalAllocatableResource := map[corev1.ResourceName]*resource.Quantity{
 		corev1.ResourceCPU:    resource.NewQuantity(0, resource.DecimalSI),
 		corev1.ResourceMemory: resource.NewQuantity(0, resource.DecimalSI),
@@ -746,7 +1766,7 @@ func satisfyResourceSetting(nodeStatuses []simulator.NodeStatus) (bool, string,
 		if nodeStorageStr, exist := node.Annotations[simontype.AnnoNodeLocalStorage]; exist {
 			var nodeStorage utils.NodeStorage
 			if err := ffjson.Unmarshal([]byte(nodeStorageStr), &nodeStorage); err != nil {
-				return false, "", fmt.Errorf("error when unmarshal json data, node is %s\n", node.Name)
+				return nil, nil, totalVGResource, fmt.Errorf("error when unmarshal json data, node is %s\n", node.Name)
 			}
 			for _, vg := range nodeStorage.VGs {
 				totalVGResource.Requested += vg.Requested
@@ -754,9 +1774,101 @@ func satisfyResourceSetting(nodeStatuses []simulator.NodeStatus) (bool, string,
 			}
 		}
 	}
+	return totalAllocatableResource, totalUsedResource, totalVGResource, nil
+}
 
-	cpuOccupancyRate := int(float64(totalUsedResource[corev1.ResourceCPU].MilliValue()) / float64(totalAllocatableResource[corev1.ResourceCPU].MilliValue()) * 100)
-	memoryOccupancyRate := int(float64(totalUsedResource[corev1.ResourceMemory].MilliValue()) / float64(totalAllocatableResource[corev1.ResourceMemory].MilliValue()) * 100)
+// reportClusterTotals prints cluster-wide allocatable, requested and absolute free
+// capacity per resource, so planners see the headroom at a glance
+func reportClusterTotals(nodeStatuses []simulator.NodeStatus, displayUnits map[corev1.ResourceName]string) {
+	totalAllocatableResource, totalUsedResource, totalVGResource, err := clusterResourceTotals(nodeStatuses)
+	if err != nil {
+		pterm.FgRed.Printf("fail to sum cluster totals: %s\n", err.Error())
+		return
+	}
+
+	pterm.FgYellow.Println("Cluster Totals")
+	totalsTable := pterm.DefaultTable.WithHasHeader()
+	totalsTableData := [][]string{
+		{"Resource", "Allocatable", "Requested", "Free"},
+	}
+	for _, resourceName := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		free := totalAllocatableResource[resourceName].DeepCopy()
+		free.Sub(*totalUsedResource[resourceName])
+		totalsTableData = append(totalsTableData, []string{
+			string(resourceName),
+			formatQuantity(totalAllocatableResource[resourceName], resourceName, displayUnits),
+			formatQuantity(totalUsedResource[resourceName], resourceName, displayUnits),
+			formatQuantity(&free, resourceName, displayUnits),
+		})
+	}
+	if totalVGResource.Capacity != 0 {
+		capacity := resource.NewQuantity(totalVGResource.Capacity, resource.BinarySI)
+		requested := resource.NewQuantity(totalVGResource.Requested, resource.BinarySI)
+		free := resource.NewQuantity(totalVGResource.Capacity-totalVGResource.Requested, resource.BinarySI)
+		totalsTableData = append(totalsTableData, []string{
+			"vg storage",
+			capacity.String(),
+			requested.String(),
+			free.String(),
+		})
+	}
+	if err := totalsTable.WithData(totalsTableData).Render(); err != nil {
+		pterm.FgRed.Printf("fail to render cluster totals table: %s\n", err.Error())
+		os.Exit(1)
+	}
+	pterm.FgYellow.Println()
+}
+
+// resolveThreshold picks the occupancy ceiling, in percent, for one resource: the CR
+// value wins over the environment variable, which wins over the default of 100.
+// Out-of-range values fall back to 100 like the env vars always did.
+func resolveThreshold(crValue int, envKey string) (int, error) {
+	if crValue != 0 {
+		if crValue > 100 || crValue < 0 {
+			return 100, nil
+		}
+		return crValue, nil
+	}
+	if str := os.Getenv(envKey); str != "" {
+		value, err := strconv.Atoi(str)
+		if err != nil {
+			return 0, fmt.Errorf("failed to convert env %s to int: %s ", envKey, err.Error())
+		}
+		if value > 100 || value < 0 {
+			return 100, nil
+		}
+		return value, nil
+	}
+	return 100, nil
+}
+
+func satisfyResourceSetting(nodeStatuses []simulator.NodeStatus, thresholds v1alpha1.ResourceThresholds) (bool, string, error) {
+	maxcpu, err := resolveThreshold(thresholds.CPU, simontype.EnvMaxCPU)
+	if err != nil {
+		return false, "", err
+	}
+	maxmem, err := resolveThreshold(thresholds.Memory, simontype.EnvMaxMemory)
+	if err != nil {
+		return false, "", err
+	}
+	maxvg, err := resolveThreshold(thresholds.VG, simontype.EnvMaxVG)
+	if err != nil {
+		return false, "", err
+	}
+	maxgpumem, err := resolveThreshold(thresholds.GPU, simontype.EnvMaxGPUMem)
+	if err != nil {
+		return false, "", err
+	}
+
+	// misconfigured nodes contribute no allocatable and must not poison the rates
+	nodeStatuses, _ = splitMisconfiguredNodes(nodeStatuses)
+	totalAllocatableResource, totalUsedResource, totalVGResource, err := clusterResourceTotals(nodeStatuses)
+	if err != nil {
+		return false, "", err
+	}
+
+	cpuOccupancyRate := occupancyPercent(totalUsedResource[corev1.ResourceCPU].MilliValue(), totalAllocatableResource[corev1.ResourceCPU].MilliValue())
+	memoryOccupancyRate := occupancyPercent(totalUsedResource[corev1.ResourceMemory].MilliValue(), totalAllocatableResource[corev1.ResourceMemory].MilliValue())
 	if cpuOccupancyRate > maxcpu {
 		return false, fmt.Sprintf("the average occupancy rate(%d%%) of cpu goes beyond the env setting(%d%%)\n", cpuOccupancyRate, maxcpu), nil
 	}
@@ -771,9 +1883,39 @@ func satisfyResourceSetting(nodeStatuses []simulator.NodeStatus) (bool, string,
 		}
 	}
 
+	// gpu memory only exists on nodes with the gpu extended resource enabled, so an
+	// all-zero total just means the threshold does not apply
+	var usedGpuMem, totalGpuMem int64
+	for _, status := range nodeStatuses {
+		totalGpuMem += gpushareutils.GetTotalGpuMemory(status.Node)
+		for _, pod := range status.Pods {
+			usedGpuMem += gpushareutils.GetGpuMemoryFromPodAnnotation(pod)
+		}
+	}
+	if totalGpuMem != 0 {
+		gpuMemOccupancyRate := occupancyPercent(usedGpuMem, totalGpuMem)
+		if gpuMemOccupancyRate > maxgpumem {
+			return false, fmt.Sprintf("the average occupancy rate(%d%%) of gpu memory goes beyond the env setting(%d%%)\n", gpuMemOccupancyRate, maxgpumem), nil
+		}
+	}
+
 	return true, "", nil
 }
 
+// namespaceSelected reports whether a pod in the given namespace passes the
+// --namespaces filter; an empty filter keeps every namespace
+func namespaceSelected(namespaces []string, namespace string) bool {
+	if len(namespaces) == 0 {
+		return true
+	}
+	for _, selected := range namespaces {
+		if selected == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 func containLocalStorage(extendedResources []string) bool {
 	for _, res := range extendedResources {
 		if res == "open-local" {
@@ -791,3 +1933,34 @@ func containGpu(extendedResources []string) bool {
 	}
 	return false
 }
+
+func containEphemeralStorage(extendedResources []string) bool {
+	for _, res := range extendedResources {
+		if res == "ephemeral-storage" {
+			return true
+		}
+	}
+	return false
+}
+
+// genericExtendedResources returns the extended-resource names that are not one of the
+// specially rendered keywords (open-local, gpu, ephemeral-storage) and that at least one
+// node advertises in its allocatable, e.g. nvidia.com/gpu or a custom device plugin.
+// Such resources get a generic requests/allocatable column without bespoke code.
+func genericExtendedResources(extendedResources []string, nodeStatuses []simulator.NodeStatus) []corev1.ResourceName {
+	var generic []corev1.ResourceName
+	for _, res := range extendedResources {
+		switch res {
+		case "open-local", "gpu", "ephemeral-storage":
+			continue
+		}
+		name := corev1.ResourceName(res)
+		for _, status := range nodeStatuses {
+			if _, exist := status.Node.Status.Allocatable[name]; exist {
+				generic = append(generic, name)
+				break
+			}
+		}
+	}
+	return generic
+}
@@ -0,0 +1,93 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRunWithoutNewNode pins down that a config without a newNode entry runs the
+// simulation instead of crashing; adding nodes is optional, the scaling loop must
+// treat a nil template as "nothing to add"
+func TestRunWithoutNewNode(t *testing.T) {
+	dir := t.TempDir()
+	clusterDir := filepath.Join(dir, "cluster")
+	appDir := filepath.Join(dir, "app")
+	for _, sub := range []string{clusterDir, appDir} {
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			t.Fatalf("failed to create fixture dir: %v", err)
+		}
+	}
+	nodeYaml := `apiVersion: v1
+kind: Node
+metadata:
+  name: node-1
+  labels:
+    kubernetes.io/hostname: node-1
+status:
+  capacity:
+    cpu: "8"
+    memory: 16Gi
+    pods: "110"
+  allocatable:
+    cpu: "8"
+    memory: 16Gi
+    pods: "110"
+`
+	appYaml := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: demo
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: demo
+  template:
+    metadata:
+      labels:
+        app: demo
+    spec:
+      containers:
+        - name: demo
+          image: busybox
+          resources:
+            requests:
+              cpu: 100m
+              memory: 100Mi
+`
+	simonConfig := fmt.Sprintf(`apiVersion: simon/v1alpha1
+kind: Config
+metadata:
+  name: test-config
+spec:
+  cluster:
+    customConfig: %s
+  appList:
+    - name: demo
+      path: %s
+`, clusterDir, appDir)
+	if err := ioutil.WriteFile(filepath.Join(clusterDir, "node.yaml"), []byte(nodeYaml), 0644); err != nil {
+		t.Fatalf("failed to write node fixture: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(appDir, "deployment.yaml"), []byte(appYaml), 0644); err != nil {
+		t.Fatalf("failed to write app fixture: %v", err)
+	}
+	configPath := filepath.Join(dir, "simon-config.yaml")
+	if err := ioutil.WriteFile(configPath, []byte(simonConfig), 0644); err != nil {
+		t.Fatalf("failed to write simon config: %v", err)
+	}
+
+	applier, err := newApplier(Options{SimonConfig: configPath, Output: OutputModeCount})
+	if err != nil {
+		t.Fatalf("newApplier() error = %v", err)
+	}
+	if err := applier.Run(); err != nil {
+		t.Fatalf("Run() without newNode error = %v, want the app scheduled onto the existing node", err)
+	}
+	if applier.addedNodes != 0 {
+		t.Errorf("Run() added %d node(s), want none without a newNode entry", applier.addedNodes)
+	}
+}
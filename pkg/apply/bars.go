@@ -0,0 +1,50 @@
+package apply
+
+import (
+	"strings"
+
+	"github.com/pterm/pterm"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// utilizationBarWidth is the number of cells of one ASCII utilization bar
+const utilizationBarWidth = 8
+
+// reportBars renders one line per node with compact ASCII utilization bars, which scans
+// faster than the full tables on large clusters
+func reportBars(nodeStatuses []simulator.NodeStatus) {
+	nameWidth := 0
+	for _, status := range nodeStatuses {
+		if len(status.Node.Name) > nameWidth {
+			nameWidth = len(status.Node.Name)
+		}
+	}
+	for _, status := range nodeStatuses {
+		node := status.Node
+		var usedCPU, usedMemory int64
+		for _, pod := range status.Pods {
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			usedCPU += podReqs.Cpu().MilliValue()
+			usedMemory += podReqs.Memory().Value()
+		}
+		cpuPercent := fraction(usedCPU, node.Status.Allocatable.Cpu().MilliValue())
+		memoryPercent := fraction(usedMemory, node.Status.Allocatable.Memory().Value())
+		pterm.Printf("%-*s CPU %s %3d%% MEM %s %3d%%\n", nameWidth, node.Name,
+			utilizationBar(cpuPercent), cpuPercent,
+			utilizationBar(memoryPercent), memoryPercent)
+	}
+}
+
+// utilizationBar draws a fixed-width ASCII bar for a percent value
+func utilizationBar(percent int64) string {
+	filled := int(percent) * utilizationBarWidth / 100
+	if filled > utilizationBarWidth {
+		filled = utilizationBarWidth
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", utilizationBarWidth-filled) + "]"
+}
@@ -0,0 +1,90 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+)
+
+// BatchResult is the outcome of one config of a batch run
+type BatchResult struct {
+	Config     string
+	AddedNodes int
+	Err        error
+}
+
+// RunBatch runs every simon config listed in configsFile, one per line, and prints a
+// combined summary table. A failing config is recorded in the summary instead of
+// aborting the batch; the returned error reflects the overall verdict.
+func RunBatch(configsFile string, opts Options) error {
+	content, err := ioutil.ReadFile(configsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read configs file %s: %v ", configsFile, err)
+	}
+	var configs []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		configs = append(configs, line)
+	}
+	if len(configs) == 0 {
+		return fmt.Errorf("configs file %s lists no configs ", configsFile)
+	}
+
+	var results []BatchResult
+	for _, config := range configs {
+		results = append(results, runBatchEntry(config, opts))
+	}
+
+	// the runs redirect pterm while simulating, restore it for the summary
+	pterm.SetDefaultOutput(os.Stdout)
+	pterm.FgYellow.Println("Batch Summary")
+	summaryTable := pterm.DefaultTable.WithHasHeader()
+	summaryTableData := [][]string{
+		{"Config", "Added Nodes", "Verdict"},
+	}
+	failed := 0
+	for _, result := range results {
+		verdict := "ok"
+		if result.Err != nil {
+			verdict = result.Err.Error()
+			failed++
+		}
+		summaryTableData = append(summaryTableData, []string{
+			result.Config,
+			strconv.Itoa(result.AddedNodes),
+			verdict,
+		})
+	}
+	if err := summaryTable.WithData(summaryTableData).Render(); err != nil {
+		return fmt.Errorf("fail to render batch summary table: %s ", err.Error())
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d config(s) failed ", failed, len(results))
+	}
+	return nil
+}
+
+// runBatchEntry runs a single config in batch mode, i.e. non-interactively and without
+// per-config report tables
+func runBatchEntry(config string, opts Options) BatchResult {
+	opts.SimonConfig = config
+	opts.Interactive = false
+	applier, err := newApplier(opts)
+	if err != nil {
+		return BatchResult{Config: config, Err: err}
+	}
+	applier.batch = true
+	err = applier.Run()
+	return BatchResult{
+		Config:     config,
+		AddedNodes: applier.addedNodes,
+		Err:        err,
+	}
+}
@@ -0,0 +1,129 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// failureBundle collects what the run had resolved before failing, so the whole
+// simulation can be attached to a bug report and replayed elsewhere
+type failureBundle struct {
+	cluster         simulator.ResourceTypes
+	apps            []simulator.AppResource
+	unscheduledPods []simulator.UnscheduledPod
+}
+
+// writeFailureBundle packages the resolved config, the cluster snapshot, the rendered
+// app manifests, the effective scheduler config and the unscheduled-pod diagnostics
+// into the bundle directory
+func (applier *Applier) writeFailureBundle(runErr error) error {
+	dir := applier.bundleOnFailure
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create bundle directory %s: %v ", dir, err)
+	}
+
+	// the resolved simon config as it was read
+	if content, err := ioutil.ReadFile(applier.configPath); err == nil {
+		if err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), content, 0644); err != nil {
+			return err
+		}
+	}
+
+	// the effective scheduler configuration
+	schedulerNote := []byte("the run used the built-in scheduler configuration\n")
+	if applier.schedulerConfig != "" {
+		if content, err := ioutil.ReadFile(applier.schedulerConfig); err == nil {
+			schedulerNote = content
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "scheduler-config.yaml"), schedulerNote, 0644); err != nil {
+		return err
+	}
+
+	// the cluster snapshot the simulation ran against
+	if err := writeResourceTypesYaml(filepath.Join(dir, "cluster.yaml"), applier.bundleSnapshot.cluster); err != nil {
+		return err
+	}
+
+	// the rendered app manifests, one file per app
+	if len(applier.bundleSnapshot.apps) != 0 {
+		appsDir := filepath.Join(dir, "apps")
+		if err := os.MkdirAll(appsDir, 0755); err != nil {
+			return err
+		}
+		for _, app := range applier.bundleSnapshot.apps {
+			if err := writeResourceTypesYaml(filepath.Join(appsDir, app.Name+".yaml"), app.Resource); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the diagnostics: the run error plus the pods the last simulation could not place
+	diagnostics := fmt.Sprintf("run failed: %v\n", runErr)
+	for _, unscheduledPod := range applier.bundleSnapshot.unscheduledPods {
+		diagnostics += fmt.Sprintf("%s/%s: %s\n", unscheduledPod.Pod.Namespace, unscheduledPod.Pod.Name, unscheduledPod.Reason)
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "diagnostics.txt"), []byte(diagnostics), 0644)
+}
+
+// writeResourceTypesYaml writes every object of the resource set as one multi-document
+// YAML file
+func writeResourceTypesYaml(path string, resources simulator.ResourceTypes) error {
+	var objects []interface{}
+	for _, item := range resources.Nodes {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.Pods {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.DaemonSets {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.Deployments {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.StatefulSets {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.ReplicaSets {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.Jobs {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.CronJobs {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.Services {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.PersistentVolumeClaims {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.StorageClasss {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.PodDisruptionBudgets {
+		objects = append(objects, item)
+	}
+	for _, item := range resources.ConfigMaps {
+		objects = append(objects, item)
+	}
+
+	var content []byte
+	for _, object := range objects {
+		b, err := yaml.Marshal(object)
+		if err != nil {
+			return fmt.Errorf("failed to marshal object for %s: %v ", path, err)
+		}
+		content = append(content, []byte("---\n")...)
+		content = append(content, b...)
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
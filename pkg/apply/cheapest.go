@@ -0,0 +1,168 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+	"github.com/alibaba/open-simulator/pkg/utils"
+)
+
+// nodeShape is one candidate node type of the catalog
+type nodeShape struct {
+	Name   string `json:"name"`
+	CPU    string `json:"cpu"`
+	Memory string `json:"memory"`
+	// Resources lists extra allocatable resources of the shape, e.g. nvidia.com/gpu
+	Resources map[string]string `json:"resources,omitempty"`
+	// Cost is the price of one node in arbitrary units, e.g. dollars per hour
+	Cost float64 `json:"cost"`
+}
+
+// nodeShapeCatalog is the file format of the shape catalog
+type nodeShapeCatalog struct {
+	Shapes []nodeShape `json:"shapes"`
+}
+
+// loadNodeShapeCatalog reads and validates the candidate shape catalog
+func loadNodeShapeCatalog(path string) ([]nodeShape, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shape catalog %s: %v ", path, err)
+	}
+	var catalog nodeShapeCatalog
+	if err := yaml.Unmarshal(content, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse shape catalog %s: %v ", path, err)
+	}
+	if len(catalog.Shapes) == 0 {
+		return nil, fmt.Errorf("shape catalog %s lists no shapes ", path)
+	}
+	for _, shape := range catalog.Shapes {
+		if shape.Name == "" {
+			return nil, fmt.Errorf("shape catalog %s contains a shape without a name ", path)
+		}
+		if shape.Cost <= 0 {
+			return nil, fmt.Errorf("shape %s needs a positive cost ", shape.Name)
+		}
+		if _, err := resource.ParseQuantity(shape.CPU); err != nil {
+			return nil, fmt.Errorf("shape %s has an invalid cpu quantity %s: %v ", shape.Name, shape.CPU, err)
+		}
+		if _, err := resource.ParseQuantity(shape.Memory); err != nil {
+			return nil, fmt.Errorf("shape %s has an invalid memory quantity %s: %v ", shape.Name, shape.Memory, err)
+		}
+	}
+	return catalog.Shapes, nil
+}
+
+// shapeNodeTemplate builds the node template one node of the shape corresponds to
+func shapeNodeTemplate(shape nodeShape) (*corev1.Node, error) {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(shape.CPU),
+		corev1.ResourceMemory: resource.MustParse(shape.Memory),
+		corev1.ResourcePods:   *resource.NewQuantity(110, resource.DecimalSI),
+	}
+	for name, quantityStr := range shape.Resources {
+		quantity, err := resource.ParseQuantity(quantityStr)
+		if err != nil {
+			return nil, fmt.Errorf("shape %s has an invalid %s quantity %s: %v ", shape.Name, name, quantityStr, err)
+		}
+		resources[corev1.ResourceName(name)] = quantity
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: shape.Name,
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    resources,
+			Allocatable: resources,
+		},
+	}, nil
+}
+
+// packShapeGreenfield finds the smallest number of nodes of the shape that hosts all
+// selected apps on an otherwise empty cluster, 0 when even the node limit is not enough
+func (applier *Applier) packShapeGreenfield(shape nodeShape, selectedApps []simulator.AppResource) (int, error) {
+	template, err := shapeNodeTemplate(shape)
+	if err != nil {
+		return 0, err
+	}
+	for count := 1; count <= simontype.MaxNumNewNode; count++ {
+		nodes, err := utils.NewFakeNodesWithNaming(template, count, utils.NodeNaming{Prefix: shape.Name}, nil)
+		if err != nil {
+			return 0, err
+		}
+		result, err := simulator.Simulate(simulator.ResourceTypes{Nodes: nodes}, selectedApps,
+			simulator.DisablePTerm(true),
+			simulator.WithKubeConfig(applier.cluster.KubeConfig),
+			simulator.WithNamespaceNodeSelectors(applier.namespaceNodeSelectors),
+			simulator.WithPodOverhead(applier.podOverhead),
+			simulator.WithIgnorePendingPods(applier.ignorePendingPods),
+			simulator.WithIgnorePodsSelector(applier.ignorePods),
+			simulator.WithUtilizationCaps(applier.utilizationCaps),
+			simulator.WithPrioritySort(applier.prioritySort),
+			simulator.WithVGSelectionPolicy(applier.vgSelectionPolicy),
+			simulator.WithAppRequestMultipliers(applier.requestMultipliers))
+		if err != nil {
+			return 0, err
+		}
+		if len(result.UnscheduledPods) == 0 {
+			return count, nil
+		}
+	}
+	return 0, nil
+}
+
+// reportCheapestNodeShape packs the selected apps greenfield onto every catalog shape
+// and recommends the one hosting the whole workload at the lowest total cost
+func (applier *Applier) reportCheapestNodeShape(selectedApps []simulator.AppResource) error {
+	shapes, err := loadNodeShapeCatalog(applier.shapeCatalogFile)
+	if err != nil {
+		return err
+	}
+
+	pterm.FgYellow.Println("Node Shape Costs")
+	shapeTable := pterm.DefaultTable.WithHasHeader()
+	shapeTableData := [][]string{
+		{"Shape", "CPU", "Memory", "Nodes", "Node Cost", "Total Cost"},
+	}
+	var cheapest *nodeShape
+	cheapestCount := 0
+	for i, shape := range shapes {
+		count, err := applier.packShapeGreenfield(shape, selectedApps)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			shapeTableData = append(shapeTableData, []string{
+				shape.Name, shape.CPU, shape.Memory, "-", fmt.Sprintf("%.2f", shape.Cost), "does not fit",
+			})
+			continue
+		}
+		totalCost := float64(count) * shape.Cost
+		shapeTableData = append(shapeTableData, []string{
+			shape.Name, shape.CPU, shape.Memory, strconv.Itoa(count), fmt.Sprintf("%.2f", shape.Cost), fmt.Sprintf("%.2f", totalCost),
+		})
+		if cheapest == nil || totalCost < float64(cheapestCount)*cheapest.Cost {
+			cheapest = &shapes[i]
+			cheapestCount = count
+		}
+	}
+	if err := shapeTable.WithData(shapeTableData).Render(); err != nil {
+		return fmt.Errorf("fail to render node shape table: %s ", err.Error())
+	}
+	pterm.FgYellow.Println()
+
+	if cheapest == nil {
+		return fmt.Errorf("no shape of the catalog can host the workload within %d nodes ", simontype.MaxNumNewNode)
+	}
+	pterm.FgGreen.Printf("cheapest node shape: %s, %d node(s) at a total cost of %.2f\n", cheapest.Name, cheapestCount, float64(cheapestCount)*cheapest.Cost)
+	return nil
+}
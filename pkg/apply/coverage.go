@@ -0,0 +1,89 @@
+package apply
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/utils"
+)
+
+// DaemonSetCoverage summarizes on how many nodes a DaemonSet would actually run. A node
+// counts as intended when the node selector and affinity of the DaemonSet match it, and
+// as covered when the pod would really run there, taints and tolerations included.
+type DaemonSetCoverage struct {
+	DaemonSet string
+	Intended  int
+	Covered   int
+	// Gaps lists intended nodes the DaemonSet does not cover, typically for a missing toleration
+	Gaps []string
+}
+
+// analyzeDaemonSetCoverage checks every DaemonSet against every node and reports the
+// coverage including the nodes that fall through
+func analyzeDaemonSetCoverage(daemonSets []*appsv1.DaemonSet, nodes []*corev1.Node) ([]DaemonSetCoverage, error) {
+	var coverages []DaemonSetCoverage
+	for _, ds := range daemonSets {
+		coverage := DaemonSetCoverage{
+			DaemonSet: fmt.Sprintf("%s/%s", ds.Namespace, ds.Name),
+		}
+		for _, node := range nodes {
+			pod, err := utils.NewDaemonPod(ds, node.Name)
+			if err != nil {
+				return nil, err
+			}
+			// a node is intended when only its taints could keep the pod away
+			untaintedNode := node.DeepCopy()
+			untaintedNode.Spec.Taints = nil
+			intended := utils.NodeShouldRunPod(untaintedNode, pod)
+			if !intended {
+				continue
+			}
+			coverage.Intended++
+			if utils.NodeShouldRunPod(node, pod) {
+				coverage.Covered++
+			} else {
+				coverage.Gaps = append(coverage.Gaps, node.Name)
+			}
+		}
+		coverages = append(coverages, coverage)
+	}
+	return coverages, nil
+}
+
+// reportDaemonSetCoverage prints the per-DaemonSet coverage table and flags the gaps
+func reportDaemonSetCoverage(daemonSets []*appsv1.DaemonSet, nodes []*corev1.Node) error {
+	coverages, err := analyzeDaemonSetCoverage(daemonSets, nodes)
+	if err != nil {
+		return err
+	}
+
+	pterm.FgYellow.Println("DaemonSet Coverage")
+	coverageTable := pterm.DefaultTable.WithHasHeader()
+	coverageTableData := [][]string{
+		{"DaemonSet", "Intended Nodes", "Covered Nodes", "Gap Nodes"},
+	}
+	gapCount := 0
+	for _, coverage := range coverages {
+		coverageTableData = append(coverageTableData, []string{
+			coverage.DaemonSet,
+			strconv.Itoa(coverage.Intended),
+			strconv.Itoa(coverage.Covered),
+			strings.Join(coverage.Gaps, "\n"),
+		})
+		gapCount += len(coverage.Gaps)
+	}
+	if err := coverageTable.WithData(coverageTableData).Render(); err != nil {
+		return fmt.Errorf("fail to render daemonset coverage table: %s ", err.Error())
+	}
+	if gapCount != 0 {
+		pterm.FgRed.Printf("%d node(s) are intended but not covered, check the tolerations of the listed DaemonSets\n", gapCount)
+		return nil
+	}
+	pterm.FgGreen.Println("every DaemonSet covers all of its intended nodes")
+	return nil
+}
@@ -0,0 +1,132 @@
+package apply
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	gpushareutils "github.com/alibaba/open-simulator/pkg/type/open-gpu-share/utils"
+)
+
+// successClause is one "<metric><op><percent>" condition of a success criteria
+// expression. The metrics cpu, memory, vg and gpu compare the cluster-wide occupancy
+// in percent, node-cpu and node-memory compare the most loaded single node.
+type successClause struct {
+	metric string
+	op     string
+	limit  int
+}
+
+// parseSuccessCriteria parses an expression like "cpu<=85 && node-memory<90" into its
+// clauses. All clauses must hold for the simulation to count as a success.
+func parseSuccessCriteria(expr string) ([]successClause, error) {
+	var clauses []successClause
+	for _, part := range strings.Split(expr, "&&") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("empty clause in success criteria %s ", expr)
+		}
+		op := "<"
+		if strings.Contains(part, "<=") {
+			op = "<="
+		}
+		fields := strings.SplitN(part, op, 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid clause %s, expected <metric>%s<percent> ", part, op)
+		}
+		metric := strings.TrimSpace(fields[0])
+		switch metric {
+		case "cpu", "memory", "vg", "gpu", "node-cpu", "node-memory":
+		default:
+			return nil, fmt.Errorf("unknown metric %s in success criteria, supported: cpu, memory, vg, gpu, node-cpu, node-memory ", metric)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid percent in clause %s: %v ", part, err)
+		}
+		if limit < 0 || limit > 100 {
+			return nil, fmt.Errorf("percent in clause %s must be between 0 and 100 ", part)
+		}
+		clauses = append(clauses, successClause{metric: metric, op: op, limit: limit})
+	}
+	return clauses, nil
+}
+
+// evaluateSuccessCriteria checks the scheduled result against every clause and reports
+// the first violated one as the reason
+func evaluateSuccessCriteria(clauses []successClause, nodeStatuses []simulator.NodeStatus) (bool, string, error) {
+	totalAllocatableResource, totalUsedResource, totalVGResource, err := clusterResourceTotals(nodeStatuses)
+	if err != nil {
+		return false, "", err
+	}
+
+	for _, clause := range clauses {
+		var value int
+		switch clause.metric {
+		case "cpu":
+			value = occupancyPercent(totalUsedResource[corev1.ResourceCPU].MilliValue(), totalAllocatableResource[corev1.ResourceCPU].MilliValue())
+		case "memory":
+			value = occupancyPercent(totalUsedResource[corev1.ResourceMemory].Value(), totalAllocatableResource[corev1.ResourceMemory].Value())
+		case "vg":
+			value = occupancyPercent(totalVGResource.Requested, totalVGResource.Capacity)
+		case "gpu":
+			var usedGpuMem, totalGpuMem int64
+			for _, status := range nodeStatuses {
+				totalGpuMem += gpushareutils.GetTotalGpuMemory(status.Node)
+				for _, pod := range status.Pods {
+					usedGpuMem += gpushareutils.GetGpuMemoryFromPodAnnotation(pod)
+				}
+			}
+			value = occupancyPercent(usedGpuMem, totalGpuMem)
+		case "node-cpu", "node-memory":
+			for _, status := range nodeStatuses {
+				var used, allocatable int64
+				if clause.metric == "node-cpu" {
+					allocatable = status.Node.Status.Allocatable.Cpu().MilliValue()
+				} else {
+					allocatable = status.Node.Status.Allocatable.Memory().Value()
+				}
+				for _, pod := range status.Pods {
+					podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+					if clause.metric == "node-cpu" {
+						used += podReqs.Cpu().MilliValue()
+					} else {
+						used += podReqs.Memory().Value()
+					}
+				}
+				if nodeValue := occupancyPercent(used, allocatable); nodeValue > value {
+					value = nodeValue
+				}
+			}
+		}
+		satisfied := value < clause.limit
+		if clause.op == "<=" {
+			satisfied = value <= clause.limit
+		}
+		if !satisfied {
+			return false, fmt.Sprintf("the occupancy rate(%d%%) of %s violates the success criteria clause %s%s%d\n", value, clause.metric, clause.metric, clause.op, clause.limit), nil
+		}
+	}
+	return true, "", nil
+}
+
+// checkSuccess decides whether a fully scheduled result counts as a success, using the
+// configured criteria expression or falling back to the CR and env threshold checks
+func (applier *Applier) checkSuccess(nodeStatuses []simulator.NodeStatus) (bool, string, error) {
+	if len(applier.successCriteria) != 0 {
+		return evaluateSuccessCriteria(applier.successCriteria, nodeStatuses)
+	}
+	return satisfyResourceSetting(nodeStatuses, applier.resourceThresholds)
+}
+
+// occupancyPercent returns used of allocatable in percent, guarding empty pools
+func occupancyPercent(used, allocatable int64) int {
+	if allocatable == 0 {
+		return 0
+	}
+	return int(float64(used) / float64(allocatable) * 100)
+}
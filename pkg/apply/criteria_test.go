@@ -0,0 +1,166 @@
+package apply
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+	gpushareutils "github.com/alibaba/open-simulator/pkg/type/open-gpu-share/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseSuccessCriteria(t *testing.T) {
+	clauses, err := parseSuccessCriteria("cpu<=85 && node-memory<90")
+	if err != nil {
+		t.Fatalf("parseSuccessCriteria() error = %v", err)
+	}
+	if len(clauses) != 2 {
+		t.Fatalf("parseSuccessCriteria() got %d clauses, want 2", len(clauses))
+	}
+	if clauses[0].metric != "cpu" || clauses[0].op != "<=" || clauses[0].limit != 85 {
+		t.Errorf("parseSuccessCriteria() clause = %+v, want cpu<=85", clauses[0])
+	}
+	if clauses[1].metric != "node-memory" || clauses[1].op != "<" || clauses[1].limit != 90 {
+		t.Errorf("parseSuccessCriteria() clause = %+v, want node-memory<90", clauses[1])
+	}
+
+	if _, err := parseSuccessCriteria("disk<=85"); err == nil {
+		t.Errorf("parseSuccessCriteria(disk<=85) expected an error")
+	}
+	if _, err := parseSuccessCriteria("cpu<=200"); err == nil {
+		t.Errorf("parseSuccessCriteria(cpu<=200) expected an error")
+	}
+}
+
+func TestEvaluateSuccessCriteria(t *testing.T) {
+	// one node half full on cpu, the other idle: cluster cpu is 25%, the most loaded
+	// node is at 50%
+	loadedNode := test.MakeFakeNode("node-1", "8", "16Gi")
+	idleNode := test.MakeFakeNode("node-2", "8", "16Gi")
+	nodeStatuses := []simulator.NodeStatus{
+		{
+			Node: loadedNode,
+			Pods: []*corev1.Pod{
+				test.MakeFakePod("busy-pod", "default", "4", "4Gi", test.WithPodNodeName("node-1")),
+			},
+		},
+		{Node: idleNode},
+	}
+
+	ok, _, err := evaluateSuccessCriteria(mustParseCriteria(t, "cpu<=30 && memory<=50 && node-cpu<=50"), nodeStatuses)
+	if err != nil {
+		t.Fatalf("evaluateSuccessCriteria() error = %v", err)
+	}
+	if !ok {
+		t.Errorf("evaluateSuccessCriteria() = false, want the criteria to pass")
+	}
+
+	ok, reason, err := evaluateSuccessCriteria(mustParseCriteria(t, "cpu<=30 && node-cpu<50"), nodeStatuses)
+	if err != nil {
+		t.Fatalf("evaluateSuccessCriteria() error = %v", err)
+	}
+	if ok {
+		t.Errorf("evaluateSuccessCriteria() = true, want node-cpu<50 to fail at 50%%")
+	}
+	if !strings.Contains(reason, "node-cpu") {
+		t.Errorf("evaluateSuccessCriteria() reason = %q, want it to name node-cpu", reason)
+	}
+
+	ok, reason, err = evaluateSuccessCriteria(mustParseCriteria(t, "cpu<25"), nodeStatuses)
+	if err != nil {
+		t.Fatalf("evaluateSuccessCriteria() error = %v", err)
+	}
+	if ok {
+		t.Errorf("evaluateSuccessCriteria() = true, want cpu<25 to fail at 25%%")
+	}
+	if !strings.Contains(reason, "25%") {
+		t.Errorf("evaluateSuccessCriteria() reason = %q, want the measured rate", reason)
+	}
+}
+
+func TestSatisfyResourceSettingThresholds(t *testing.T) {
+	// one node half full on cpu
+	nodeStatuses := []simulator.NodeStatus{
+		{
+			Node: test.MakeFakeNode("node-1", "8", "16Gi"),
+			Pods: []*corev1.Pod{
+				test.MakeFakePod("busy-pod", "default", "4", "4Gi", test.WithPodNodeName("node-1")),
+			},
+		},
+	}
+
+	ok, reason, err := satisfyResourceSetting(nodeStatuses, v1alpha1.ResourceThresholds{CPU: 40})
+	if err != nil {
+		t.Fatalf("satisfyResourceSetting() error = %v", err)
+	}
+	if ok {
+		t.Errorf("satisfyResourceSetting() = true, want the CR cpu threshold of 40%% to fail at 50%%")
+	}
+	if !strings.Contains(reason, "cpu") {
+		t.Errorf("satisfyResourceSetting() reason = %q, want it to name cpu", reason)
+	}
+
+	// the env var only applies when the CR leaves the threshold unset
+	t.Setenv(simontype.EnvMaxCPU, "40")
+	ok, _, err = satisfyResourceSetting(nodeStatuses, v1alpha1.ResourceThresholds{CPU: 60})
+	if err != nil {
+		t.Fatalf("satisfyResourceSetting() error = %v", err)
+	}
+	if !ok {
+		t.Errorf("satisfyResourceSetting() = false, want the CR threshold to win over the env var")
+	}
+
+	ok, _, err = satisfyResourceSetting(nodeStatuses, v1alpha1.ResourceThresholds{})
+	if err != nil {
+		t.Fatalf("satisfyResourceSetting() error = %v", err)
+	}
+	if ok {
+		t.Errorf("satisfyResourceSetting() = true, want the env var to apply when the CR is unset")
+	}
+}
+
+func TestSatisfyResourceSettingGpuThreshold(t *testing.T) {
+	// one node with 16Gi of gpu memory, half of it requested via the pod annotation
+	gpuNode := test.MakeFakeNode("gpu-node", "8", "16Gi")
+	gpuNode.Status.Capacity[gpushareutils.ResourceName] = resource.MustParse("16Gi")
+	gpuPod := test.MakeFakePod("gpu-pod", "default", "1", "1Gi", test.WithPodNodeName("gpu-node"))
+	gpuPod.Annotations = map[string]string{gpushareutils.ResourceName: "8Gi"}
+	nodeStatuses := []simulator.NodeStatus{
+		{
+			Node: gpuNode,
+			Pods: []*corev1.Pod{gpuPod},
+		},
+	}
+
+	ok, reason, err := satisfyResourceSetting(nodeStatuses, v1alpha1.ResourceThresholds{GPU: 40})
+	if err != nil {
+		t.Fatalf("satisfyResourceSetting() error = %v", err)
+	}
+	if ok {
+		t.Errorf("satisfyResourceSetting() = true, want the gpu threshold of 40%% to fail at 50%%")
+	}
+	if !strings.Contains(reason, "gpu memory") {
+		t.Errorf("satisfyResourceSetting() reason = %q, want it to name gpu memory", reason)
+	}
+
+	ok, _, err = satisfyResourceSetting(nodeStatuses, v1alpha1.ResourceThresholds{GPU: 60})
+	if err != nil {
+		t.Fatalf("satisfyResourceSetting() error = %v", err)
+	}
+	if !ok {
+		t.Errorf("satisfyResourceSetting() = false, want 50%% gpu occupancy to pass a 60%% threshold")
+	}
+}
+
+func mustParseCriteria(t *testing.T, expr string) []successClause {
+	t.Helper()
+	clauses, err := parseSuccessCriteria(expr)
+	if err != nil {
+		t.Fatalf("parseSuccessCriteria(%s) error = %v", expr, err)
+	}
+	return clauses
+}
@@ -0,0 +1,227 @@
+package apply
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+	gpushareutils "github.com/alibaba/open-simulator/pkg/type/open-gpu-share/utils"
+	"github.com/alibaba/open-simulator/pkg/utils"
+)
+
+// nodeInfoCSV builds the Node Info table as CSV records, with the request fractions as
+// plain numbers in their own columns so spreadsheets can chart them directly
+func nodeInfoCSV(nodeStatuses []simulator.NodeStatus, extendedResources []string) [][]string {
+	header := []string{
+		"Node",
+		"CPU Allocatable",
+		"CPU Requests",
+		"CPU Request Percent",
+		"CPU Free",
+		"Memory Allocatable",
+		"Memory Requests",
+		"Memory Request Percent",
+		"Memory Free",
+	}
+	if containGpu(extendedResources) {
+		header = append(header, []string{
+			"GPU Mem Allocatable",
+			"GPU Mem Requests",
+			"GPU Mem Request Percent",
+			"GPU Mem Free",
+		}...)
+	}
+	header = append(header, []string{
+		"Pod Count",
+		"New Node",
+	}...)
+	records := [][]string{header}
+
+	for _, status := range nodeStatuses {
+		node := status.Node
+		allocatable := node.Status.Allocatable
+		cpuReq := resource.NewMilliQuantity(0, resource.DecimalSI)
+		memoryReq := resource.NewQuantity(0, resource.BinarySI)
+		for _, pod := range status.Pods {
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			cpuReq.Add(*podReqs.Cpu())
+			memoryReq.Add(*podReqs.Memory())
+		}
+		cpuFree := allocatable.Cpu().DeepCopy()
+		cpuFree.Sub(*cpuReq)
+		memoryFree := allocatable.Memory().DeepCopy()
+		memoryFree.Sub(*memoryReq)
+		newNode := "false"
+		if _, exist := node.Labels[simontype.LabelNewNode]; exist {
+			newNode = "true"
+		}
+
+		record := []string{
+			node.Name,
+			allocatable.Cpu().String(),
+			cpuReq.String(),
+			strconv.Itoa(occupancyPercent(cpuReq.MilliValue(), allocatable.Cpu().MilliValue())),
+			cpuFree.String(),
+			allocatable.Memory().String(),
+			memoryReq.String(),
+			strconv.Itoa(occupancyPercent(memoryReq.Value(), allocatable.Memory().Value())),
+			memoryFree.String(),
+		}
+		if containGpu(extendedResources) {
+			gpuMemAllocatable := allocatable.Name(gpushareutils.ResourceName, resource.BinarySI)
+			gpuMemReq := resource.NewQuantity(0, resource.BinarySI)
+			for _, pod := range status.Pods {
+				gpuMem, gpuNum := gpushareutils.GetGpuMemoryAndCountFromPodAnnotation(pod)
+				gpuMemReq.Add(*resource.NewQuantity(int64(gpuMem*gpuNum), resource.BinarySI))
+			}
+			gpuMemFree := gpuMemAllocatable.DeepCopy()
+			gpuMemFree.Sub(*gpuMemReq)
+			record = append(record, []string{
+				gpuMemAllocatable.String(),
+				gpuMemReq.String(),
+				strconv.Itoa(occupancyPercent(gpuMemReq.Value(), gpuMemAllocatable.Value())),
+				gpuMemFree.String(),
+			}...)
+		}
+		record = append(record, []string{
+			strconv.Itoa(len(status.Pods)),
+			newNode,
+		}...)
+		records = append(records, record)
+	}
+	return records
+}
+
+// podInfoCSV builds the Pod Info table as CSV records, one row per placed pod with the
+// host node in the first column instead of one block per node
+func podInfoCSV(nodeStatuses []simulator.NodeStatus, extendedResources []string) [][]string {
+	header := []string{
+		"Node",
+		"Pod",
+		"CPU Requests",
+		"CPU Request Percent",
+		"Memory Requests",
+		"Memory Request Percent",
+		"QoS",
+	}
+	if containLocalStorage(extendedResources) {
+		header = append(header, "Volume Request")
+	}
+	if containGpu(extendedResources) {
+		header = append(header, []string{
+			"GPU Mem Requests",
+			"GPU Mem Request Percent",
+		}...)
+	}
+	header = append(header, "APP Name")
+	records := [][]string{header}
+
+	for _, status := range nodeStatuses {
+		node := status.Node
+		allocatable := node.Status.Allocatable
+		for _, pod := range status.Pods {
+			req, _ := resourcehelper.PodRequestsAndLimits(pod)
+			cpuReq, memoryReq := req[corev1.ResourceCPU], req[corev1.ResourceMemory]
+
+			record := []string{
+				node.Name,
+				fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+				cpuReq.String(),
+				strconv.Itoa(occupancyPercent(cpuReq.MilliValue(), allocatable.Cpu().MilliValue())),
+				memoryReq.String(),
+				strconv.Itoa(occupancyPercent(memoryReq.Value(), allocatable.Memory().Value())),
+				string(utils.GetPodQoSClass(pod)),
+			}
+			if containLocalStorage(extendedResources) {
+				podVolumeStr := ""
+				if volumes := utils.GetPodStorage(pod); volumes != nil {
+					for i, volume := range volumes.Volumes {
+						volumeQuantity := resource.NewQuantity(volume.Size, resource.BinarySI)
+						podVolumeStr = fmt.Sprintf("%s<%d> %s: %s", podVolumeStr, i, volume.Kind, volumeQuantity.String())
+					}
+				}
+				record = append(record, podVolumeStr)
+			}
+			if containGpu(extendedResources) {
+				gpuMem, gpuNum := gpushareutils.GetGpuMemoryAndCountFromPodAnnotation(pod)
+				gpuMemReq := resource.NewQuantity(int64(gpuMem*gpuNum), resource.BinarySI)
+				gpuMemAllocatable := allocatable.Name(gpushareutils.ResourceName, resource.BinarySI)
+				record = append(record, []string{
+					gpuMemReq.String(),
+					strconv.Itoa(occupancyPercent(gpuMemReq.Value(), gpuMemAllocatable.Value())),
+				}...)
+			}
+			record = append(record, pod.Labels[simontype.LabelAppName])
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// writeCSVOutput emits the Node Info and Pod Info tables as CSV. A directory output path
+// gets one file per table; otherwise both tables go to the output file or stdout as
+// blocks separated by a comment line.
+func (applier *Applier) writeCSVOutput(result *simulator.SimulateResult) error {
+	nodeStatuses, _ := splitMisconfiguredNodes(result.NodeStatus)
+	nodeRecords := nodeInfoCSV(nodeStatuses, applier.extendedResources)
+	podRecords := podInfoCSV(nodeStatuses, applier.extendedResources)
+
+	if applier.outputPath != "" {
+		if info, err := os.Stat(applier.outputPath); err == nil && info.IsDir() {
+			if err := writeCSVFile(filepath.Join(applier.outputPath, "node-info.csv"), nodeRecords); err != nil {
+				return err
+			}
+			return writeCSVFile(filepath.Join(applier.outputPath, "pod-info.csv"), podRecords)
+		}
+		target, err := os.Create(applier.outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to open file %s: %s ", applier.outputPath, err.Error())
+		}
+		defer target.Close()
+		return writeCSVBlocks(target, nodeRecords, podRecords)
+	}
+	var target io.Writer = os.Stdout
+	if applier.outputFile != nil {
+		target = applier.outputFile
+	}
+	return writeCSVBlocks(target, nodeRecords, podRecords)
+}
+
+func writeCSVFile(path string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %s ", path, err.Error())
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write csv file %s: %v ", path, err)
+	}
+	return nil
+}
+
+func writeCSVBlocks(target io.Writer, nodeRecords, podRecords [][]string) error {
+	if _, err := fmt.Fprintln(target, "# Node Info"); err != nil {
+		return fmt.Errorf("failed to write csv output: %v ", err)
+	}
+	writer := csv.NewWriter(target)
+	if err := writer.WriteAll(nodeRecords); err != nil {
+		return fmt.Errorf("failed to write csv output: %v ", err)
+	}
+	if _, err := fmt.Fprintln(target, "# Pod Info"); err != nil {
+		return fmt.Errorf("failed to write csv output: %v ", err)
+	}
+	if err := writer.WriteAll(podRecords); err != nil {
+		return fmt.Errorf("failed to write csv output: %v ", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package apply
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+func TestCSVRecords(t *testing.T) {
+	node := test.MakeFakeNode("node-1", "8", "16Gi")
+	pod := test.MakeFakePod("pod-1", "default", "2", "4Gi", test.WithPodNodeName("node-1"))
+	pod.Labels = map[string]string{simontype.LabelAppName: "demo-app"}
+	nodeStatuses := []simulator.NodeStatus{
+		{Node: node, Pods: []*corev1.Pod{pod}},
+	}
+
+	nodeRecords := nodeInfoCSV(nodeStatuses, nil)
+	if len(nodeRecords) != 2 {
+		t.Fatalf("expect a header and 1 node record, got %d", len(nodeRecords))
+	}
+	if len(nodeRecords[0]) != len(nodeRecords[1]) {
+		t.Fatalf("node record has %d columns, header has %d", len(nodeRecords[1]), len(nodeRecords[0]))
+	}
+	record := nodeRecords[1]
+	if record[0] != "node-1" || record[2] != "2" || record[3] != "25" || record[7] != "25" {
+		t.Fatalf("unexpected node record: %v", record)
+	}
+
+	podRecords := podInfoCSV(nodeStatuses, nil)
+	if len(podRecords) != 2 {
+		t.Fatalf("expect a header and 1 pod record, got %d", len(podRecords))
+	}
+	if len(podRecords[0]) != len(podRecords[1]) {
+		t.Fatalf("pod record has %d columns, header has %d", len(podRecords[1]), len(podRecords[0]))
+	}
+	record = podRecords[1]
+	if record[0] != "node-1" || record[1] != "default/pod-1" || record[3] != "25" || record[len(record)-1] != "demo-app" {
+		t.Fatalf("unexpected pod record: %v", record)
+	}
+
+	// the gpu columns only show up when the extended resource is requested
+	gpuRecords := nodeInfoCSV(nodeStatuses, []string{"gpu"})
+	if len(gpuRecords[0]) != len(nodeRecords[0])+4 {
+		t.Fatalf("expect 4 extra gpu columns, got header %v", gpuRecords[0])
+	}
+}
@@ -0,0 +1,48 @@
+package apply
+
+import (
+	"fmt"
+
+	"github.com/pterm/pterm"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// reportDeschedule runs the LowNodeUtilization-style rebalancing pass on the finished
+// placement and prints the moves alongside the per-node utilization before and after,
+// showing how much headroom descheduling would claw back
+func reportDeschedule(result *simulator.SimulateResult) error {
+	descheduleResult, err := simulator.Deschedule(result, simulator.DescheduleOptions{})
+	if err != nil {
+		return err
+	}
+
+	pterm.FgYellow.Println("Deschedule")
+	if len(descheduleResult.Moves) == 0 {
+		pterm.FgGreen.Println("no node is overloaded enough for descheduling to move a pod")
+		pterm.FgYellow.Println()
+		return nil
+	}
+	for _, move := range descheduleResult.Moves {
+		pterm.FgLightWhite.Printf("move pod %s from %s to %s\n", move.Pod, move.From, move.To)
+	}
+
+	after := make(map[string]int, len(descheduleResult.Result.NodeStatus))
+	for _, status := range descheduleResult.Result.NodeStatus {
+		after[status.Node.Name] = simulator.NodeUtilizationPercent(status)
+	}
+	utilizationTableData := [][]string{{"Node", "Utilization Before", "Utilization After"}}
+	for _, status := range result.NodeStatus {
+		utilizationTableData = append(utilizationTableData, []string{
+			status.Node.Name,
+			fmt.Sprintf("%d%%", simulator.NodeUtilizationPercent(status)),
+			fmt.Sprintf("%d%%", after[status.Node.Name]),
+		})
+	}
+	utilizationTable := pterm.DefaultTable.WithHasHeader()
+	if err := utilizationTable.WithData(utilizationTableData).Render(); err != nil {
+		return fmt.Errorf("fail to render deschedule table: %s ", err.Error())
+	}
+	pterm.FgYellow.Println()
+	return nil
+}
@@ -0,0 +1,57 @@
+package apply
+
+import (
+	"fmt"
+
+	"github.com/pterm/pterm"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+// reportDescribe prints one kubectl-describe-like text block per node instead of the
+// tables, which is familiar to operators and easier to grep
+func reportDescribe(nodeStatuses []simulator.NodeStatus) {
+	for _, status := range nodeStatuses {
+		node := status.Node
+		allocatableCPU := node.Status.Allocatable.Cpu()
+		allocatableMemory := node.Status.Allocatable.Memory()
+
+		pterm.Printf("Name:           %s\n", node.Name)
+		if _, exist := node.Labels[simontype.LabelNewNode]; exist {
+			pterm.Printf("Origin:         added by the simulation\n")
+		}
+		pterm.Printf("Allocatable:\n")
+		pterm.Printf("  cpu:          %s\n", allocatableCPU.String())
+		pterm.Printf("  memory:       %s\n", allocatableMemory.String())
+
+		pterm.Printf("Non-terminated Pods: (%d in total)\n", len(status.Pods))
+		pterm.Printf("  %-24s %-48s %-16s %s\n", "Namespace", "Name", "CPU Requests", "Memory Requests")
+		var totalCPU, totalMemory int64
+		for _, pod := range status.Pods {
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			cpu := podReqs.Cpu()
+			memory := podReqs.Memory()
+			totalCPU += cpu.MilliValue()
+			totalMemory += memory.Value()
+			pterm.Printf("  %-24s %-48s %-16s %s\n", pod.Namespace, pod.Name,
+				fmt.Sprintf("%s (%d%%)", cpu.String(), fraction(cpu.MilliValue(), allocatableCPU.MilliValue())),
+				fmt.Sprintf("%s (%d%%)", memory.String(), fraction(memory.Value(), allocatableMemory.Value())))
+		}
+
+		pterm.Printf("Allocated resources:\n")
+		pterm.Printf("  %-16s %s\n", "Resource", "Requests")
+		pterm.Printf("  %-16s %dm (%d%%)\n", "cpu", totalCPU, fraction(totalCPU, allocatableCPU.MilliValue()))
+		pterm.Printf("  %-16s %dMi (%d%%)\n", "memory", totalMemory/(1<<20), fraction(totalMemory, allocatableMemory.Value()))
+		pterm.Println()
+	}
+}
+
+// fraction returns part of whole in percent, guarding against empty allocatable
+func fraction(part, whole int64) int64 {
+	if whole == 0 {
+		return 0
+	}
+	return part * 100 / whole
+}
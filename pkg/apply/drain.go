@@ -0,0 +1,71 @@
+package apply
+
+import (
+	"fmt"
+
+	"github.com/pterm/pterm"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// reportDrain simulates draining one node of the cluster snapshot and reports the
+// transition against the steady state, so the transient spike graceful termination
+// causes while old and new pod coexist is visible before a real drain
+func (applier *Applier) reportDrain(cluster simulator.ResourceTypes, disablePTerm bool) error {
+	drainResult, err := simulator.SimulateDrain(cluster, applier.drainNode, applier.terminationOverlap, simulator.DisablePTerm(disablePTerm), simulator.WithKubeConfig(applier.cluster.KubeConfig), simulator.WithNamespaceNodeSelectors(applier.namespaceNodeSelectors), simulator.WithPodOverhead(applier.podOverhead), simulator.WithIgnorePendingPods(applier.ignorePendingPods), simulator.WithIgnorePodsSelector(applier.ignorePods), simulator.WithScoreDump(applier.scoreDumpFile), simulator.WithDebugDump(applier.debugDumpFile), simulator.WithUtilizationCaps(applier.utilizationCaps), simulator.WithPrioritySort(applier.prioritySort), simulator.WithPodOrder(applier.podOrder), simulator.WithVGSelectionPolicy(applier.vgSelectionPolicy), simulator.WithScoringStrategy(applier.scoringStrategy), simulator.WithVerboseFailures(applier.verboseFailures))
+	if err != nil {
+		return err
+	}
+
+	pterm.FgYellow.Printf("Drain Simulation of node %s\n", applier.drainNode)
+	if applier.terminationOverlap {
+		pterm.FgLightWhite.Println("termination overlap on: drained pods keep their capacity while the replacements are placed")
+	} else {
+		pterm.FgLightWhite.Println("termination overlap off: drained pods release their capacity before the replacements are placed")
+	}
+	pterm.FgLightWhite.Printf("peak nodes during the transition: %d\n", drainResult.PeakNodes)
+	pterm.FgLightWhite.Printf("steady-state nodes after the drain: %d\n", drainResult.SteadyNodes)
+	pterm.FgYellow.Println()
+
+	if len(drainResult.Result.UnscheduledPods) != 0 {
+		for _, unscheduledPod := range drainResult.Result.UnscheduledPods {
+			pterm.FgRed.Printf("pod %s/%s can not be rescheduled: %s\n", unscheduledPod.Pod.Namespace, unscheduledPod.Pod.Name, unscheduledPod.Reason)
+		}
+		if applier.verboseFailures {
+			reportVerboseFailures(drainResult.Result.UnscheduledPods)
+		}
+		return fmt.Errorf("%d drained pod(s) can not be rescheduled on the remaining nodes ", len(drainResult.Result.UnscheduledPods))
+	}
+	pterm.FgGreen.Printf("all pods of node %s can be rescheduled\n", applier.drainNode)
+	return nil
+}
+
+// reportRemoveNode simulates removing one node from the cluster snapshot and reports
+// both whether its pods fit on the remaining nodes and which disruption budgets the
+// simultaneous eviction would break
+func (applier *Applier) reportRemoveNode(cluster simulator.ResourceTypes, pdbs []*policyv1beta1.PodDisruptionBudget, disablePTerm bool) error {
+	removeResult, err := simulator.SimulateRemoveNode(cluster, applier.removeNode, pdbs, simulator.DisablePTerm(disablePTerm), simulator.WithKubeConfig(applier.cluster.KubeConfig), simulator.WithNamespaceNodeSelectors(applier.namespaceNodeSelectors), simulator.WithPodOverhead(applier.podOverhead), simulator.WithIgnorePendingPods(applier.ignorePendingPods), simulator.WithIgnorePodsSelector(applier.ignorePods), simulator.WithScoreDump(applier.scoreDumpFile), simulator.WithDebugDump(applier.debugDumpFile), simulator.WithUtilizationCaps(applier.utilizationCaps), simulator.WithPrioritySort(applier.prioritySort), simulator.WithPodOrder(applier.podOrder), simulator.WithVGSelectionPolicy(applier.vgSelectionPolicy), simulator.WithScoringStrategy(applier.scoringStrategy), simulator.WithVerboseFailures(applier.verboseFailures))
+	if err != nil {
+		return err
+	}
+
+	pterm.FgYellow.Printf("Remove Simulation of node %s\n", applier.removeNode)
+	for _, violation := range removeResult.PDBViolations {
+		pterm.FgRed.Printf("pdb %s would be violated: %s\n", violation.PDB, violation.Message)
+	}
+	if len(removeResult.Result.UnscheduledPods) != 0 {
+		for _, unscheduledPod := range removeResult.Result.UnscheduledPods {
+			pterm.FgRed.Printf("pod %s/%s can not be rescheduled: %s\n", unscheduledPod.Pod.Namespace, unscheduledPod.Pod.Name, unscheduledPod.Reason)
+		}
+		if applier.verboseFailures {
+			reportVerboseFailures(removeResult.Result.UnscheduledPods)
+		}
+		return fmt.Errorf("%d evicted pod(s) can not be rescheduled on the remaining nodes ", len(removeResult.Result.UnscheduledPods))
+	}
+	if len(removeResult.PDBViolations) != 0 {
+		return fmt.Errorf("removing node %s would violate %d pod disruption budget(s) ", applier.removeNode, len(removeResult.PDBViolations))
+	}
+	pterm.FgGreen.Printf("node %s can be removed, all of its pods can be rescheduled\n", applier.removeNode)
+	return nil
+}
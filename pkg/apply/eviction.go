@@ -0,0 +1,129 @@
+package apply
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// evictionThreshold is one kubelet eviction signal with its threshold, either an
+// absolute quantity or a percentage of node capacity
+type evictionThreshold struct {
+	signal       string
+	resourceName corev1.ResourceName
+	quantity     resource.Quantity
+	percent      int64
+	isPercent    bool
+}
+
+// evictionSignalResources maps the supported kubelet eviction signals to the node
+// resource they watch
+var evictionSignalResources = map[string]corev1.ResourceName{
+	"memory.available": corev1.ResourceMemory,
+	"nodefs.available": corev1.ResourceEphemeralStorage,
+}
+
+// ParseEvictionThresholds parses kubelet-style eviction threshold expressions like
+// memory.available<500Mi or nodefs.available<10%
+func ParseEvictionThresholds(thresholds []string) ([]evictionThreshold, error) {
+	var parsed []evictionThreshold
+	for _, item := range thresholds {
+		fields := strings.SplitN(item, "<", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid eviction threshold %s, expected <signal><<quantity or percent> ", item)
+		}
+		signal := strings.TrimSpace(fields[0])
+		resourceName, exist := evictionSignalResources[signal]
+		if !exist {
+			return nil, fmt.Errorf("unknown eviction signal %s, supported: memory.available, nodefs.available ", signal)
+		}
+		threshold := evictionThreshold{signal: signal, resourceName: resourceName}
+		value := strings.TrimSpace(fields[1])
+		if strings.HasSuffix(value, "%") {
+			percentQuantity, err := resource.ParseQuantity(strings.TrimSuffix(value, "%"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid percent in eviction threshold %s: %v ", item, err)
+			}
+			threshold.percent = percentQuantity.Value()
+			threshold.isPercent = true
+			if threshold.percent < 0 || threshold.percent > 100 {
+				return nil, fmt.Errorf("percent in eviction threshold %s must be between 0 and 100 ", item)
+			}
+		} else {
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quantity in eviction threshold %s: %v ", item, err)
+			}
+			threshold.quantity = quantity
+		}
+		parsed = append(parsed, threshold)
+	}
+	return parsed, nil
+}
+
+// evictionRisk flags one node whose free resource sits below an eviction threshold
+type evictionRisk struct {
+	node      string
+	signal    string
+	available string
+	threshold string
+}
+
+// evictionRisks lists the nodes whose requests leave less of a resource available than
+// the eviction threshold allows: the kubelet on such a node would start evicting before
+// the allocatable capacity is exhausted
+func evictionRisks(nodeStatuses []simulator.NodeStatus, thresholds []evictionThreshold) []evictionRisk {
+	var risks []evictionRisk
+	for _, status := range nodeStatuses {
+		node := status.Node
+		for _, threshold := range thresholds {
+			capacity, exist := node.Status.Capacity[threshold.resourceName]
+			if !exist || capacity.IsZero() {
+				continue
+			}
+			var requested int64
+			for _, pod := range status.Pods {
+				podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+				quantity := podReqs[threshold.resourceName]
+				requested += quantity.Value()
+			}
+			available := capacity.Value() - requested
+			thresholdValue := threshold.quantity.Value()
+			thresholdDesc := threshold.quantity.String()
+			if threshold.isPercent {
+				thresholdValue = capacity.Value() * threshold.percent / 100
+				thresholdDesc = fmt.Sprintf("%d%%", threshold.percent)
+			}
+			if available < thresholdValue {
+				risks = append(risks, evictionRisk{
+					node:      node.Name,
+					signal:    threshold.signal,
+					available: resource.NewQuantity(available, resource.BinarySI).String(),
+					threshold: thresholdDesc,
+				})
+			}
+		}
+	}
+	return risks
+}
+
+// reportEvictionRisk prints the nodes the configured eviction thresholds flag; a node
+// can look fine on allocatable utilization and still be listed here
+func reportEvictionRisk(nodeStatuses []simulator.NodeStatus, thresholds []evictionThreshold) {
+	risks := evictionRisks(nodeStatuses, thresholds)
+	if len(risks) == 0 {
+		return
+	}
+	pterm.FgYellow.Println("Eviction Risk")
+	for _, risk := range risks {
+		pterm.FgLightWhite.Printf("node %s: %s would drop to %s, below the eviction threshold %s\n",
+			risk.node, risk.signal, risk.available, risk.threshold)
+	}
+	pterm.FgYellow.Println()
+}
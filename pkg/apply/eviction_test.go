@@ -0,0 +1,53 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestEvictionRisks(t *testing.T) {
+	// the pod leaves 1Gi of the node's 16Gi memory free, which passes allocatable
+	// checks but sits below a 10% eviction threshold
+	nodeStatuses := []simulator.NodeStatus{
+		{
+			Node: test.MakeFakeNode("node-1", "8", "16Gi"),
+			Pods: []*corev1.Pod{
+				test.MakeFakePod("hungry-pod", "default", "1", "15Gi", test.WithPodNodeName("node-1")),
+			},
+		},
+		{
+			Node: test.MakeFakeNode("node-2", "8", "16Gi"),
+		},
+	}
+
+	thresholds, err := ParseEvictionThresholds([]string{"memory.available<10%"})
+	if err != nil {
+		t.Fatalf("ParseEvictionThresholds() error = %v", err)
+	}
+	risks := evictionRisks(nodeStatuses, thresholds)
+	if len(risks) != 1 {
+		t.Fatalf("evictionRisks() flagged %d node(s), want 1", len(risks))
+	}
+	if risks[0].node != "node-1" || risks[0].signal != "memory.available" {
+		t.Errorf("evictionRisks() = %+v, want node-1 flagged on memory.available", risks[0])
+	}
+
+	// an absolute threshold below the remaining memory flags nothing
+	thresholds, err = ParseEvictionThresholds([]string{"memory.available<500Mi"})
+	if err != nil {
+		t.Fatalf("ParseEvictionThresholds() error = %v", err)
+	}
+	if risks := evictionRisks(nodeStatuses, thresholds); len(risks) != 0 {
+		t.Errorf("evictionRisks() flagged %d node(s), want 0", len(risks))
+	}
+
+	if _, err := ParseEvictionThresholds([]string{"imagefs.available<10%"}); err == nil {
+		t.Errorf("ParseEvictionThresholds(imagefs.available<10%%) expected an error")
+	}
+	if _, err := ParseEvictionThresholds([]string{"memory.available>10%"}); err == nil {
+		t.Errorf("ParseEvictionThresholds(memory.available>10%%) expected an error")
+	}
+}
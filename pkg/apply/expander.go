@@ -0,0 +1,139 @@
+package apply
+
+import (
+	"fmt"
+	"math/rand"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+)
+
+// Expander strategies mirroring the cluster-autoscaler option of the same name, so the
+// simulated scale-out grows the same node template a real autoscaler would pick.
+const (
+	ExpanderRandom     = "random"
+	ExpanderMostPods   = "most-pods"
+	ExpanderLeastWaste = "least-waste"
+	// ExpanderBestFit is not an autoscaler strategy: it picks the template that can host
+	// the most of the pending pods on an empty node, and is the default once several
+	// templates are parsed, so mixed instance types work without configuration
+	ExpanderBestFit = "best-fit"
+)
+
+// ValidateExpander rejects strategy names the simulator does not implement
+func ValidateExpander(expander string) error {
+	switch expander {
+	case "", ExpanderRandom, ExpanderMostPods, ExpanderLeastWaste, ExpanderBestFit:
+		return nil
+	}
+	return fmt.Errorf("unknown expander %s, supported: %s, %s, %s, %s ", expander, ExpanderRandom, ExpanderMostPods, ExpanderLeastWaste, ExpanderBestFit)
+}
+
+// nodeTemplatePool names the pool a node template belongs to: its pool label value,
+// falling back to the template name for unlabeled templates
+func nodeTemplatePool(template *corev1.Node, poolLabel string) string {
+	if poolLabel == "" {
+		poolLabel = corev1.LabelInstanceTypeStable
+	}
+	if pool := template.Labels[poolLabel]; pool != "" {
+		return pool
+	}
+	return template.Name
+}
+
+// filterScalablePoolTemplates keeps only the node templates from the allowed pools, so
+// the scaling loop respects which pools the operator may actually grow
+func filterScalablePoolTemplates(templates []*corev1.Node, poolLabel string, scalablePools []string) []*corev1.Node {
+	allowed := make(map[string]struct{}, len(scalablePools))
+	for _, pool := range scalablePools {
+		allowed[pool] = struct{}{}
+	}
+	var filtered []*corev1.Node
+	for _, template := range templates {
+		if _, ok := allowed[nodeTemplatePool(template, poolLabel)]; ok {
+			filtered = append(filtered, template)
+		}
+	}
+	return filtered
+}
+
+// packOntoTemplate greedily packs the pending pods onto one empty node of the template
+// and reports how many fit and which share of the node they would fill, approximating
+// how the cluster-autoscaler evaluates a node group
+func packOntoTemplate(template *corev1.Node, pendingPods []*corev1.Pod) (fit int, usedFraction float64) {
+	allocatableCPU := template.Status.Allocatable.Cpu().MilliValue()
+	allocatableMemory := template.Status.Allocatable.Memory().Value()
+	freeCPU, freeMemory := allocatableCPU, allocatableMemory
+	for _, pod := range pendingPods {
+		podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+		cpu := podReqs.Cpu().MilliValue()
+		memory := podReqs.Memory().Value()
+		if cpu <= freeCPU && memory <= freeMemory {
+			freeCPU -= cpu
+			freeMemory -= memory
+			fit++
+		}
+	}
+	if allocatableCPU == 0 || allocatableMemory == 0 {
+		return fit, 0
+	}
+	cpuFraction := float64(allocatableCPU-freeCPU) / float64(allocatableCPU)
+	memoryFraction := float64(allocatableMemory-freeMemory) / float64(allocatableMemory)
+	return fit, (cpuFraction + memoryFraction) / 2
+}
+
+// countHostablePods counts the pending pods whose requests an empty node of the template
+// could satisfy on its own, judging templates by what they unblock rather than by how
+// they pack
+func countHostablePods(template *corev1.Node, pendingPods []*corev1.Pod) int {
+	hostable := 0
+	for _, pod := range pendingPods {
+		podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+		if podReqs.Cpu().MilliValue() <= template.Status.Allocatable.Cpu().MilliValue() &&
+			podReqs.Memory().Value() <= template.Status.Allocatable.Memory().Value() {
+			hostable++
+		}
+	}
+	return hostable
+}
+
+// chooseNodeTemplate picks the node template the configured expander would grow for the
+// given pending pods and explains the decision for the report. Ties keep the first
+// template so repeated runs stay reproducible.
+func chooseNodeTemplate(expander string, templates []*corev1.Node, pendingPods []*corev1.Pod) (*corev1.Node, string, error) {
+	if len(templates) == 0 {
+		return nil, "", fmt.Errorf("no node template to expand ")
+	}
+	switch expander {
+	case ExpanderRandom:
+		template := templates[rand.Intn(len(templates))]
+		return template, fmt.Sprintf("expander %s chose node template %s", expander, template.Name), nil
+	case ExpanderMostPods:
+		best, bestFit := templates[0], -1
+		for _, template := range templates {
+			if fit, _ := packOntoTemplate(template, pendingPods); fit > bestFit {
+				best, bestFit = template, fit
+			}
+		}
+		return best, fmt.Sprintf("expander %s chose node template %s: fits %d pending pod(s)", expander, best.Name, bestFit), nil
+	case ExpanderLeastWaste:
+		best, bestWaste := templates[0], 2.0
+		for _, template := range templates {
+			fit, usedFraction := packOntoTemplate(template, pendingPods)
+			waste := 1 - usedFraction
+			if fit != 0 && waste < bestWaste {
+				best, bestWaste = template, waste
+			}
+		}
+		return best, fmt.Sprintf("expander %s chose node template %s: %d%% of the node would be wasted", expander, best.Name, int64(bestWaste*100)), nil
+	case ExpanderBestFit:
+		best, bestHostable := templates[0], -1
+		for _, template := range templates {
+			if hostable := countHostablePods(template, pendingPods); hostable > bestHostable {
+				best, bestHostable = template, hostable
+			}
+		}
+		return best, fmt.Sprintf("expander %s chose node template %s: can host %d of %d pending pod(s)", expander, best.Name, bestHostable, len(pendingPods)), nil
+	}
+	return nil, "", fmt.Errorf("unknown expander %s ", expander)
+}
@@ -0,0 +1,78 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/alibaba/open-simulator/pkg/test"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestChooseNodeTemplate(t *testing.T) {
+	templates := []*corev1.Node{
+		test.MakeFakeNode("big-pool", "16", "32Gi"),
+		test.MakeFakeNode("small-pool", "2", "4Gi"),
+	}
+	pendingPods := []*corev1.Pod{
+		test.MakeFakePod("pending-1", "default", "1", "1Gi"),
+		test.MakeFakePod("pending-2", "default", "1", "1Gi"),
+		test.MakeFakePod("pending-3", "default", "1", "1Gi"),
+	}
+
+	// most-pods grows the big pool because all three pending pods fit on one node
+	chosen, _, err := chooseNodeTemplate(ExpanderMostPods, templates, pendingPods)
+	if err != nil {
+		t.Fatalf("chooseNodeTemplate(%s) error = %v", ExpanderMostPods, err)
+	}
+	if chosen.Name != "big-pool" {
+		t.Errorf("chooseNodeTemplate(%s) = %s, want big-pool", ExpanderMostPods, chosen.Name)
+	}
+
+	// least-waste grows the small pool because the big node would be mostly idle
+	chosen, _, err = chooseNodeTemplate(ExpanderLeastWaste, templates, pendingPods)
+	if err != nil {
+		t.Fatalf("chooseNodeTemplate(%s) error = %v", ExpanderLeastWaste, err)
+	}
+	if chosen.Name != "small-pool" {
+		t.Errorf("chooseNodeTemplate(%s) = %s, want small-pool", ExpanderLeastWaste, chosen.Name)
+	}
+
+	// best-fit grows the template that can host the most pending pods on its own; the
+	// high-memory pod rules out the small pool
+	mixedPods := append(pendingPods, test.MakeFakePod("pending-4", "default", "1", "8Gi"))
+	chosen, _, err = chooseNodeTemplate(ExpanderBestFit, templates, mixedPods)
+	if err != nil {
+		t.Fatalf("chooseNodeTemplate(%s) error = %v", ExpanderBestFit, err)
+	}
+	if chosen.Name != "big-pool" {
+		t.Errorf("chooseNodeTemplate(%s) = %s, want big-pool", ExpanderBestFit, chosen.Name)
+	}
+
+	if _, _, err := chooseNodeTemplate("made-up", templates, pendingPods); err == nil {
+		t.Errorf("chooseNodeTemplate(made-up) expected an error")
+	}
+}
+
+func TestFilterScalablePoolTemplates(t *testing.T) {
+	templates := []*corev1.Node{
+		test.MakeFakeNode("general-template", "8", "16Gi",
+			test.WithNodeLabels(map[string]string{"pool": "general"})),
+		test.MakeFakeNode("gpu-template", "8", "16Gi",
+			test.WithNodeLabels(map[string]string{"pool": "gpu"})),
+		test.MakeFakeNode("unlabeled-template", "8", "16Gi"),
+	}
+
+	filtered := filterScalablePoolTemplates(templates, "pool", []string{"general"})
+	if len(filtered) != 1 || filtered[0].Name != "general-template" {
+		t.Fatalf("filterScalablePoolTemplates() = %v, want only general-template", filtered)
+	}
+
+	// unlabeled templates fall back to their name as the pool
+	filtered = filterScalablePoolTemplates(templates, "pool", []string{"unlabeled-template"})
+	if len(filtered) != 1 || filtered[0].Name != "unlabeled-template" {
+		t.Fatalf("filterScalablePoolTemplates() = %v, want only unlabeled-template", filtered)
+	}
+
+	if filtered := filterScalablePoolTemplates(templates, "pool", []string{"edge"}); len(filtered) != 0 {
+		t.Errorf("filterScalablePoolTemplates() = %v, want no template", filtered)
+	}
+}
@@ -0,0 +1,22 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// exportResult writes the raw simulation result as JSON to the given file, so two
+// runs can later be compared with simon diff
+func exportResult(path string, result *simulator.SimulateResult) error {
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal simulation result: %v ", err)
+	}
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write result file %s: %v ", path, err)
+	}
+	return nil
+}
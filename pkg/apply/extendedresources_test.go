@@ -0,0 +1,34 @@
+package apply
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+)
+
+func TestGenericExtendedResources(t *testing.T) {
+	nodeStatuses := []simulator.NodeStatus{
+		{
+			Node: test.MakeFakeNode("gpu-node", "8", "16Gi",
+				test.WithNodeResources(corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("4")})),
+		},
+		{
+			Node: test.MakeFakeNode("plain-node", "8", "16Gi"),
+		},
+	}
+
+	// the specially rendered keywords stay out, unknown names only count when a node
+	// advertises them
+	generic := genericExtendedResources([]string{"gpu", "open-local", "ephemeral-storage", "nvidia.com/gpu", "example.com/fpga"}, nodeStatuses)
+	if len(generic) != 1 || generic[0] != corev1.ResourceName("nvidia.com/gpu") {
+		t.Errorf("genericExtendedResources() = %v, want only nvidia.com/gpu", generic)
+	}
+
+	if generic := genericExtendedResources(nil, nodeStatuses); len(generic) != 0 {
+		t.Errorf("genericExtendedResources(nil) = %v, want none", generic)
+	}
+}
@@ -0,0 +1,40 @@
+package apply
+
+import (
+	"sort"
+
+	"github.com/pterm/pterm"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// reportVerboseFailures breaks a scheduling failure down to which filter plugin rejected
+// the pod on which node, turning the single reason string into something debuggable for
+// placement problems spanning several constraints
+func reportVerboseFailures(unscheduledPods []simulator.UnscheduledPod) {
+	pterm.FgYellow.Println("Failure Breakdown")
+	for _, unscheduledPod := range unscheduledPods {
+		pterm.FgLightWhite.Printf("%s/%s:\n", unscheduledPod.Pod.Namespace, unscheduledPod.Pod.Name)
+		if len(unscheduledPod.FilterFailures) == 0 {
+			pterm.FgLightWhite.Println("    no per-node filter verdicts recorded")
+			continue
+		}
+		var nodeNames []string
+		for nodeName := range unscheduledPod.FilterFailures {
+			nodeNames = append(nodeNames, nodeName)
+		}
+		sort.Strings(nodeNames)
+		for _, nodeName := range nodeNames {
+			failures := unscheduledPod.FilterFailures[nodeName]
+			var plugins []string
+			for plugin := range failures {
+				plugins = append(plugins, plugin)
+			}
+			sort.Strings(plugins)
+			for _, plugin := range plugins {
+				pterm.FgLightWhite.Printf("    %s: %s: %s\n", nodeName, plugin, failures[plugin])
+			}
+		}
+	}
+	pterm.FgYellow.Println()
+}
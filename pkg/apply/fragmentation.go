@@ -0,0 +1,107 @@
+package apply
+
+import (
+	"fmt"
+
+	"github.com/pterm/pterm"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	gpushareutils "github.com/alibaba/open-simulator/pkg/type/open-gpu-share/utils"
+)
+
+// podShape is the reference request shape fragmentation is measured against
+type podShape struct {
+	cpuMilli int64
+	memory   int64
+	gpuMem   int64
+}
+
+// largestPodShape combines the per-dimension maxima of all pod requests in the run, so
+// the reference stands for the hardest pod a fragmented node would have to host next
+func largestPodShape(nodeStatuses []simulator.NodeStatus) podShape {
+	var shape podShape
+	for _, status := range nodeStatuses {
+		for _, pod := range status.Pods {
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			if cpu := podReqs.Cpu().MilliValue(); cpu > shape.cpuMilli {
+				shape.cpuMilli = cpu
+			}
+			if memory := podReqs.Memory().Value(); memory > shape.memory {
+				shape.memory = memory
+			}
+			if gpuMem := gpushareutils.GetGpuMemoryFromPodAnnotation(pod); gpuMem > shape.gpuMem {
+				shape.gpuMem = gpuMem
+			}
+		}
+	}
+	return shape
+}
+
+// fragmentationPercent scores how much of a node's free capacity is stranded: per
+// dimension, the leftover after packing as many reference-shaped pods as fit can not
+// host another one, so it counts as fragmented. The worst dimension sets the score;
+// a node whose free cpu or memory can not fit even one reference pod scores 100.
+func fragmentationPercent(status simulator.NodeStatus, shape podShape) int {
+	allocatable := status.Node.Status.Allocatable
+	var usedCpu, usedMemory, usedGpuMem int64
+	for _, pod := range status.Pods {
+		podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+		usedCpu += podReqs.Cpu().MilliValue()
+		usedMemory += podReqs.Memory().Value()
+		usedGpuMem += gpushareutils.GetGpuMemoryFromPodAnnotation(pod)
+	}
+	dimensions := []struct {
+		free int64
+		ref  int64
+	}{
+		{free: allocatable.Cpu().MilliValue() - usedCpu, ref: shape.cpuMilli},
+		{free: allocatable.Memory().Value() - usedMemory, ref: shape.memory},
+		{free: gpushareutils.GetTotalGpuMemory(status.Node) - usedGpuMem, ref: shape.gpuMem},
+	}
+	frag := 0
+	for _, dimension := range dimensions {
+		if dimension.ref <= 0 || dimension.free <= 0 {
+			continue
+		}
+		stranded := dimension.free % dimension.ref
+		if dimensionFrag := occupancyPercent(stranded, dimension.free); dimensionFrag > frag {
+			frag = dimensionFrag
+		}
+	}
+	return frag
+}
+
+// reportFragmentationSummary prints the cluster-wide average so bin-packing quality can
+// be compared between runs at a glance
+func reportFragmentationSummary(nodeStatuses []simulator.NodeStatus, shape podShape) {
+	if len(nodeStatuses) == 0 {
+		return
+	}
+	total := 0
+	for _, status := range nodeStatuses {
+		total += fragmentationPercent(status, shape)
+	}
+	pterm.FgLightWhite.Printf("average fragmentation: %d%% of free capacity is stranded relative to the largest pod shape (cpu %s, memory %s)\n",
+		total/len(nodeStatuses), formatMilliCpu(shape.cpuMilli), formatBytes(shape.memory))
+	pterm.FgYellow.Println()
+}
+
+// formatMilliCpu renders a milli-cpu amount the way requests appear in the tables
+func formatMilliCpu(milli int64) string {
+	if milli%1000 == 0 {
+		return fmt.Sprintf("%d", milli/1000)
+	}
+	return fmt.Sprintf("%dm", milli)
+}
+
+// formatBytes renders a byte amount in binary units like the quantity columns
+func formatBytes(bytes int64) string {
+	switch {
+	case bytes >= 1<<30 && bytes%(1<<30) == 0:
+		return fmt.Sprintf("%dGi", bytes/(1<<30))
+	case bytes >= 1<<20 && bytes%(1<<20) == 0:
+		return fmt.Sprintf("%dMi", bytes/(1<<20))
+	}
+	return fmt.Sprintf("%d", bytes)
+}
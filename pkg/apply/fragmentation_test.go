@@ -0,0 +1,53 @@
+package apply
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+)
+
+func TestFragmentationPercent(t *testing.T) {
+	nodeStatuses := []simulator.NodeStatus{
+		{
+			// 4 cpu and 8Gi free: exactly two more 2-cpu/4Gi pods fit, nothing stranded
+			Node: test.MakeFakeNode("tidy-node", "8", "16Gi"),
+			Pods: []*corev1.Pod{
+				test.MakeFakePod("pod-1", "default", "2", "4Gi", test.WithPodNodeName("tidy-node")),
+				test.MakeFakePod("pod-2", "default", "2", "4Gi", test.WithPodNodeName("tidy-node")),
+			},
+		},
+		{
+			// 1 cpu and 1Gi free: too small for another 2-cpu/4Gi pod, fully stranded
+			Node: test.MakeFakeNode("fragmented-node", "3", "5Gi"),
+			Pods: []*corev1.Pod{
+				test.MakeFakePod("pod-3", "default", "2", "4Gi", test.WithPodNodeName("fragmented-node")),
+			},
+		},
+	}
+
+	shape := largestPodShape(nodeStatuses)
+	if shape.cpuMilli != 2000 || shape.memory != 4*(1<<30) {
+		t.Fatalf("largestPodShape() = %+v, want 2 cpu and 4Gi", shape)
+	}
+
+	if frag := fragmentationPercent(nodeStatuses[0], shape); frag != 0 {
+		t.Errorf("fragmentationPercent(tidy-node) = %d%%, want 0%%", frag)
+	}
+	if frag := fragmentationPercent(nodeStatuses[1], shape); frag != 100 {
+		t.Errorf("fragmentationPercent(fragmented-node) = %d%%, want 100%%", frag)
+	}
+
+	// 3 cpu free packs one 2-cpu pod with 1 cpu stranded: a third of the free cpu
+	partial := simulator.NodeStatus{
+		Node: test.MakeFakeNode("partial-node", "5", "12Gi"),
+		Pods: []*corev1.Pod{
+			test.MakeFakePod("pod-4", "default", "2", "4Gi", test.WithPodNodeName("partial-node")),
+		},
+	}
+	if frag := fragmentationPercent(partial, shape); frag != 33 {
+		t.Errorf("fragmentationPercent(partial-node) = %d%%, want 33%%", frag)
+	}
+}
@@ -0,0 +1,139 @@
+package apply
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	gpushareutils "github.com/alibaba/open-simulator/pkg/type/open-gpu-share/utils"
+)
+
+// htmlCard is one summary card at the top of the HTML report
+type htmlCard struct {
+	Title string
+	Value string
+}
+
+// htmlReportData is what the HTML template renders: the verdict, the summary cards and
+// the per-node sections reuse the structured report so both outputs stay in sync
+type htmlReportData struct {
+	Verdict         string
+	Succeeded       bool
+	Cards           []htmlCard
+	Nodes           []StructuredNode
+	UnscheduledPods []UnscheduledPodReport
+}
+
+// writeHTMLOutput renders the simulation result as one self-contained HTML page with a
+// collapsible section per node, made for sharing with people who do not read terminal
+// tables. The per-node data comes from the structured report, the cluster cards
+// aggregate requests against allocatable the way the Node Info table does.
+func (applier *Applier) writeHTMLOutput(result *simulator.SimulateResult, newNodeNum int, canBeScheduled bool) error {
+	nodeStatuses, _ := splitMisconfiguredNodes(result.NodeStatus)
+	report := buildStructuredReport(&simulator.SimulateResult{UnscheduledPods: result.UnscheduledPods, NodeStatus: nodeStatuses}, nil, newNodeNum, canBeScheduled)
+
+	data := htmlReportData{
+		Verdict:         fmt.Sprintf("Simulation success, %d node(s) added", newNodeNum),
+		Succeeded:       canBeScheduled,
+		Nodes:           report.Nodes,
+		UnscheduledPods: report.UnscheduledPods,
+	}
+	if !canBeScheduled {
+		data.Verdict = fmt.Sprintf("Simulation failed, %d pod(s) unscheduled", len(result.UnscheduledPods))
+	}
+
+	var cpuReq, cpuAlloc, memReq, memAlloc, gpuMemReq, gpuMemAlloc int64
+	for _, status := range nodeStatuses {
+		allocatable := status.Node.Status.Allocatable
+		cpuAlloc += allocatable.Cpu().MilliValue()
+		memAlloc += allocatable.Memory().Value()
+		gpuMemAlloc += allocatable.Name(gpushareutils.ResourceName, resource.BinarySI).Value()
+		for _, pod := range status.Pods {
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			cpuReq += podReqs.Cpu().MilliValue()
+			memReq += podReqs.Memory().Value()
+			gpuMem, gpuNum := gpushareutils.GetGpuMemoryAndCountFromPodAnnotation(pod)
+			gpuMemReq += int64(gpuMem * gpuNum)
+		}
+	}
+	data.Cards = []htmlCard{
+		{Title: "CPU Utilization", Value: fmt.Sprintf("%d%%", fraction(cpuReq, cpuAlloc))},
+		{Title: "Memory Utilization", Value: fmt.Sprintf("%d%%", fraction(memReq, memAlloc))},
+	}
+	if gpuMemAlloc > 0 {
+		data.Cards = append(data.Cards, htmlCard{Title: "GPU Mem Utilization", Value: fmt.Sprintf("%d%%", fraction(gpuMemReq, gpuMemAlloc))})
+	}
+	data.Cards = append(data.Cards, htmlCard{Title: "New Nodes", Value: fmt.Sprintf("%d", newNodeNum)})
+
+	var target io.Writer = os.Stdout
+	if applier.outputFile != nil {
+		target = applier.outputFile
+	}
+	if err := htmlReportTemplate.Execute(target, data); err != nil {
+		return fmt.Errorf("failed to write html output: %v ", err)
+	}
+	return nil
+}
+
+// htmlReportTemplate is the whole page; styles are inlined so the file stands alone
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Cluster Capacity Simulation</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2em auto; max-width: 60em; color: #24292f; }
+h1.success { color: #1a7f37; }
+h1.failure { color: #cf222e; }
+.cards { display: flex; gap: 1em; flex-wrap: wrap; margin-bottom: 2em; }
+.card { border: 1px solid #d0d7de; border-radius: 6px; padding: 1em 1.5em; min-width: 9em; }
+.card .value { font-size: 2em; font-weight: 600; }
+.card .title { color: #57606a; }
+details { border: 1px solid #d0d7de; border-radius: 6px; margin-bottom: 0.5em; padding: 0.5em 1em; }
+summary { cursor: pointer; font-weight: 600; }
+summary .meta { font-weight: 400; color: #57606a; }
+summary .new-node { color: #1a7f37; }
+table { border-collapse: collapse; margin: 0.8em 0; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 0.3em 0.8em; text-align: left; }
+th { background: #f6f8fa; }
+.unscheduled { border-color: #cf222e; }
+</style>
+</head>
+<body>
+<h1 class="{{if .Succeeded}}success{{else}}failure{{end}}">{{.Verdict}}</h1>
+<div class="cards">
+{{- range .Cards}}
+<div class="card"><div class="value">{{.Value}}</div><div class="title">{{.Title}}</div></div>
+{{- end}}
+</div>
+<h2>Nodes</h2>
+{{- range .Nodes}}
+<details>
+<summary>{{.Name}}{{if .NewNode}} <span class="new-node">new</span>{{end}} <span class="meta">cpu {{.RequestedCpu}}/{{.AllocatableCpu}} ({{.CpuFraction}}%), memory {{.RequestedMemory}}/{{.AllocatableMemory}} ({{.MemoryFraction}}%), {{len .Pods}} pod(s)</span></summary>
+{{- if .Pods}}
+<table>
+<tr><th>Namespace</th><th>Pod</th><th>App</th><th>CPU Requests</th><th>Memory Requests</th></tr>
+{{- range .Pods}}
+<tr><td>{{.Namespace}}</td><td>{{.Name}}</td><td>{{.App}}</td><td>{{.Cpu}}</td><td>{{.Memory}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</details>
+{{- end}}
+{{- if .UnscheduledPods}}
+<h2>Unscheduled Pods</h2>
+<table class="unscheduled">
+<tr><th>Namespace</th><th>Pod</th><th>Reason</th></tr>
+{{- range .UnscheduledPods}}
+<tr><td>{{.Namespace}}</td><td>{{.Name}}</td><td>{{.Reason}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,55 @@
+package apply
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+)
+
+func TestWriteHTMLOutput(t *testing.T) {
+	node := test.MakeFakeNode("node-1", "8", "16Gi")
+	pod := test.MakeFakePod("pod-1", "default", "2", "4Gi", test.WithPodNodeName("node-1"))
+	result := &simulator.SimulateResult{
+		NodeStatus: []simulator.NodeStatus{
+			{Node: node, Pods: []*corev1.Pod{pod}},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	outputFile, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create output file: %v", err)
+	}
+	applier := &Applier{outputFile: outputFile}
+	if err := applier.writeHTMLOutput(result, 1, true); err != nil {
+		t.Fatalf("writeHTMLOutput() error = %v", err)
+	}
+	outputFile.Close()
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	page := string(content)
+	if !strings.Contains(page, "Simulation success, 1 node(s) added") {
+		t.Errorf("report %q misses the verdict heading", page)
+	}
+	// one quarter of the single node's cpu and memory is requested
+	if !strings.Contains(page, ">25%<") {
+		t.Errorf("report %q misses the cluster utilization cards", page)
+	}
+	// no GPU is advertised, so no GPU card shows up
+	if strings.Contains(page, "GPU Mem Utilization") {
+		t.Errorf("report %q shows a GPU card although the cluster has no GPUs", page)
+	}
+	if !strings.Contains(page, "<details>") || !strings.Contains(page, "<td>pod-1</td>") {
+		t.Errorf("report %q misses the collapsible node section with its pods", page)
+	}
+}
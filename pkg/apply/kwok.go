@@ -0,0 +1,66 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// AnnoKwokFakeNode marks a node as managed by kwok so its controller keeps it ready
+const AnnoKwokFakeNode = "kwok.x-k8s.io/node"
+
+// exportKwokManifests materializes the simulated cluster as manifests kwok can load:
+// every node is annotated for the kwok controller and every pod keeps the placement the
+// simulation decided on, so a fake cluster reproduces the plan exactly.
+func exportKwokManifests(dir string, nodeStatuses []simulator.NodeStatus) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create kwok export directory %s: %v ", dir, err)
+	}
+
+	var nodeContent, podContent []byte
+	for _, status := range nodeStatuses {
+		node := status.Node.DeepCopy()
+		node.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Node"}
+		metav1.SetMetaDataAnnotation(&node.ObjectMeta, AnnoKwokFakeNode, "fake")
+		data, err := yaml.Marshal(node)
+		if err != nil {
+			return fmt.Errorf("failed to marshal node %s: %v ", node.Name, err)
+		}
+		if len(nodeContent) > 0 {
+			nodeContent = append(nodeContent, []byte("---\n")...)
+		}
+		nodeContent = append(nodeContent, data...)
+
+		for _, pod := range status.Pods {
+			exportPod := pod.DeepCopy()
+			exportPod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+			// drop the scheduler-bound status so kwok adopts the pod itself
+			exportPod.Status = corev1.PodStatus{}
+			data, err := yaml.Marshal(exportPod)
+			if err != nil {
+				return fmt.Errorf("failed to marshal pod %s/%s: %v ", exportPod.Namespace, exportPod.Name, err)
+			}
+			if len(podContent) > 0 {
+				podContent = append(podContent, []byte("---\n")...)
+			}
+			podContent = append(podContent, data...)
+		}
+	}
+
+	nodePath := filepath.Join(dir, "nodes.yaml")
+	if err := ioutil.WriteFile(nodePath, nodeContent, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v ", nodePath, err)
+	}
+	podPath := filepath.Join(dir, "pods.yaml")
+	if err := ioutil.WriteFile(podPath, podContent, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v ", podPath, err)
+	}
+	return nil
+}
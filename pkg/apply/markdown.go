@@ -0,0 +1,65 @@
+package apply
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// writeMarkdownOutput emits the Node Info and Pod Info tables as GitHub-flavored
+// Markdown with the success/failure verdict as the heading, so a CI job can post the
+// report straight into a PR comment or wiki page. The GPU and local-storage columns
+// are conditional on --extended-resources, same as in the pterm and csv tables.
+func (applier *Applier) writeMarkdownOutput(result *simulator.SimulateResult, newNodeNum int, canBeScheduled bool) error {
+	nodeStatuses, _ := splitMisconfiguredNodes(result.NodeStatus)
+	nodeRecords := nodeInfoCSV(nodeStatuses, applier.extendedResources)
+	podRecords := podInfoCSV(nodeStatuses, applier.extendedResources)
+
+	var target io.Writer = os.Stdout
+	if applier.outputFile != nil {
+		target = applier.outputFile
+	}
+
+	heading := fmt.Sprintf("# Simulation success, %d node(s) added", newNodeNum)
+	if !canBeScheduled {
+		heading = fmt.Sprintf("# Simulation failed, %d pod(s) unscheduled", len(result.UnscheduledPods))
+	}
+	if _, err := fmt.Fprintf(target, "%s\n\n", heading); err != nil {
+		return fmt.Errorf("failed to write markdown output: %v ", err)
+	}
+
+	if err := writeMarkdownTable(target, "Node Info", nodeRecords); err != nil {
+		return err
+	}
+	return writeMarkdownTable(target, "Pod Info", podRecords)
+}
+
+// writeMarkdownTable renders one table, first record as header, as a Markdown section
+func writeMarkdownTable(target io.Writer, title string, records [][]string) error {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## %s\n\n", title))
+	for i, record := range records {
+		cells := make([]string, 0, len(record))
+		for _, cell := range record {
+			cells = append(cells, markdownCell(cell))
+		}
+		builder.WriteString(fmt.Sprintf("| %s |\n", strings.Join(cells, " | ")))
+		if i == 0 {
+			builder.WriteString(fmt.Sprintf("|%s\n", strings.Repeat(" --- |", len(record))))
+		}
+	}
+	builder.WriteString("\n")
+	if _, err := io.WriteString(target, builder.String()); err != nil {
+		return fmt.Errorf("failed to write markdown output: %v ", err)
+	}
+	return nil
+}
+
+// markdownCell escapes the characters that would break out of a table cell
+func markdownCell(cell string) string {
+	cell = strings.ReplaceAll(cell, "|", "\\|")
+	return strings.ReplaceAll(cell, "\n", " ")
+}
@@ -0,0 +1,29 @@
+package apply
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteMarkdownTable(t *testing.T) {
+	var out strings.Builder
+	records := [][]string{
+		{"Node", "CPU Allocatable"},
+		{"node-1", "8"},
+		{"node|2", "4"},
+	}
+	if err := writeMarkdownTable(&out, "Node Info", records); err != nil {
+		t.Fatalf("writeMarkdownTable() error = %v", err)
+	}
+	rendered := out.String()
+	if !strings.HasPrefix(rendered, "## Node Info\n\n| Node | CPU Allocatable |\n| --- | --- |\n") {
+		t.Errorf("writeMarkdownTable() header = %q, want a Markdown table header", rendered)
+	}
+	if !strings.Contains(rendered, "| node-1 | 8 |\n") {
+		t.Errorf("writeMarkdownTable() output %q misses the data row", rendered)
+	}
+	// a pipe in a cell must not break out of the table
+	if !strings.Contains(rendered, "| node\\|2 | 4 |\n") {
+		t.Errorf("writeMarkdownTable() output %q does not escape pipes", rendered)
+	}
+}
@@ -0,0 +1,86 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+// requestFraction is the fraction math of report() as a plain ratio, guarded against
+// zero-allocatable nodes
+func requestFraction(used, allocatable int64) float64 {
+	if allocatable == 0 {
+		return 0
+	}
+	return float64(used) / float64(allocatable)
+}
+
+// buildMetrics renders the simulation outcome in the Prometheus exposition format so a
+// run can be scraped into dashboards: per-node request fractions, per-app placements,
+// the unscheduled pod count and the number of added nodes
+func buildMetrics(result *simulator.SimulateResult, addedNodes int) string {
+	var lines []string
+	appendMetric := func(name, help string, samples []string) {
+		lines = append(lines, fmt.Sprintf("# HELP %s %s", name, help))
+		lines = append(lines, fmt.Sprintf("# TYPE %s gauge", name))
+		lines = append(lines, samples...)
+	}
+
+	var cpuSamples, memorySamples, appSamples []string
+	for _, status := range result.NodeStatus {
+		node := status.Node
+		newNode := "false"
+		if _, exist := node.Labels[simontype.LabelNewNode]; exist {
+			newNode = "true"
+		}
+		var cpuReq, memoryReq int64
+		appPods := make(map[string]int)
+		for _, pod := range status.Pods {
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			cpuReq += podReqs.Cpu().MilliValue()
+			memoryReq += podReqs.Memory().Value()
+			appPods[pod.Labels[simontype.LabelAppName]]++
+		}
+		cpuFraction := requestFraction(cpuReq, node.Status.Allocatable.Cpu().MilliValue())
+		memoryFraction := requestFraction(memoryReq, node.Status.Allocatable.Memory().Value())
+		cpuSamples = append(cpuSamples, fmt.Sprintf("opensim_node_cpu_request_fraction{node=%q,new_node=%q} %s", node.Name, newNode, strconv.FormatFloat(cpuFraction, 'g', -1, 64)))
+		memorySamples = append(memorySamples, fmt.Sprintf("opensim_node_memory_request_fraction{node=%q,new_node=%q} %s", node.Name, newNode, strconv.FormatFloat(memoryFraction, 'g', -1, 64)))
+
+		var apps []string
+		for app := range appPods {
+			apps = append(apps, app)
+		}
+		sort.Strings(apps)
+		for _, app := range apps {
+			appSamples = append(appSamples, fmt.Sprintf("opensim_app_pods{app=%q,node=%q,new_node=%q} %d", app, node.Name, newNode, appPods[app]))
+		}
+	}
+
+	appendMetric("opensim_node_cpu_request_fraction", "Fraction of the node's allocatable cpu requested by placed pods.", cpuSamples)
+	appendMetric("opensim_node_memory_request_fraction", "Fraction of the node's allocatable memory requested by placed pods.", memorySamples)
+	appendMetric("opensim_app_pods", "Number of placed pods per app and node.", appSamples)
+	appendMetric("opensim_unscheduled_pods", "Number of pods the simulation could not schedule.", []string{fmt.Sprintf("opensim_unscheduled_pods %d", len(result.UnscheduledPods))})
+	appendMetric("opensim_new_nodes_added", "Number of nodes the scaling loop added to the cluster.", []string{fmt.Sprintf("opensim_new_nodes_added %d", addedNodes)})
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// writeMetrics writes the exposition-format metrics of the run to the configured file,
+// or to stdout when the path is "-"
+func (applier *Applier) writeMetrics(result *simulator.SimulateResult, addedNodes int) error {
+	content := buildMetrics(result, addedNodes)
+	if applier.metricsFile == "-" {
+		fmt.Print(content)
+		return nil
+	}
+	if err := ioutil.WriteFile(applier.metricsFile, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file %s: %v ", applier.metricsFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package apply
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+func TestBuildMetrics(t *testing.T) {
+	node := test.MakeFakeNode("node-1", "8", "16Gi")
+	newNode := test.MakeFakeNode("simon-node-1", "8", "16Gi",
+		test.WithNodeLabels(map[string]string{simontype.LabelNewNode: ""}))
+	pod := test.MakeFakePod("pod-1", "default", "2", "4Gi", test.WithPodNodeName("node-1"))
+	pod.Labels = map[string]string{simontype.LabelAppName: "demo-app"}
+	result := &simulator.SimulateResult{
+		NodeStatus: []simulator.NodeStatus{
+			{Node: node, Pods: []*corev1.Pod{pod}},
+			{Node: newNode},
+		},
+		UnscheduledPods: []simulator.UnscheduledPod{
+			{Pod: test.MakeFakePod("pod-2", "default", "32", "4Gi"), Reason: "Insufficient cpu"},
+		},
+	}
+
+	content := buildMetrics(result, 1)
+	for _, expected := range []string{
+		"# TYPE opensim_node_cpu_request_fraction gauge",
+		`opensim_node_cpu_request_fraction{node="node-1",new_node="false"} 0.25`,
+		`opensim_node_memory_request_fraction{node="node-1",new_node="false"} 0.25`,
+		`opensim_node_cpu_request_fraction{node="simon-node-1",new_node="true"} 0`,
+		`opensim_app_pods{app="demo-app",node="node-1",new_node="false"} 1`,
+		"opensim_unscheduled_pods 1",
+		"opensim_new_nodes_added 1",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Fatalf("metrics output misses %q:\n%s", expected, content)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package apply
+
+import (
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// zeroAllocatable reports whether a node ended up with no allocatable cpu or memory,
+// which happens when a template or its reservation math is wrong and would turn every
+// utilization fraction into a division by zero
+func zeroAllocatable(node *corev1.Node) bool {
+	return node.Status.Allocatable.Cpu().MilliValue() == 0 || node.Status.Allocatable.Memory().Value() == 0
+}
+
+// splitMisconfiguredNodes separates the nodes with zero allocatable from the ones the
+// fraction math may safely use
+func splitMisconfiguredNodes(nodeStatuses []simulator.NodeStatus) ([]simulator.NodeStatus, []string) {
+	var valid []simulator.NodeStatus
+	var misconfigured []string
+	for _, status := range nodeStatuses {
+		if zeroAllocatable(status.Node) {
+			misconfigured = append(misconfigured, status.Node.Name)
+			continue
+		}
+		valid = append(valid, status)
+	}
+	return valid, misconfigured
+}
+
+// reportMisconfiguredNodes lists the nodes the utilization report excluded
+func reportMisconfiguredNodes(misconfigured []string) {
+	if len(misconfigured) == 0 {
+		return
+	}
+	pterm.FgYellow.Println("Misconfigured Nodes")
+	for _, name := range misconfigured {
+		pterm.FgRed.Printf("node %s has zero allocatable cpu or memory, check its template or reservations\n", name)
+	}
+	pterm.FgYellow.Println()
+}
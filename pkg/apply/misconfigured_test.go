@@ -0,0 +1,41 @@
+package apply
+
+import (
+	"testing"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSplitMisconfiguredNodes(t *testing.T) {
+	nodeStatuses := []simulator.NodeStatus{
+		{
+			Node: test.MakeFakeNode("node-1", "8", "16Gi"),
+			Pods: []*corev1.Pod{
+				test.MakeFakePod("pod-1", "default", "4", "4Gi", test.WithPodNodeName("node-1")),
+			},
+		},
+		{
+			Node: test.MakeFakeNode("broken-node", "0", "0"),
+		},
+	}
+
+	valid, misconfigured := splitMisconfiguredNodes(nodeStatuses)
+	if len(valid) != 1 || valid[0].Node.Name != "node-1" {
+		t.Errorf("splitMisconfiguredNodes() kept %d node(s), want only node-1", len(valid))
+	}
+	if len(misconfigured) != 1 || misconfigured[0] != "broken-node" {
+		t.Errorf("splitMisconfiguredNodes() flagged %v, want broken-node", misconfigured)
+	}
+
+	// the broken node must not poison the occupancy rates with a division by zero
+	satisfied, reason, err := satisfyResourceSetting(nodeStatuses, v1alpha1.ResourceThresholds{})
+	if err != nil {
+		t.Fatalf("satisfyResourceSetting() error = %v", err)
+	}
+	if !satisfied || reason != "" {
+		t.Errorf("satisfyResourceSetting() = %v, %q, want satisfied with no reason", satisfied, reason)
+	}
+}
@@ -0,0 +1,97 @@
+package apply
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+// ParseRequestMultipliers parses per-app request multipliers of the form app=0.1 into a
+// map keyed by app name, so a canary can be modeled at a fraction of production scale
+func ParseRequestMultipliers(items []string) (map[string]float64, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	multipliers := make(map[string]float64, len(items))
+	for _, item := range items {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid request multiplier %q, expect <app>=<factor> ", item)
+		}
+		factor, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil || factor <= 0 {
+			return nil, fmt.Errorf("invalid factor %q for app %s, expect a positive number ", parts[1], parts[0])
+		}
+		multipliers[parts[0]] = factor
+	}
+	return multipliers, nil
+}
+
+// reportEffectiveAppDemand prints the per-app resource demand the simulation actually
+// scheduled with, so the effect of the request multipliers is visible in the report
+func reportEffectiveAppDemand(nodeStatuses []simulator.NodeStatus, multipliers map[string]float64) error {
+	type appDemand struct {
+		pods   int
+		cpu    int64
+		memory int64
+	}
+	demandByApp := make(map[string]*appDemand)
+	for _, status := range nodeStatuses {
+		for _, pod := range status.Pods {
+			appName, exist := pod.Labels[simontype.LabelAppName]
+			if !exist {
+				continue
+			}
+			demand, exist := demandByApp[appName]
+			if !exist {
+				demand = &appDemand{}
+				demandByApp[appName] = demand
+			}
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			demand.pods++
+			demand.cpu += podReqs.Cpu().MilliValue()
+			demand.memory += podReqs.Memory().Value()
+		}
+	}
+	if len(demandByApp) == 0 {
+		return nil
+	}
+
+	appNames := make([]string, 0, len(demandByApp))
+	for appName := range demandByApp {
+		appNames = append(appNames, appName)
+	}
+	sort.Strings(appNames)
+
+	demandTableData := [][]string{{"App", "Multiplier", "Pods", "CPU", "Memory"}}
+	for _, appName := range appNames {
+		multiplier := 1.0
+		if factor, exist := multipliers[appName]; exist {
+			multiplier = factor
+		}
+		demand := demandByApp[appName]
+		demandTableData = append(demandTableData, []string{
+			appName,
+			strconv.FormatFloat(multiplier, 'g', -1, 64),
+			strconv.Itoa(demand.pods),
+			resource.NewMilliQuantity(demand.cpu, resource.DecimalSI).String(),
+			resource.NewQuantity(demand.memory, resource.BinarySI).String(),
+		})
+	}
+
+	pterm.FgYellow.Println("Effective App Demand")
+	demandTable := pterm.DefaultTable.WithHasHeader()
+	if err := demandTable.WithData(demandTableData).Render(); err != nil {
+		return fmt.Errorf("fail to render effective app demand table: %s ", err.Error())
+	}
+	pterm.FgYellow.Println()
+	return nil
+}
@@ -0,0 +1,23 @@
+package apply
+
+import "testing"
+
+func TestParseRequestMultipliers(t *testing.T) {
+	multipliers, err := ParseRequestMultipliers([]string{"canary-app=0.1", "load-app=2"})
+	if err != nil {
+		t.Fatalf("ParseRequestMultipliers() error = %v", err)
+	}
+	if multipliers["canary-app"] != 0.1 || multipliers["load-app"] != 2 {
+		t.Errorf("ParseRequestMultipliers() = %v, want canary-app=0.1 and load-app=2", multipliers)
+	}
+
+	if _, err := ParseRequestMultipliers([]string{"canary-app"}); err == nil {
+		t.Errorf("ParseRequestMultipliers(canary-app) expected an error")
+	}
+	if _, err := ParseRequestMultipliers([]string{"canary-app=0"}); err == nil {
+		t.Errorf("ParseRequestMultipliers(canary-app=0) expected an error")
+	}
+	if _, err := ParseRequestMultipliers([]string{"canary-app=fast"}); err == nil {
+		t.Errorf("ParseRequestMultipliers(canary-app=fast) expected an error")
+	}
+}
@@ -0,0 +1,107 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+)
+
+// parseNewNode accepts the two forms of Spec.NewNode: the original single path string
+// and a list of node templates with per-template limits for heterogeneous clusters
+func parseNewNode(raw json.RawMessage) (string, []v1alpha1.NodeTemplate, error) {
+	if len(raw) == 0 {
+		return "", nil, nil
+	}
+	var path string
+	if err := json.Unmarshal(raw, &path); err == nil {
+		return path, nil, nil
+	}
+	var templates []v1alpha1.NodeTemplate
+	if err := json.Unmarshal(raw, &templates); err != nil {
+		return "", nil, fmt.Errorf("failed to unmarshal newNode, expect a path or a list of {name, path, maxCount} entries: %v ", err)
+	}
+	for _, template := range templates {
+		if template.Path == "" {
+			return "", nil, fmt.Errorf("every newNode template needs a path ")
+		}
+		if template.MaxCount < 0 {
+			return "", nil, fmt.Errorf("maxCount of newNode template %s must be non-negative ", template.Path)
+		}
+		switch template.TaintPolicy {
+		case "", TaintPolicyPreserve, TaintPolicyStrip, TaintPolicyReplace:
+		default:
+			return "", nil, fmt.Errorf("taintPolicy of newNode template %s must be %s, %s or %s ", template.Path, TaintPolicyPreserve, TaintPolicyStrip, TaintPolicyReplace)
+		}
+		if len(template.Taints) != 0 && template.TaintPolicy != TaintPolicyReplace {
+			return "", nil, fmt.Errorf("taints of newNode template %s are only applied with taintPolicy %s ", template.Path, TaintPolicyReplace)
+		}
+		if err := validateNodeNamePrefix(template.NamePrefix); err != nil {
+			return "", nil, fmt.Errorf("namePrefix of newNode template %s: %v ", template.Path, err)
+		}
+		if err := validateReservedResources(template); err != nil {
+			return "", nil, fmt.Errorf("reserved resources of newNode template %s: %v ", template.Path, err)
+		}
+	}
+	return "", templates, nil
+}
+
+const (
+	// TaintPolicyPreserve keeps the taints of the template node on every generated node
+	TaintPolicyPreserve = "preserve"
+	// TaintPolicyStrip removes the template's taints, so untolerated pods can land on
+	// the generated nodes
+	TaintPolicyStrip = "strip"
+	// TaintPolicyReplace swaps the template's taints for the ones declared on the
+	// template config
+	TaintPolicyReplace = "replace"
+)
+
+// validateNodeNamePrefix rejects prefixes that would yield node names no apiserver
+// would accept; the ordinal suffix appended later keeps a valid prefix valid
+func validateNodeNamePrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(prefix); len(errs) != 0 {
+		return fmt.Errorf("node name prefix %s is not a valid DNS subdomain: %s ", prefix, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// applyTaintPolicy rewrites the taints of a parsed template node according to the
+// template's taint policy. The scheduler and the NodeShouldRunPod check on the failure
+// path both evaluate the resulting taints, so stripping is the way to keep a tainted
+// production template usable for untolerated pods in what-if runs.
+func applyTaintPolicy(node *corev1.Node, template v1alpha1.NodeTemplate) {
+	switch template.TaintPolicy {
+	case TaintPolicyStrip:
+		node.Spec.Taints = nil
+	case TaintPolicyReplace:
+		node.Spec.Taints = append([]corev1.Taint{}, template.Taints...)
+	}
+}
+
+// remainingNodeTemplates drops the templates whose maxCount the already chosen nodes
+// have used up
+func remainingNodeTemplates(templates []*corev1.Node, chosen []*corev1.Node, maxCounts map[string]int) []*corev1.Node {
+	if len(maxCounts) == 0 {
+		return templates
+	}
+	counts := make(map[string]int)
+	for _, node := range chosen {
+		counts[node.Name]++
+	}
+	var remaining []*corev1.Node
+	for _, template := range templates {
+		if max, ok := maxCounts[template.Name]; ok && counts[template.Name] >= max {
+			continue
+		}
+		remaining = append(remaining, template)
+	}
+	return remaining
+}
@@ -0,0 +1,112 @@
+package apply
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+	"github.com/alibaba/open-simulator/pkg/test"
+)
+
+func TestParseNewNode(t *testing.T) {
+	// the original single-path string form keeps working
+	path, templates, err := parseNewNode(json.RawMessage(`"./example/newnode"`))
+	if err != nil {
+		t.Fatalf("parseNewNode() error = %v", err)
+	}
+	if path != "./example/newnode" || templates != nil {
+		t.Errorf("parseNewNode() = %q, %v, want the plain path", path, templates)
+	}
+
+	path, templates, err = parseNewNode(json.RawMessage(`[{"name":"small","path":"./small","maxCount":2},{"path":"./big"}]`))
+	if err != nil {
+		t.Fatalf("parseNewNode() error = %v", err)
+	}
+	if path != "" || len(templates) != 2 {
+		t.Fatalf("parseNewNode() = %q, %v, want 2 templates", path, templates)
+	}
+	if templates[0].Name != "small" || templates[0].Path != "./small" || templates[0].MaxCount != 2 {
+		t.Errorf("parseNewNode() template = %+v, want small with maxCount 2", templates[0])
+	}
+	if templates[1].MaxCount != 0 {
+		t.Errorf("parseNewNode() template = %+v, want no limit by default", templates[1])
+	}
+
+	path, templates, err = parseNewNode(nil)
+	if err != nil || path != "" || templates != nil {
+		t.Errorf("parseNewNode(nil) = %q, %v, %v, want all empty", path, templates, err)
+	}
+
+	if _, _, err := parseNewNode(json.RawMessage(`[{"name":"no-path"}]`)); err == nil {
+		t.Errorf("parseNewNode() expected an error for a template without path")
+	}
+	if _, _, err := parseNewNode(json.RawMessage(`[{"path":"./small","maxCount":-1}]`)); err == nil {
+		t.Errorf("parseNewNode() expected an error for a negative maxCount")
+	}
+	if _, _, err := parseNewNode(json.RawMessage(`[{"path":"./small","taintPolicy":"drop"}]`)); err == nil {
+		t.Errorf("parseNewNode() expected an error for an unknown taintPolicy")
+	}
+	if _, _, err := parseNewNode(json.RawMessage(`[{"path":"./small","taintPolicy":"strip","taints":[{"key":"k","effect":"NoSchedule"}]}]`)); err == nil {
+		t.Errorf("parseNewNode() expected an error for taints without the replace policy")
+	}
+	if _, _, err := parseNewNode(json.RawMessage(`[{"path":"./small","namePrefix":"Has_Underscore"}]`)); err == nil {
+		t.Errorf("parseNewNode() expected an error for a namePrefix that is no DNS subdomain")
+	}
+
+	_, templates, err = parseNewNode(json.RawMessage(`[{"path":"./small","namePrefix":"pool-a"}]`))
+	if err != nil || templates[0].NamePrefix != "pool-a" {
+		t.Errorf("parseNewNode() = %v, %v, want the namePrefix pool-a accepted", templates, err)
+	}
+}
+
+func TestApplyTaintPolicy(t *testing.T) {
+	templateTaints := []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+	makeNode := func() *corev1.Node {
+		return test.MakeFakeNode("template", "4", "8Gi", test.WithNodeTaints(templateTaints))
+	}
+
+	// the default preserves the template's taints wholesale
+	node := makeNode()
+	applyTaintPolicy(node, v1alpha1.NodeTemplate{})
+	if len(node.Spec.Taints) != 1 || node.Spec.Taints[0].Key != "dedicated" {
+		t.Errorf("applyTaintPolicy(default) taints = %v, want the template's taint preserved", node.Spec.Taints)
+	}
+
+	node = makeNode()
+	applyTaintPolicy(node, v1alpha1.NodeTemplate{TaintPolicy: TaintPolicyStrip})
+	if len(node.Spec.Taints) != 0 {
+		t.Errorf("applyTaintPolicy(strip) taints = %v, want none", node.Spec.Taints)
+	}
+
+	node = makeNode()
+	replacement := []corev1.Taint{{Key: "simulated", Effect: corev1.TaintEffectNoExecute}}
+	applyTaintPolicy(node, v1alpha1.NodeTemplate{TaintPolicy: TaintPolicyReplace, Taints: replacement})
+	if len(node.Spec.Taints) != 1 || node.Spec.Taints[0].Key != "simulated" {
+		t.Errorf("applyTaintPolicy(replace) taints = %v, want only the replacement", node.Spec.Taints)
+	}
+}
+
+func TestRemainingNodeTemplates(t *testing.T) {
+	small := test.MakeFakeNode("small", "4", "8Gi")
+	big := test.MakeFakeNode("big", "16", "32Gi")
+	templates := []*corev1.Node{small, big}
+	maxCounts := map[string]int{"small": 1}
+
+	remaining := remainingNodeTemplates(templates, nil, maxCounts)
+	if len(remaining) != 2 {
+		t.Errorf("remainingNodeTemplates() kept %d template(s), want both before any node was added", len(remaining))
+	}
+
+	// one small node added: its maxCount of 1 is used up, big stays unlimited
+	remaining = remainingNodeTemplates(templates, []*corev1.Node{small}, maxCounts)
+	if len(remaining) != 1 || remaining[0].Name != "big" {
+		t.Errorf("remainingNodeTemplates() = %v, want only big", remaining)
+	}
+
+	remaining = remainingNodeTemplates(templates, []*corev1.Node{small, big, big}, nil)
+	if len(remaining) != 2 {
+		t.Errorf("remainingNodeTemplates() kept %d template(s), want both without limits", len(remaining))
+	}
+}
@@ -0,0 +1,35 @@
+package apply
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+)
+
+// resolveOutputPath maps the --output-path flag to the file receiving the report. A
+// directory yields one file per app list, named after the deployed apps, so successive
+// batch runs do not overwrite each other; any other path is truncated and rewritten.
+func resolveOutputPath(path string, appList []v1alpha1.AppInfo) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return path, nil
+		}
+		return "", fmt.Errorf("invalid output path %s: %v ", path, err)
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	var names []string
+	for _, app := range appList {
+		names = append(names, app.Name)
+	}
+	fileName := "report.txt"
+	if len(names) != 0 {
+		fileName = strings.Join(names, "-") + "-report.txt"
+	}
+	return filepath.Join(path, fileName), nil
+}
@@ -0,0 +1,45 @@
+package apply
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+)
+
+func TestResolveOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	appList := []v1alpha1.AppInfo{{Name: "demo-app"}, {Name: "other-app"}}
+
+	path, err := resolveOutputPath(dir, appList)
+	if err != nil {
+		t.Fatalf("failed to resolve directory path: %v", err)
+	}
+	if path != filepath.Join(dir, "demo-app-other-app-report.txt") {
+		t.Fatalf("unexpected file name for directory path: %s", path)
+	}
+
+	path, err = resolveOutputPath(dir, nil)
+	if err != nil {
+		t.Fatalf("failed to resolve directory path without apps: %v", err)
+	}
+	if path != filepath.Join(dir, "report.txt") {
+		t.Fatalf("unexpected fallback file name: %s", path)
+	}
+
+	existing := filepath.Join(dir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	path, err = resolveOutputPath(existing, appList)
+	if err != nil || path != existing {
+		t.Fatalf("expect existing file to be used as is, got %s, %v", path, err)
+	}
+
+	missing := filepath.Join(dir, "missing.txt")
+	path, err = resolveOutputPath(missing, appList)
+	if err != nil || path != missing {
+		t.Fatalf("expect missing file to be used as is, got %s, %v", path, err)
+	}
+}
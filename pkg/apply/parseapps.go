@@ -0,0 +1,80 @@
+package apply
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+	"github.com/alibaba/open-simulator/pkg/chart"
+	"github.com/alibaba/open-simulator/pkg/kustomize"
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/utils"
+)
+
+// parseAppConcurrency bounds how many apps are rendered and decoded at once; chart
+// rendering is CPU-bound, so a small pool captures most of the win without stampeding
+// on dozens of large charts
+const parseAppConcurrency = 4
+
+// parseAppResource converts one app entry into kubernetes objects, rendering charts and
+// kustomizations and reading plain YAML directories as-is
+func parseAppResource(app v1alpha1.AppInfo) (simulator.ResourceTypes, error) {
+	var content []string
+	var err error
+	// process separately chart and other file
+	if app.Chart {
+		// parse and render chart as a yaml array
+		if chart.IsOCIReference(app.Path) {
+			content, err = chart.ProcessChartFromOCI(app.Name, app.Path, app.Version, app.ValuesFiles, app.SetValues)
+		} else {
+			content, err = chart.ProcessChart(app.Name, app.Path, app.ValuesFiles, app.SetValues)
+		}
+	} else if app.Kustomize {
+		content, err = kustomize.ProcessKustomize(app.Path)
+	} else {
+		content, err = utils.GetYamlContentFromDirectory(app.Path)
+	}
+	if err != nil {
+		return simulator.ResourceTypes{}, err
+	}
+	return simulator.GetObjectFromYamlContent(content)
+}
+
+// parseAppResources parses the whole app list with a bounded worker pool; the result
+// slice mirrors the app list order, so callers keep their deterministic resource list.
+// The first failure stops the workers that have not started yet and is returned with
+// the name of the app it belongs to.
+func parseAppResources(appList []v1alpha1.AppInfo) ([]simulator.ResourceTypes, error) {
+	appResources := make([]simulator.ResourceTypes, len(appList))
+	parseErrors := make([]error, len(appList))
+	var failed int32
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, parseAppConcurrency)
+	for index := range appList {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			workers <- struct{}{}
+			defer func() { <-workers }()
+			if atomic.LoadInt32(&failed) != 0 {
+				return
+			}
+			appResource, err := parseAppResource(appList[index])
+			if err != nil {
+				parseErrors[index] = fmt.Errorf("failed to parse app %s: %v ", appList[index].Name, err)
+				atomic.StoreInt32(&failed, 1)
+				return
+			}
+			appResources[index] = appResource
+		}(index)
+	}
+	wg.Wait()
+	// report the error of the first app in list order, not the first one to fail
+	for _, err := range parseErrors {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return appResources, nil
+}
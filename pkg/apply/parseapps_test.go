@@ -0,0 +1,57 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+)
+
+func TestParseAppResources(t *testing.T) {
+	dir := t.TempDir()
+	podYAML := `apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  namespace: default
+spec:
+  containers:
+  - name: container
+    image: nginx
+`
+	var appList []v1alpha1.AppInfo
+	// enough apps to exercise the worker pool beyond its concurrency bound
+	for index := 0; index < 10; index++ {
+		appDir := filepath.Join(dir, fmt.Sprintf("app-%d", index))
+		if err := os.Mkdir(appDir, 0755); err != nil {
+			t.Fatalf("failed to create app dir: %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(appDir, "pod.yaml"), []byte(fmt.Sprintf(podYAML, fmt.Sprintf("pod-%d", index))), 0644); err != nil {
+			t.Fatalf("failed to write app manifest: %v", err)
+		}
+		appList = append(appList, v1alpha1.AppInfo{Name: fmt.Sprintf("app-%d", index), Path: appDir})
+	}
+
+	appResources, err := parseAppResources(appList)
+	if err != nil {
+		t.Fatalf("parseAppResources() error = %v", err)
+	}
+	if len(appResources) != len(appList) {
+		t.Fatalf("parseAppResources() returned %d resources, want %d", len(appResources), len(appList))
+	}
+	// the result slice must mirror the app list order
+	for index, appResource := range appResources {
+		if len(appResource.Pods) != 1 || appResource.Pods[0].Name != fmt.Sprintf("pod-%d", index) {
+			t.Errorf("parseAppResources() resource %d = %v, want pod-%d", index, appResource.Pods, index)
+		}
+	}
+
+	appList[3].Path = filepath.Join(dir, "does-not-exist")
+	if _, err := parseAppResources(appList); err == nil || !strings.Contains(err.Error(), "app-3") {
+		t.Errorf("parseAppResources() error = %v, want the failing app named", err)
+	}
+}
@@ -0,0 +1,144 @@
+package apply
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+// Recommendation mirrors the VerticalPodAutoscaler object closely enough that the
+// exported YAML can be reviewed or turned into a real VPA. The simulator has no live
+// usage signal, so the target is derived from the pod requests seen in the simulation
+// and the upper bound from the limits when they are set.
+type Recommendation struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Metadata   RecommendationMetadata `json:"metadata"`
+	Spec       RecommendationSpec     `json:"spec"`
+	Status     RecommendationStatus   `json:"status"`
+}
+
+type RecommendationMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type RecommendationSpec struct {
+	TargetRef RecommendationTargetRef `json:"targetRef"`
+}
+
+type RecommendationTargetRef struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+type RecommendationStatus struct {
+	Recommendation ContainerRecommendations `json:"recommendation"`
+}
+
+type ContainerRecommendations struct {
+	ContainerRecommendations []ContainerRecommendation `json:"containerRecommendations"`
+}
+
+type ContainerRecommendation struct {
+	ContainerName string              `json:"containerName"`
+	Target        corev1.ResourceList `json:"target"`
+	UpperBound    corev1.ResourceList `json:"upperBound,omitempty"`
+}
+
+// buildRecommendations groups the scheduled pods by their owning workload and produces
+// one VPA-style recommendation object per workload
+func buildRecommendations(nodeStatuses []simulator.NodeStatus) []Recommendation {
+	type workloadKey struct {
+		kind      string
+		name      string
+		namespace string
+	}
+	workloadPods := make(map[workloadKey]*corev1.Pod)
+	var keys []workloadKey
+	for _, status := range nodeStatuses {
+		for _, pod := range status.Pods {
+			kind, exist := pod.Annotations[simontype.AnnoWorkloadKind]
+			if !exist {
+				continue
+			}
+			key := workloadKey{
+				kind:      kind,
+				name:      pod.Annotations[simontype.AnnoWorkloadName],
+				namespace: pod.Annotations[simontype.AnnoWorkloadNamespace],
+			}
+			if _, exist := workloadPods[key]; !exist {
+				workloadPods[key] = pod
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		return keys[i].name < keys[j].name
+	})
+
+	var recommendations []Recommendation
+	for _, key := range keys {
+		pod := workloadPods[key]
+		var containerRecommendations []ContainerRecommendation
+		for _, container := range pod.Spec.Containers {
+			if len(container.Resources.Requests) == 0 {
+				continue
+			}
+			containerRecommendations = append(containerRecommendations, ContainerRecommendation{
+				ContainerName: container.Name,
+				Target:        container.Resources.Requests.DeepCopy(),
+				UpperBound:    container.Resources.Limits.DeepCopy(),
+			})
+		}
+		if len(containerRecommendations) == 0 {
+			continue
+		}
+		recommendations = append(recommendations, Recommendation{
+			APIVersion: "autoscaling.k8s.io/v1",
+			Kind:       "VerticalPodAutoscaler",
+			Metadata: RecommendationMetadata{
+				Name:      key.name,
+				Namespace: key.namespace,
+			},
+			Spec: RecommendationSpec{
+				TargetRef: RecommendationTargetRef{
+					APIVersion: "apps/v1",
+					Kind:       key.kind,
+					Name:       key.name,
+				},
+			},
+			Status: RecommendationStatus{
+				Recommendation: ContainerRecommendations{
+					ContainerRecommendations: containerRecommendations,
+				},
+			},
+		})
+	}
+
+	return recommendations
+}
+
+// writeRecommendations exports the recommendations as a multi-document YAML file
+func writeRecommendations(path string, nodeStatuses []simulator.NodeStatus) error {
+	var content []byte
+	for _, recommendation := range buildRecommendations(nodeStatuses) {
+		b, err := yaml.Marshal(recommendation)
+		if err != nil {
+			return fmt.Errorf("failed to marshal recommendation for %s/%s: %v", recommendation.Metadata.Namespace, recommendation.Metadata.Name, err)
+		}
+		content = append(content, []byte("---\n")...)
+		content = append(content, b...)
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,109 @@
+package apply
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// validateReservedResources rejects reserved quantities no kubelet flag would accept
+func validateReservedResources(template v1alpha1.NodeTemplate) error {
+	for _, reserved := range []corev1.ResourceList{template.KubeReserved, template.SystemReserved} {
+		for name, quantity := range reserved {
+			if quantity.Sign() < 0 {
+				return fmt.Errorf("reserved %s must be non-negative, got %s ", name, quantity.String())
+			}
+		}
+	}
+	return nil
+}
+
+// applyReservedResources derives the allocatable of a template node from its capacity
+// the way the kubelet derives it from --kube-reserved and --system-reserved: both
+// reservations are subtracted per resource. Node files exported from production often
+// carry the capacity of the machine type with an unreduced allocatable, so this keeps
+// the generated nodes honest without editing every file. The fake nodes copy the
+// template's status wholesale, which carries the derived allocatable along.
+func applyReservedResources(node *corev1.Node, template v1alpha1.NodeTemplate) error {
+	if len(template.KubeReserved) == 0 && len(template.SystemReserved) == 0 {
+		return nil
+	}
+	reservedTotals := corev1.ResourceList{}
+	for _, reserved := range []corev1.ResourceList{template.KubeReserved, template.SystemReserved} {
+		for name, quantity := range reserved {
+			total := reservedTotals[name]
+			total.Add(quantity)
+			reservedTotals[name] = total
+		}
+	}
+	// the allocatable list may share its quantities, or the whole map, with the
+	// capacity list, so detach it before deriving
+	node.Status.Allocatable = node.Status.Allocatable.DeepCopy()
+	if node.Status.Allocatable == nil {
+		node.Status.Allocatable = corev1.ResourceList{}
+	}
+	for name, reservedTotal := range reservedTotals {
+		capacity, exist := node.Status.Capacity[name]
+		if !exist {
+			return fmt.Errorf("%s is reserved on newNode template %s but the node advertises no %s capacity ", name, node.Name, name)
+		}
+		allocatable := capacity.DeepCopy()
+		allocatable.Sub(reservedTotal)
+		if allocatable.Sign() < 0 {
+			return fmt.Errorf("kubeReserved and systemReserved of newNode template %s exceed its %s capacity of %s ", node.Name, name, capacity.String())
+		}
+		node.Status.Allocatable[name] = allocatable
+	}
+	return nil
+}
+
+// reportReservedResources lists every node whose allocatable is below its capacity,
+// with the reserved difference, so reservations configured on the templates stay
+// visible in the report next to the allocatable-based tables
+func reportReservedResources(nodeStatuses []simulator.NodeStatus) {
+	type reservedLine struct {
+		node     string
+		resource corev1.ResourceName
+		capacity string
+		reserved string
+		free     string
+	}
+	var lines []reservedLine
+	for _, status := range nodeStatuses {
+		node := status.Node
+		var names []string
+		for name := range node.Status.Capacity {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			capacity := node.Status.Capacity[corev1.ResourceName(name)]
+			allocatable, exist := node.Status.Allocatable[corev1.ResourceName(name)]
+			if !exist || capacity.Cmp(allocatable) <= 0 {
+				continue
+			}
+			reserved := capacity.DeepCopy()
+			reserved.Sub(allocatable)
+			lines = append(lines, reservedLine{
+				node:     node.Name,
+				resource: corev1.ResourceName(name),
+				capacity: capacity.String(),
+				reserved: reserved.String(),
+				free:     allocatable.String(),
+			})
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	pterm.FgYellow.Println("Reserved Capacity")
+	for _, line := range lines {
+		pterm.FgLightWhite.Printf("%s: %s capacity %s, %s reserved, %s allocatable\n", line.node, line.resource, line.capacity, line.reserved, line.free)
+	}
+	pterm.FgYellow.Println()
+}
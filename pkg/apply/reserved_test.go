@@ -0,0 +1,70 @@
+package apply
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/alibaba/open-simulator/pkg/api/v1alpha1"
+	"github.com/alibaba/open-simulator/pkg/test"
+)
+
+func TestApplyReservedResources(t *testing.T) {
+	node := test.MakeFakeNode("template-node", "8", "16Gi")
+	template := v1alpha1.NodeTemplate{
+		KubeReserved:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+		SystemReserved: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")},
+	}
+	if err := applyReservedResources(node, template); err != nil {
+		t.Fatalf("applyReservedResources() error = %v", err)
+	}
+	// allocatable derives from capacity minus both reservations, capacity stays put
+	if node.Status.Capacity.Cpu().String() != "8" {
+		t.Errorf("capacity cpu = %s, want it untouched", node.Status.Capacity.Cpu())
+	}
+	if node.Status.Allocatable.Cpu().MilliValue() != 7500 {
+		t.Errorf("allocatable cpu = %s, want 7500m after both reservations", node.Status.Allocatable.Cpu())
+	}
+	if node.Status.Allocatable.Memory().String() != "15Gi" {
+		t.Errorf("allocatable memory = %s, want 15Gi", node.Status.Allocatable.Memory())
+	}
+
+	overdrawn := v1alpha1.NodeTemplate{
+		KubeReserved: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("9")},
+	}
+	if err := applyReservedResources(test.MakeFakeNode("small-node", "8", "16Gi"), overdrawn); err == nil {
+		t.Errorf("applyReservedResources() expected an error when the reservation exceeds capacity")
+	}
+
+	unknown := v1alpha1.NodeTemplate{
+		KubeReserved: corev1.ResourceList{"nvidia.com/gpu": resource.MustParse("1")},
+	}
+	if err := applyReservedResources(test.MakeFakeNode("cpu-node", "8", "16Gi"), unknown); err == nil {
+		t.Errorf("applyReservedResources() expected an error for a resource the node has no capacity for")
+	}
+
+	// a template without reservations leaves the node alone
+	untouched := test.MakeFakeNode("plain-node", "8", "16Gi")
+	if err := applyReservedResources(untouched, v1alpha1.NodeTemplate{}); err != nil {
+		t.Fatalf("applyReservedResources() error = %v", err)
+	}
+	if untouched.Status.Allocatable.Cpu().String() != "8" {
+		t.Errorf("allocatable cpu = %s, want the file's value kept", untouched.Status.Allocatable.Cpu())
+	}
+}
+
+func TestParseNewNodeReservedResources(t *testing.T) {
+	_, templates, err := parseNewNode(json.RawMessage(`[{"path":"./small","kubeReserved":{"cpu":"200m"},"systemReserved":{"memory":"250Mi"}}]`))
+	if err != nil {
+		t.Fatalf("parseNewNode() error = %v", err)
+	}
+	if templates[0].KubeReserved.Cpu().String() != "200m" || templates[0].SystemReserved.Memory().String() != "250Mi" {
+		t.Errorf("parseNewNode() template = %+v, want the reservations parsed", templates[0])
+	}
+
+	if _, _, err := parseNewNode(json.RawMessage(`[{"path":"./small","kubeReserved":{"cpu":"-1"}}]`)); err == nil {
+		t.Errorf("parseNewNode() expected an error for a negative reservation")
+	}
+}
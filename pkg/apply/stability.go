@@ -0,0 +1,104 @@
+package apply
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pterm/pterm"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+// podStability is the placement summary of one pod across the repeated runs
+type podStability struct {
+	pod string
+	// node is the node the pod landed on most often
+	node string
+	// score is the share of runs the pod landed on that node, in percent
+	score int
+}
+
+// reportPlacementStability replays the whole simulation --repeat times and scores every
+// app pod by how often it landed on its most-common node. Pods below 100% have
+// placements that are sensitive to ordering or scoring ties, which undermines
+// reproducible planning, so they are listed explicitly.
+func (applier *Applier) reportPlacementStability(cluster simulator.ResourceTypes, selectedApps []simulator.AppResource) error {
+	// pod key -> node name -> number of runs the pod landed there
+	placements := make(map[string]map[string]int)
+	for run := 0; run < applier.repeat; run++ {
+		result, err := simulator.Simulate(cluster, selectedApps,
+			simulator.DisablePTerm(true),
+			simulator.WithKubeConfig(applier.cluster.KubeConfig),
+			simulator.WithNamespaceNodeSelectors(applier.namespaceNodeSelectors),
+			simulator.WithPodOverhead(applier.podOverhead),
+			simulator.WithIgnorePendingPods(applier.ignorePendingPods),
+			simulator.WithIgnorePodsSelector(applier.ignorePods),
+			simulator.WithUtilizationCaps(applier.utilizationCaps),
+			simulator.WithPrioritySort(applier.prioritySort),
+			simulator.WithVGSelectionPolicy(applier.vgSelectionPolicy), simulator.WithAppRequestMultipliers(applier.requestMultipliers))
+		if err != nil {
+			return err
+		}
+		for _, status := range result.NodeStatus {
+			for _, pod := range status.Pods {
+				if _, exist := pod.Labels[simontype.LabelAppName]; !exist {
+					continue
+				}
+				key := pod.Annotations[simontype.AnnoPodStableName]
+				if key == "" {
+					key = pod.Name
+				}
+				key = fmt.Sprintf("%s/%s", pod.Namespace, key)
+				if placements[key] == nil {
+					placements[key] = make(map[string]int)
+				}
+				placements[key][status.Node.Name]++
+			}
+		}
+	}
+
+	// a pod unscheduled in some runs also counts as unstable, so the score is always
+	// computed against the number of runs
+	var unstable []podStability
+	for podKey, nodes := range placements {
+		best, bestNode := 0, ""
+		for node, count := range nodes {
+			if count > best {
+				best, bestNode = count, node
+			}
+		}
+		score := best * 100 / applier.repeat
+		if score < 100 {
+			unstable = append(unstable, podStability{pod: podKey, node: bestNode, score: score})
+		}
+	}
+	sort.Slice(unstable, func(i, j int) bool {
+		if unstable[i].score != unstable[j].score {
+			return unstable[i].score < unstable[j].score
+		}
+		return unstable[i].pod < unstable[j].pod
+	})
+
+	pterm.FgYellow.Printf("Placement Stability (%d runs)\n", applier.repeat)
+	if len(unstable) == 0 {
+		pterm.Printf("every pod kept the same placement in all %d runs\n\n", applier.repeat)
+		return nil
+	}
+	stabilityTable := pterm.DefaultTable.WithHasHeader()
+	stabilityTableData := [][]string{
+		{"Pod", "Most Common Node", "Stability"},
+	}
+	for _, item := range unstable {
+		stabilityTableData = append(stabilityTableData, []string{
+			item.pod,
+			item.node,
+			fmt.Sprintf("%d%%", item.score),
+		})
+	}
+	if err := stabilityTable.WithData(stabilityTableData).Render(); err != nil {
+		return fmt.Errorf("fail to render stability table: %s ", err.Error())
+	}
+	pterm.FgYellow.Println()
+	return nil
+}
@@ -0,0 +1,125 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+// StructuredReportVersion names the schema of the structured report, so the schema can
+// evolve without breaking parsers that pin a version
+const StructuredReportVersion = "v1"
+
+// StructuredReport is the machine-readable scheduling report emitted by the json and
+// yaml output modes, shaped for CI pipelines and dashboards
+type StructuredReport struct {
+	Version         string                 `json:"version"`
+	Succeeded       bool                   `json:"succeeded"`
+	AddedNodes      int                    `json:"addedNodes"`
+	Apps            []string               `json:"apps,omitempty"`
+	Nodes           []StructuredNode       `json:"nodes,omitempty"`
+	UnscheduledPods []UnscheduledPodReport `json:"unscheduledPods,omitempty"`
+}
+
+// StructuredNode is one node of the simulated cluster with its requested fractions
+type StructuredNode struct {
+	Name              string          `json:"name"`
+	NewNode           bool            `json:"newNode,omitempty"`
+	AllocatableCpu    string          `json:"allocatableCpu"`
+	AllocatableMemory string          `json:"allocatableMemory"`
+	RequestedCpu      string          `json:"requestedCpu"`
+	RequestedMemory   string          `json:"requestedMemory"`
+	CpuFraction       int64           `json:"cpuFraction"`
+	MemoryFraction    int64           `json:"memoryFraction"`
+	Pods              []StructuredPod `json:"pods,omitempty"`
+}
+
+// StructuredPod is one pod placement with the requests the scheduler accounted
+type StructuredPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	App       string `json:"app,omitempty"`
+	Cpu       string `json:"cpu"`
+	Memory    string `json:"memory"`
+}
+
+// buildStructuredReport converts the simulation result into the versioned report
+func buildStructuredReport(result *simulator.SimulateResult, appNames []string, addedNodes int, succeeded bool) StructuredReport {
+	report := StructuredReport{
+		Version:    StructuredReportVersion,
+		Succeeded:  succeeded,
+		AddedNodes: addedNodes,
+		Apps:       appNames,
+	}
+	for _, status := range result.NodeStatus {
+		node := status.Node
+		_, newNode := node.Labels[simontype.LabelNewNode]
+		structuredNode := StructuredNode{
+			Name:              node.Name,
+			NewNode:           newNode,
+			AllocatableCpu:    node.Status.Allocatable.Cpu().String(),
+			AllocatableMemory: node.Status.Allocatable.Memory().String(),
+		}
+		var requestedCpu, requestedMemory int64
+		for _, pod := range status.Pods {
+			podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+			requestedCpu += podReqs.Cpu().MilliValue()
+			requestedMemory += podReqs.Memory().Value()
+			structuredNode.Pods = append(structuredNode.Pods, StructuredPod{
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+				App:       pod.Labels[simontype.LabelAppName],
+				Cpu:       podReqs.Cpu().String(),
+				Memory:    podReqs.Memory().String(),
+			})
+		}
+		requestedCpuQuantity := *resource.NewMilliQuantity(requestedCpu, resource.DecimalSI)
+		requestedMemoryQuantity := *resource.NewQuantity(requestedMemory, resource.BinarySI)
+		structuredNode.RequestedCpu = requestedCpuQuantity.String()
+		structuredNode.RequestedMemory = requestedMemoryQuantity.String()
+		structuredNode.CpuFraction = fraction(requestedCpu, node.Status.Allocatable.Cpu().MilliValue())
+		structuredNode.MemoryFraction = fraction(requestedMemory, node.Status.Allocatable.Memory().Value())
+		report.Nodes = append(report.Nodes, structuredNode)
+	}
+	for _, unscheduledPod := range result.UnscheduledPods {
+		report.UnscheduledPods = append(report.UnscheduledPods, UnscheduledPodReport{
+			Namespace: unscheduledPod.Pod.Namespace,
+			Name:      unscheduledPod.Pod.Name,
+			Reason:    unscheduledPod.Reason,
+		})
+	}
+	return report
+}
+
+// writeStructuredOutput marshals the report in the selected output mode and writes it to
+// stdout, or to the output file when one is configured, so the result can be piped
+func (applier *Applier) writeStructuredOutput(result *simulator.SimulateResult, appNames []string, addedNodes int, succeeded bool) error {
+	report := buildStructuredReport(result, appNames, addedNodes, succeeded)
+	var content []byte
+	var err error
+	switch applier.outputMode {
+	case OutputModeJSON:
+		content, err = json.MarshalIndent(report, "", "  ")
+	case OutputModeYAML:
+		content, err = yaml.Marshal(report)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal structured report: %v ", err)
+	}
+	var target io.Writer = os.Stdout
+	if applier.outputFile != nil {
+		target = applier.outputFile
+	}
+	if _, err := fmt.Fprintln(target, string(content)); err != nil {
+		return fmt.Errorf("failed to write structured report: %v ", err)
+	}
+	return nil
+}
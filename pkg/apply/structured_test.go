@@ -0,0 +1,62 @@
+package apply
+
+import (
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+func TestBuildStructuredReport(t *testing.T) {
+	node := test.MakeFakeNode("node-1", "8", "16Gi")
+	pod := test.MakeFakePod("pod-1", "default", "2", "4Gi", test.WithPodNodeName("node-1"))
+	pod.Labels = map[string]string{simontype.LabelAppName: "demo-app"}
+	result := &simulator.SimulateResult{
+		NodeStatus: []simulator.NodeStatus{
+			{Node: node, Pods: []*corev1.Pod{pod}},
+		},
+		UnscheduledPods: []simulator.UnscheduledPod{
+			{Pod: test.MakeFakePod("pod-2", "default", "32", "4Gi"), Reason: "Insufficient cpu"},
+		},
+	}
+
+	report := buildStructuredReport(result, []string{"demo-app"}, 1, false)
+	if report.Version != StructuredReportVersion {
+		t.Fatalf("expect version %s, got %s", StructuredReportVersion, report.Version)
+	}
+	if report.Succeeded || report.AddedNodes != 1 {
+		t.Fatalf("unexpected outcome fields: succeeded %v, added nodes %d", report.Succeeded, report.AddedNodes)
+	}
+	if len(report.Nodes) != 1 {
+		t.Fatalf("expect 1 node, got %d", len(report.Nodes))
+	}
+	reportedNode := report.Nodes[0]
+	if reportedNode.CpuFraction != 25 || reportedNode.MemoryFraction != 25 {
+		t.Fatalf("expect 25%% cpu and memory fractions, got %d and %d", reportedNode.CpuFraction, reportedNode.MemoryFraction)
+	}
+	if reportedNode.RequestedCpu != "2" || reportedNode.RequestedMemory != "4Gi" {
+		t.Fatalf("unexpected requested resources: %s cpu, %s memory", reportedNode.RequestedCpu, reportedNode.RequestedMemory)
+	}
+	if len(reportedNode.Pods) != 1 || reportedNode.Pods[0].App != "demo-app" {
+		t.Fatalf("expect 1 placement of demo-app, got %+v", reportedNode.Pods)
+	}
+	if len(report.UnscheduledPods) != 1 || report.UnscheduledPods[0].Reason != "Insufficient cpu" {
+		t.Fatalf("expect 1 unscheduled pod with its reason, got %+v", report.UnscheduledPods)
+	}
+
+	content, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("failed to marshal report: %v", err)
+	}
+	var parsed StructuredReport
+	if err := json.Unmarshal(content, &parsed); err != nil {
+		t.Fatalf("failed to parse marshaled report: %v", err)
+	}
+	if parsed.Version != StructuredReportVersion || len(parsed.Nodes) != 1 {
+		t.Fatalf("report did not round-trip: %+v", parsed)
+	}
+}
@@ -0,0 +1,31 @@
+package apply
+
+import (
+	"sort"
+
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// reportSystemOverhead prints the capacity reserved on every node for system pods
+// (kube-proxy, CNI, node-local DNS), which no app manifest declares
+func reportSystemOverhead(nodeStatuses []simulator.NodeStatus, systemOverhead corev1.ResourceList) {
+	pterm.FgYellow.Println("System Overhead")
+	var names []string
+	for name := range systemOverhead {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		perNode := systemOverhead[corev1.ResourceName(name)]
+		total := resource.NewQuantity(0, perNode.Format)
+		for i := 0; i < len(nodeStatuses); i++ {
+			total.Add(perNode)
+		}
+		pterm.FgLightWhite.Printf("%s: %s per node, %s in total across %d node(s)\n", name, perNode.String(), total.String(), len(nodeStatuses))
+	}
+	pterm.FgYellow.Println()
+}
@@ -0,0 +1,98 @@
+package apply
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pquerna/ffjson/ffjson"
+	"github.com/pterm/pterm"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+// reportVolumePlacement lists which VG each open-local volume landed in, read from the
+// annotation the local plugin stamps on bound pods. Nothing is printed when no pod
+// requested local storage.
+func reportVolumePlacement(nodeStatuses []simulator.NodeStatus) error {
+	var placementTableData [][]string
+	for _, status := range nodeStatuses {
+		for _, pod := range status.Pods {
+			assignmentsStr, exist := pod.Annotations[simontype.AnnoPodVolumeVGs]
+			if !exist {
+				continue
+			}
+			assignments := make(map[string]string)
+			if err := ffjson.Unmarshal([]byte(assignmentsStr), &assignments); err != nil {
+				return fmt.Errorf("failed to unmarshal volume placement of pod %s/%s: %v ", pod.Namespace, pod.Name, err)
+			}
+			for pvcName, vgName := range assignments {
+				placementTableData = append(placementTableData, []string{
+					status.Node.Name,
+					fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+					pvcName,
+					vgName,
+				})
+			}
+		}
+	}
+	if len(placementTableData) == 0 {
+		return nil
+	}
+	sort.Slice(placementTableData, func(i, j int) bool {
+		for k := range placementTableData[i] {
+			if placementTableData[i][k] != placementTableData[j][k] {
+				return placementTableData[i][k] < placementTableData[j][k]
+			}
+		}
+		return false
+	})
+
+	pterm.FgYellow.Println("Volume Placement")
+	placementTable := pterm.DefaultTable.WithHasHeader()
+	placementTableData = append([][]string{{"Node", "Pod", "PVC", "VG"}}, placementTableData...)
+	if err := placementTable.WithData(placementTableData).Render(); err != nil {
+		return fmt.Errorf("fail to render volume placement table: %s ", err.Error())
+	}
+	pterm.FgYellow.Println()
+	return nil
+}
+
+// reportPVCBindings lists how every persistent volume claim was bound and errors when
+// any claim stayed unbound, so an unsatisfiable stateful app fails the run. Nothing is
+// printed when the cluster and the apps declare no claims.
+func reportPVCBindings(bindings []simulator.PVCBinding) error {
+	if len(bindings) == 0 {
+		return nil
+	}
+	unbound := 0
+	bindingTableData := [][]string{{"PVC", "Storage Class", "Requested", "Volume", "Status"}}
+	for _, binding := range bindings {
+		status := "Bound"
+		if binding.Provisioned {
+			status = "Provisioned"
+		}
+		if binding.Volume == "" {
+			status = fmt.Sprintf("Unbound: %s", binding.Reason)
+			unbound++
+		}
+		bindingTableData = append(bindingTableData, []string{
+			fmt.Sprintf("%s/%s", binding.Namespace, binding.Name),
+			binding.StorageClass,
+			binding.Requested,
+			binding.Volume,
+			status,
+		})
+	}
+
+	pterm.FgYellow.Println("PVC Bindings")
+	bindingTable := pterm.DefaultTable.WithHasHeader()
+	if err := bindingTable.WithData(bindingTableData).Render(); err != nil {
+		return fmt.Errorf("fail to render pvc binding table: %s ", err.Error())
+	}
+	pterm.FgYellow.Println()
+	if unbound != 0 {
+		return fmt.Errorf("%d persistent volume claim(s) can not be bound ", unbound)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package apply
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pterm/pterm"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// reportWaveAnalysis replays the rollout wave by wave on the final cluster and prints
+// the node usage and utilization after each wave, showing which wave consumes the
+// capacity and would trigger the scale-out
+func (applier *Applier) reportWaveAnalysis(cluster simulator.ResourceTypes, selectedApps []simulator.AppResource) error {
+	appByName := make(map[string]simulator.AppResource, len(selectedApps))
+	for _, app := range selectedApps {
+		appByName[app.Name] = app
+	}
+
+	assigned := make(map[string]bool)
+	var waves [][]simulator.AppResource
+	for _, wave := range applier.waves {
+		var group []simulator.AppResource
+		for _, name := range wave {
+			app, exist := appByName[name]
+			if !exist {
+				return fmt.Errorf("wave lists unknown app %s ", name)
+			}
+			if assigned[name] {
+				return fmt.Errorf("app %s is listed in more than one wave ", name)
+			}
+			assigned[name] = true
+			group = append(group, app)
+		}
+		waves = append(waves, group)
+	}
+	// apps outside the configured waves roll out together at the end
+	var rest []simulator.AppResource
+	for _, app := range selectedApps {
+		if !assigned[app.Name] {
+			rest = append(rest, app)
+		}
+	}
+	if len(rest) != 0 {
+		waves = append(waves, rest)
+	}
+
+	waveResults, err := simulator.SimulateWaves(cluster, waves,
+		simulator.DisablePTerm(true),
+		simulator.WithKubeConfig(applier.cluster.KubeConfig),
+		simulator.WithNamespaceNodeSelectors(applier.namespaceNodeSelectors),
+		simulator.WithPodOverhead(applier.podOverhead),
+		simulator.WithIgnorePendingPods(applier.ignorePendingPods),
+		simulator.WithIgnorePodsSelector(applier.ignorePods),
+		simulator.WithUtilizationCaps(applier.utilizationCaps),
+		simulator.WithPrioritySort(applier.prioritySort),
+		simulator.WithVGSelectionPolicy(applier.vgSelectionPolicy), simulator.WithAppRequestMultipliers(applier.requestMultipliers))
+	if err != nil {
+		return err
+	}
+
+	pterm.FgYellow.Println("Rollout Waves")
+	waveTable := pterm.DefaultTable.WithHasHeader()
+	waveTableData := [][]string{
+		{"Wave", "Apps", "Unscheduled", "Nodes Used", "CPU Requests", "Memory Requests"},
+	}
+	for i, waveResult := range waveResults {
+		nodesUsed := 0
+		for _, status := range waveResult.NodeStatus {
+			if len(status.Pods) != 0 {
+				nodesUsed++
+			}
+		}
+		totalAllocatable, totalUsed, _, err := clusterResourceTotals(waveResult.NodeStatus)
+		if err != nil {
+			return err
+		}
+		cpuFraction := float64(totalUsed[corev1.ResourceCPU].MilliValue()) / float64(totalAllocatable[corev1.ResourceCPU].MilliValue()) * 100
+		memoryFraction := float64(totalUsed[corev1.ResourceMemory].Value()) / float64(totalAllocatable[corev1.ResourceMemory].Value()) * 100
+		waveTableData = append(waveTableData, []string{
+			strconv.Itoa(i + 1),
+			strings.Join(waveResult.Apps, "\n"),
+			strconv.Itoa(len(waveResult.UnscheduledPods)),
+			strconv.Itoa(nodesUsed),
+			fmt.Sprintf("%s(%d%%)", formatQuantity(totalUsed[corev1.ResourceCPU], corev1.ResourceCPU, applier.displayUnits), int64(cpuFraction)),
+			fmt.Sprintf("%s(%d%%)", formatQuantity(totalUsed[corev1.ResourceMemory], corev1.ResourceMemory, applier.displayUnits), int64(memoryFraction)),
+		})
+	}
+	if err := waveTable.WithData(waveTableData).Render(); err != nil {
+		return fmt.Errorf("fail to render wave table: %s ", err.Error())
+	}
+	pterm.FgYellow.Println()
+	return nil
+}
@@ -0,0 +1,120 @@
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/utils"
+)
+
+const (
+	webhookTimeout    = 10 * time.Second
+	webhookMaxRetries = 3
+)
+
+// SimulationReport is the structured result of one simulation run, shaped for machine
+// consumers such as webhooks and report files
+type SimulationReport struct {
+	Succeeded       bool                   `json:"succeeded"`
+	AddedNodes      int                    `json:"addedNodes"`
+	Apps            []string               `json:"apps,omitempty"`
+	Nodes           []NodeReport           `json:"nodes,omitempty"`
+	UnscheduledPods []UnscheduledPodReport `json:"unscheduledPods,omitempty"`
+}
+
+// NodeReport summarizes one node of the simulated cluster
+type NodeReport struct {
+	Name string `json:"name"`
+	Pods int    `json:"pods"`
+}
+
+// UnscheduledPodReport names one pod the scheduler could not place and why
+type UnscheduledPodReport struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+// buildSimulationReport converts the simulation result into the structured report
+func buildSimulationReport(result *simulator.SimulateResult, appNames []string, addedNodes int, succeeded bool) SimulationReport {
+	report := SimulationReport{
+		Succeeded:  succeeded,
+		AddedNodes: addedNodes,
+		Apps:       appNames,
+	}
+	for _, nodeStatus := range result.NodeStatus {
+		report.Nodes = append(report.Nodes, NodeReport{
+			Name: nodeStatus.Node.Name,
+			Pods: len(nodeStatus.Pods),
+		})
+	}
+	for _, unscheduledPod := range result.UnscheduledPods {
+		report.UnscheduledPods = append(report.UnscheduledPods, UnscheduledPodReport{
+			Namespace: unscheduledPod.Pod.Namespace,
+			Name:      unscheduledPod.Pod.Name,
+			Reason:    unscheduledPod.Reason,
+		})
+	}
+	return report
+}
+
+// parseWebhookHeaders parses "Name=Value" items into HTTP headers
+func parseWebhookHeaders(items []string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, item := range items {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid webhook header %s, expect Name=Value ", item)
+		}
+		headers[parts[0]] = parts[1]
+	}
+	return headers, nil
+}
+
+// postWebhook POSTs the report as JSON to the given endpoint. Transient failures, i.e.
+// network errors and 5xx responses, are retried a few times with a linear backoff;
+// anything else fails immediately. A failed post only produces a warning so a flaky
+// dashboard cannot fail a run outside strict mode.
+func postWebhook(url string, headers map[string]string, report SimulationReport) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		utils.AddWarning("webhook", "failed to marshal simulation report: %v", err)
+		return
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			utils.AddWarning("webhook", "failed to build webhook request: %v", err)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 300 {
+				return
+			}
+			if resp.StatusCode < 500 {
+				utils.AddWarning("webhook", "webhook %s rejected the report with status %s", url, resp.Status)
+				return
+			}
+			err = fmt.Errorf("status %s", resp.Status)
+		}
+		if attempt >= webhookMaxRetries {
+			utils.AddWarning("webhook", "failed to post the report to %s after %d attempt(s): %v", url, attempt, err)
+			return
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
@@ -0,0 +1,121 @@
+package chart
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// renderCache remembers rendered chart manifests so repeated simulations of the same
+// chart skip re-rendering, in memory within a run and on disk across runs. An entry is
+// keyed by the release name and chart path and invalidated when a file below the chart
+// path changes or the render values differ.
+type renderCache struct {
+	mu      sync.Mutex
+	entries map[string]renderCacheEntry
+	// diskDisabled turns the cache into a purely in-memory one, the --no-chart-cache
+	// escape hatch for debugging stale renders
+	diskDisabled bool
+}
+
+type renderCacheEntry struct {
+	Fingerprint string   `json:"fingerprint"`
+	Content     []string `json:"content"`
+}
+
+var defaultRenderCache = renderCache{entries: make(map[string]renderCacheEntry)}
+
+// SetDiskCacheDisabled toggles the on-disk layer of the render cache; rendering within
+// one run still reuses the in-memory entries
+func SetDiskCacheDisabled(disabled bool) {
+	defaultRenderCache.mu.Lock()
+	defer defaultRenderCache.mu.Unlock()
+	defaultRenderCache.diskDisabled = disabled
+}
+
+// diskCacheDir is where rendered manifests persist between runs; the user cache dir
+// keeps them out of the way of /tmp cleaners where possible
+func diskCacheDir() string {
+	if cacheDir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(cacheDir, "open-simulator", "chart-render")
+	}
+	return filepath.Join(os.TempDir(), "open-simulator-chart-render")
+}
+
+// diskCachePath names the cache file of one entry after the hash of its key, so release
+// names and chart paths need no escaping
+func diskCachePath(key string) string {
+	return filepath.Join(diskCacheDir(), fmt.Sprintf("%x.json", sha256.Sum256([]byte(key))))
+}
+
+// chartFingerprint combines the newest modification time below chartPath with a hash of
+// the render values, so both file edits and value changes invalidate a cache entry
+func chartFingerprint(chartPath string, vals map[string]interface{}) (string, error) {
+	var newest int64
+	err := filepath.Walk(chartPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if modTime := info.ModTime().UnixNano(); modTime > newest {
+			newest = modTime
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	valsJSON, err := json.Marshal(vals)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%x", newest, sha256.Sum256(valsJSON)), nil
+}
+
+func (cache *renderCache) get(key, fingerprint string) ([]string, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry, exist := cache.entries[key]
+	if exist && entry.Fingerprint == fingerprint {
+		return entry.Content, true
+	}
+	if cache.diskDisabled {
+		return nil, false
+	}
+	// fall back to the entry a previous run left on disk; a stale or unreadable file
+	// is the same as a miss
+	raw, err := ioutil.ReadFile(diskCachePath(key))
+	if err != nil {
+		return nil, false
+	}
+	if err := json.Unmarshal(raw, &entry); err != nil || entry.Fingerprint != fingerprint {
+		return nil, false
+	}
+	cache.entries[key] = entry
+	return entry.Content, true
+}
+
+func (cache *renderCache) put(key, fingerprint string, content []string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry := renderCacheEntry{
+		Fingerprint: fingerprint,
+		Content:     content,
+	}
+	cache.entries[key] = entry
+	if cache.diskDisabled {
+		return
+	}
+	// persisting is best effort, the next run simply re-renders when it fails
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(diskCacheDir(), 0755); err != nil {
+		return
+	}
+	_ = ioutil.WriteFile(diskCachePath(key), raw, 0644)
+}
@@ -0,0 +1,35 @@
+package chart
+
+import (
+	"testing"
+)
+
+func TestRenderCacheDiskLayer(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	first := renderCache{entries: make(map[string]renderCacheEntry)}
+	first.put("demo\x00./chart", "fingerprint-1", []string{"kind: ConfigMap"})
+
+	// a fresh cache, as a new run would start with, finds the persisted entry
+	second := renderCache{entries: make(map[string]renderCacheEntry)}
+	content, hit := second.get("demo\x00./chart", "fingerprint-1")
+	if !hit || len(content) != 1 || content[0] != "kind: ConfigMap" {
+		t.Errorf("get() = %v, %v, want the entry persisted by the previous run", content, hit)
+	}
+
+	// a changed fingerprint invalidates the disk entry
+	if _, hit := second.get("demo\x00./chart", "fingerprint-2"); hit {
+		t.Errorf("get() hit with a stale fingerprint")
+	}
+
+	// with the escape hatch the disk layer is neither read nor written
+	disabled := renderCache{entries: make(map[string]renderCacheEntry), diskDisabled: true}
+	if _, hit := disabled.get("demo\x00./chart", "fingerprint-1"); hit {
+		t.Errorf("get() hit from disk although the disk cache is disabled")
+	}
+	disabled.put("other\x00./chart", "fingerprint-1", []string{"kind: Secret"})
+	fresh := renderCache{entries: make(map[string]renderCacheEntry)}
+	if _, hit := fresh.get("other\x00./chart", "fingerprint-1"); hit {
+		t.Errorf("put() persisted to disk although the disk cache is disabled")
+	}
+}
@@ -12,10 +12,27 @@ import (
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/engine"
 	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
 )
 
-// ProcessChart parses chart to /tmp/charts
-func ProcessChart(name string, chartPath string) ([]string, error) {
+// ProcessChart parses chart to /tmp/charts. Values files merge in order and set
+// overrides take precedence over them, matching helm's own semantics.
+func ProcessChart(name string, chartPath string, valuesFiles []string, setValues []string) ([]string, error) {
+	vals, err := buildValues(valuesFiles, setValues)
+	if err != nil {
+		return nil, err
+	}
+
+	// skip re-rendering when the chart files and values are unchanged within this run
+	cacheKey := fmt.Sprintf("%s\x00%s", name, chartPath)
+	fingerprint, err := chartFingerprint(chartPath, vals)
+	if err != nil {
+		return nil, err
+	}
+	if content, exist := defaultRenderCache.get(cacheKey, fingerprint); exist {
+		return content, nil
+	}
+
 	chartRequested, err := loader.Load(chartPath)
 	if err != nil {
 		return nil, err
@@ -26,8 +43,10 @@ func ProcessChart(name string, chartPath string) ([]string, error) {
 		return nil, err
 	}
 
-	// TODO
-	var vals map[string]interface{}
+	if err := resolveDependencies(chartRequested, chartPath); err != nil {
+		return nil, err
+	}
+
 	if err := chartutil.ProcessDependencies(chartRequested, vals); err != nil {
 		return nil, err
 	}
@@ -37,7 +56,60 @@ func ProcessChart(name string, chartPath string) ([]string, error) {
 		return nil, err
 	}
 
-	return renderResources(chartRequested, valuesToRender, true)
+	content, err := renderResources(chartRequested, valuesToRender, true)
+	if err != nil {
+		return nil, err
+	}
+	defaultRenderCache.put(cacheKey, fingerprint, content)
+	return content, nil
+}
+
+// buildValues merges the values files in order and applies the key=value overrides on
+// top, the order of precedence helm itself uses
+func buildValues(valuesFiles []string, setValues []string) (map[string]interface{}, error) {
+	vals := map[string]interface{}{}
+	for _, valuesFile := range valuesFiles {
+		fileVals, err := chartutil.ReadValuesFile(valuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read values file %s: %v", valuesFile, err)
+		}
+		vals = chartutil.CoalesceTables(fileVals.AsMap(), vals)
+	}
+	for _, setValue := range setValues {
+		if err := mergeSetValue(vals, setValue); err != nil {
+			return nil, err
+		}
+	}
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	return vals, nil
+}
+
+// mergeSetValue merges one key=value override into the values map; nested keys use the
+// dotted path form a.b.c and the value is parsed like a YAML scalar. The full --set
+// grammar lives in helm's strvals package, which is not vendored.
+func mergeSetValue(vals map[string]interface{}, setValue string) error {
+	parts := strings.SplitN(setValue, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("invalid set value %q, expect <key>=<value>", setValue)
+	}
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(parts[1]), &value); err != nil {
+		value = parts[1]
+	}
+	keys := strings.Split(parts[0], ".")
+	table := vals
+	for _, key := range keys[:len(keys)-1] {
+		subTable, ok := table[key].(map[string]interface{})
+		if !ok {
+			subTable = map[string]interface{}{}
+			table[key] = subTable
+		}
+		table = subTable
+	}
+	table[keys[len(keys)-1]] = value
+	return nil
 }
 
 // checkIfInstallable validates if a chart can be installed
@@ -0,0 +1,49 @@
+package chart
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildValues(t *testing.T) {
+	dir := t.TempDir()
+	baseValues := filepath.Join(dir, "base.yaml")
+	envValues := filepath.Join(dir, "env.yaml")
+	if err := ioutil.WriteFile(baseValues, []byte("replicas: 1\nimage:\n  tag: stable\n"), 0644); err != nil {
+		t.Fatalf("failed to write values file: %v", err)
+	}
+	if err := ioutil.WriteFile(envValues, []byte("replicas: 3\n"), 0644); err != nil {
+		t.Fatalf("failed to write values file: %v", err)
+	}
+
+	// later files override earlier ones, set overrides win over both
+	vals, err := buildValues([]string{baseValues, envValues}, []string{"image.tag=canary", "resources.requests.cpu=500m"})
+	if err != nil {
+		t.Fatalf("failed to build values: %v", err)
+	}
+	if vals["replicas"] != float64(3) {
+		t.Fatalf("expect the later values file to win, got %v", vals["replicas"])
+	}
+	image, ok := vals["image"].(map[string]interface{})
+	if !ok || image["tag"] != "canary" {
+		t.Fatalf("expect the set override to win, got %v", vals["image"])
+	}
+	resources, ok := vals["resources"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expect a nested table from the dotted key, got %v", vals["resources"])
+	}
+	requests := resources["requests"].(map[string]interface{})
+	if requests["cpu"] != "500m" {
+		t.Fatalf("expect cpu 500m, got %v", requests["cpu"])
+	}
+
+	if _, err := buildValues(nil, []string{"no-equals-sign"}); err == nil {
+		t.Fatal("expect an error for a malformed set value")
+	}
+
+	vals, err = buildValues(nil, nil)
+	if err != nil || vals != nil {
+		t.Fatalf("expect nil values without overrides, got %v, %v", vals, err)
+	}
+}
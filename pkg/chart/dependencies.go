@@ -0,0 +1,196 @@
+package chart
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"sigs.k8s.io/yaml"
+)
+
+// resolveDependencies makes sure every dependency declared in Chart.yaml is loaded
+// before the chart is templated, the job of helm dependency build. Dependencies already
+// vendored into charts/ come in with the loader; missing ones are resolved from their
+// repository: file:// paths relative to the chart, oci:// registries via the OCI pull,
+// and http(s) chart repositories via their index.yaml. Charts fetched from a repository
+// are persisted into charts/ when the chart is a directory, so later runs render
+// offline. Helm's downloader manager is not vendored, hence the hand-rolled resolution.
+func resolveDependencies(ch *chart.Chart, chartPath string) error {
+	if len(ch.Metadata.Dependencies) == 0 {
+		return nil
+	}
+	loaded := make(map[string]bool)
+	for _, dep := range ch.Dependencies() {
+		loaded[dep.Name()] = true
+	}
+	info, err := os.Stat(chartPath)
+	chartIsDir := err == nil && info.IsDir()
+
+	for _, dep := range ch.Metadata.Dependencies {
+		if loaded[dep.Name] {
+			continue
+		}
+		subChart, err := resolveDependency(dep, chartPath, chartIsDir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve dependency %s of chart %s: %v; run 'helm dependency build' to vendor it into charts/ for offline use", dep.Name, ch.Name(), err)
+		}
+		if !dependencyVersionMatches(dep.Version, subChart.Metadata.Version) {
+			return fmt.Errorf("dependency %s of chart %s resolved to version %s, which does not satisfy the declared constraint %s", dep.Name, ch.Name(), subChart.Metadata.Version, dep.Version)
+		}
+		ch.AddDependency(subChart)
+		loaded[dep.Name] = true
+	}
+	return nil
+}
+
+// resolveDependency loads one missing dependency from wherever its repository points
+func resolveDependency(dep *chart.Dependency, chartPath string, chartIsDir bool) (*chart.Chart, error) {
+	switch {
+	case strings.HasPrefix(dep.Repository, "file://"):
+		if !chartIsDir {
+			return nil, fmt.Errorf("local repository %s cannot be resolved relative to a chart archive", dep.Repository)
+		}
+		return loader.Load(filepath.Join(chartPath, strings.TrimPrefix(dep.Repository, "file://")))
+	case IsOCIReference(dep.Repository):
+		reference := strings.TrimSuffix(dep.Repository, "/") + "/" + dep.Name
+		archive, cleanup, err := pullOCIChart(reference, dep.Version)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		return loadAndPersistArchive(archive, dep, chartPath, chartIsDir)
+	case strings.HasPrefix(dep.Repository, "http://"), strings.HasPrefix(dep.Repository, "https://"):
+		archive, cleanup, err := pullRepositoryChart(dep)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		return loadAndPersistArchive(archive, dep, chartPath, chartIsDir)
+	case dep.Repository == "":
+		return nil, fmt.Errorf("no repository declared and the chart is not vendored in charts/")
+	}
+	return nil, fmt.Errorf("unsupported repository %s, expect file://, oci:// or http(s)://", dep.Repository)
+}
+
+// loadAndPersistArchive loads a downloaded chart archive and copies it into the charts/
+// directory of the parent so the next run does not hit the network again
+func loadAndPersistArchive(archive string, dep *chart.Dependency, chartPath string, chartIsDir bool) (*chart.Chart, error) {
+	subChart, err := loader.Load(archive)
+	if err != nil {
+		return nil, err
+	}
+	if !chartIsDir {
+		return subChart, nil
+	}
+	blob, err := ioutil.ReadFile(archive)
+	if err != nil {
+		return subChart, nil
+	}
+	chartsDir := filepath.Join(chartPath, "charts")
+	if err := os.MkdirAll(chartsDir, 0755); err != nil {
+		return subChart, nil
+	}
+	target := filepath.Join(chartsDir, fmt.Sprintf("%s-%s.tgz", subChart.Name(), subChart.Metadata.Version))
+	// best effort, a read-only chart dir just means re-downloading next run
+	_ = ioutil.WriteFile(target, blob, 0644)
+	return subChart, nil
+}
+
+// pullRepositoryChart resolves a dependency against a classic http(s) chart repository:
+// the repository index lists the available versions with their download URLs
+func pullRepositoryChart(dep *chart.Dependency) (string, func(), error) {
+	repoURL := strings.TrimSuffix(dep.Repository, "/")
+	indexBody, err := fetchRepositoryFile(repoURL + "/index.yaml")
+	if err != nil {
+		return "", nil, err
+	}
+	var index struct {
+		Entries map[string][]struct {
+			Version string   `json:"version"`
+			URLs    []string `json:"urls"`
+		} `json:"entries"`
+	}
+	if err := yaml.Unmarshal(indexBody, &index); err != nil {
+		return "", nil, fmt.Errorf("failed to parse repository index of %s: %v", repoURL, err)
+	}
+
+	chartURL := ""
+	var bestVersion *semver.Version
+	for _, entry := range index.Entries[dep.Name] {
+		if !dependencyVersionMatches(dep.Version, entry.Version) || len(entry.URLs) == 0 {
+			continue
+		}
+		version, err := semver.NewVersion(entry.Version)
+		if err != nil {
+			continue
+		}
+		if bestVersion == nil || version.GreaterThan(bestVersion) {
+			bestVersion = version
+			chartURL = entry.URLs[0]
+		}
+	}
+	if chartURL == "" {
+		return "", nil, fmt.Errorf("repository %s offers no version of %s matching %s", repoURL, dep.Name, dep.Version)
+	}
+	if !strings.Contains(chartURL, "://") {
+		chartURL = repoURL + "/" + chartURL
+	}
+
+	blob, err := fetchRepositoryFile(chartURL)
+	if err != nil {
+		return "", nil, err
+	}
+	tempDir, err := ioutil.TempDir("", "repo-chart-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp dir for chart %s: %v", dep.Name, err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+	archive := filepath.Join(tempDir, filepath.Base(chartURL))
+	if err := ioutil.WriteFile(archive, blob, 0644); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write chart archive %s: %v", archive, err)
+	}
+	return archive, cleanup, nil
+}
+
+// fetchRepositoryFile GETs one repository file, passing the basic credentials from the
+// environment along like the registry client does
+func fetchRepositoryFile(url string) ([]byte, error) {
+	response, err := registryGet(url, "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("repository answered %s for %s", response.Status, url)
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository response of %s: %v", url, err)
+	}
+	return body, nil
+}
+
+// dependencyVersionMatches reports whether a resolved chart version satisfies the
+// declared version constraint; a constraint that is not valid semver falls back to
+// string equality
+func dependencyVersionMatches(constraint string, version string) bool {
+	if constraint == "" {
+		return true
+	}
+	rng, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return constraint == version
+	}
+	parsed, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return rng.Check(parsed)
+}
@@ -0,0 +1,107 @@
+package chart
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeChartDir lays out a chart directory from relative paths to file contents
+func writeChartDir(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create chart dir: %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write chart file: %v", err)
+		}
+	}
+}
+
+func TestResolveLocalDependency(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	chartDir := t.TempDir()
+	writeChartDir(t, chartDir, map[string]string{
+		"Chart.yaml": "apiVersion: v2\nname: parent\nversion: 1.0.0\ndependencies:\n" +
+			"  - name: sub\n    version: 0.1.0\n    repository: file://deps/sub\n",
+		"templates/cm.yaml":          "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: parent-cm\n",
+		"deps/sub/Chart.yaml":        "apiVersion: v2\nname: sub\nversion: 0.1.0\n",
+		"deps/sub/templates/cm.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: sub-cm\n",
+	})
+
+	content, err := ProcessChart("parent", chartDir, nil, nil)
+	if err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+	if len(content) != 2 || !strings.Contains(strings.Join(content, "\n"), "sub-cm") {
+		t.Errorf("ProcessChart() rendered %v, want the parent and the file:// subchart", content)
+	}
+}
+
+func TestResolveRepositoryDependency(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	archive := makeChartArchive(t, map[string]string{
+		"sub/Chart.yaml":        "apiVersion: v2\nname: sub\nversion: 0.2.0\n",
+		"sub/templates/cm.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: sub-cm\n",
+	})
+	index := "entries:\n  sub:\n" +
+		"    - version: 0.1.0\n      urls: [sub-0.1.0.tgz]\n" +
+		"    - version: 0.2.0\n      urls: [sub-0.2.0.tgz]\n"
+	repository := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/index.yaml":
+			writer.Write([]byte(index))
+		case "/sub-0.2.0.tgz":
+			writer.Write(archive)
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+
+	chartDir := t.TempDir()
+	writeChartDir(t, chartDir, map[string]string{
+		"Chart.yaml": "apiVersion: v2\nname: parent\nversion: 1.0.0\ndependencies:\n" +
+			fmt.Sprintf("  - name: sub\n    version: \">= 0.1.0\"\n    repository: %s\n", repository.URL),
+		"templates/cm.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: parent-cm\n",
+	})
+
+	// the range constraint selects the highest matching index entry
+	content, err := ProcessChart("parent", chartDir, nil, nil)
+	if err != nil {
+		t.Fatalf("ProcessChart() error = %v", err)
+	}
+	if len(content) != 2 || !strings.Contains(strings.Join(content, "\n"), "sub-cm") {
+		t.Errorf("ProcessChart() rendered %v, want the parent and the repository subchart", content)
+	}
+	if _, err := os.Stat(filepath.Join(chartDir, "charts", "sub-0.2.0.tgz")); err != nil {
+		t.Errorf("expect the downloaded dependency persisted into charts/: %v", err)
+	}
+
+	// with the dependency vendored, the next render works offline
+	repository.Close()
+	if _, err := ProcessChart("parent", chartDir, nil, nil); err != nil {
+		t.Errorf("ProcessChart() after vendoring error = %v, want an offline render from charts/", err)
+	}
+}
+
+func TestResolveDependencyUnresolvable(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	chartDir := t.TempDir()
+	writeChartDir(t, chartDir, map[string]string{
+		"Chart.yaml": "apiVersion: v2\nname: parent\nversion: 1.0.0\ndependencies:\n" +
+			"  - name: sub\n    version: 0.1.0\n",
+		"templates/cm.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: parent-cm\n",
+	})
+
+	_, err := ProcessChart("parent", chartDir, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "helm dependency build") {
+		t.Errorf("ProcessChart() error = %v, want a clear hint at vendoring the dependency", err)
+	}
+}
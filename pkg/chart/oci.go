@@ -0,0 +1,223 @@
+package chart
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// OCIPrefix marks an AppInfo path as an OCI registry reference instead of a local
+// chart directory
+const OCIPrefix = "oci://"
+
+// helmChartLayerMediaType is the media type of the layer carrying the chart archive
+// in an OCI manifest, as pushed by helm and oras
+const helmChartLayerMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+// IsOCIReference reports whether the chart path is an oci:// registry reference
+func IsOCIReference(chartPath string) bool {
+	return strings.HasPrefix(chartPath, OCIPrefix)
+}
+
+// ProcessChartFromOCI pulls the chart behind an oci:// reference into a temp dir and
+// renders it like ProcessChart; the temp dir is removed after rendering. The version is
+// the tag to pull and may alternatively be appended to the reference as :<tag>. Helm's
+// registry client is not vendored, so the pull speaks the OCI distribution API directly
+// and reads basic credentials from HELM_REGISTRY_USERNAME and HELM_REGISTRY_PASSWORD.
+func ProcessChartFromOCI(name string, reference string, version string, valuesFiles []string, setValues []string) ([]string, error) {
+	archive, cleanup, err := pullOCIChart(reference, version)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	return ProcessChart(name, archive, valuesFiles, setValues)
+}
+
+// pullOCIChart downloads the chart archive of an oci:// reference into a temp dir and
+// returns its path with a cleanup func removing the dir
+func pullOCIChart(reference string, version string) (string, func(), error) {
+	host, repository, tag, err := parseOCIReference(reference, version)
+	if err != nil {
+		return "", nil, err
+	}
+	scheme := "https"
+	if isLocalRegistry(host) {
+		scheme = "http"
+	}
+
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, host, repository, tag)
+	manifestBody, err := fetchFromRegistry(manifestURL, "application/vnd.oci.image.manifest.v1+json", host, repository)
+	if err != nil {
+		return "", nil, err
+	}
+	var manifest struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal(manifestBody, &manifest); err != nil {
+		return "", nil, fmt.Errorf("failed to parse manifest of %s: %v", reference, err)
+	}
+	chartDigest := ""
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == helmChartLayerMediaType {
+			chartDigest = layer.Digest
+			break
+		}
+	}
+	if chartDigest == "" {
+		return "", nil, fmt.Errorf("%s:%s carries no helm chart layer, is it a chart pushed with helm or oras?", reference, tag)
+	}
+
+	blobURL := fmt.Sprintf("%s://%s/v2/%s/blobs/%s", scheme, host, repository, chartDigest)
+	blob, err := fetchFromRegistry(blobURL, "", host, repository)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tempDir, err := ioutil.TempDir("", "oci-chart-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp dir for chart %s: %v", reference, err)
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+	archive := filepath.Join(tempDir, filepath.Base(repository)+".tgz")
+	if err := ioutil.WriteFile(archive, blob, 0644); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write chart archive %s: %v", archive, err)
+	}
+	return archive, cleanup, nil
+}
+
+// parseOCIReference splits oci://host/repository[:tag] into its parts; an explicit
+// version must agree with a tag embedded in the reference
+func parseOCIReference(reference string, version string) (string, string, string, error) {
+	trimmed := strings.TrimPrefix(reference, OCIPrefix)
+	slash := strings.Index(trimmed, "/")
+	if slash <= 0 || slash == len(trimmed)-1 {
+		return "", "", "", fmt.Errorf("invalid OCI reference %s, expect oci://<host>/<repository>[:<tag>]", reference)
+	}
+	host, repository := trimmed[:slash], trimmed[slash+1:]
+	tag := ""
+	if colon := strings.LastIndex(repository, ":"); colon != -1 {
+		repository, tag = repository[:colon], repository[colon+1:]
+	}
+	if version != "" {
+		if tag != "" && tag != version {
+			return "", "", "", fmt.Errorf("OCI reference %s pins tag %s but version %s was requested", reference, tag, version)
+		}
+		tag = version
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+	return host, repository, tag, nil
+}
+
+// isLocalRegistry reports whether the registry host is a local one that is spoken to
+// over plain http, the convention container tooling uses for localhost registries
+func isLocalRegistry(host string) bool {
+	return strings.HasPrefix(host, "localhost") || strings.HasPrefix(host, "127.0.0.1")
+}
+
+// fetchFromRegistry performs one GET against the distribution API, transparently
+// acquiring a bearer token when the registry demands one and surfacing auth failures
+// with the credentials environment variables to set
+func fetchFromRegistry(url string, accept string, host string, repository string) ([]byte, error) {
+	response, err := registryGet(url, accept, "")
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusUnauthorized {
+		challenge := response.Header.Get("WWW-Authenticate")
+		response.Body.Close()
+		token, err := fetchRegistryToken(challenge, repository)
+		if err != nil {
+			return nil, fmt.Errorf("authentication to registry %s failed: %v; set HELM_REGISTRY_USERNAME and HELM_REGISTRY_PASSWORD for a private registry", host, err)
+		}
+		if response, err = registryGet(url, accept, token); err != nil {
+			return nil, err
+		}
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("authentication to registry %s failed with status %s; set HELM_REGISTRY_USERNAME and HELM_REGISTRY_PASSWORD for a private registry", host, response.Status)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry %s answered %s for %s", host, response.Status, url)
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry response of %s: %v", url, err)
+	}
+	return body, nil
+}
+
+func registryGet(url string, accept string, token string) (*http.Response, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	} else if username := os.Getenv("HELM_REGISTRY_USERNAME"); username != "" {
+		request.SetBasicAuth(username, os.Getenv("HELM_REGISTRY_PASSWORD"))
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach registry: %v", err)
+	}
+	return response, nil
+}
+
+// fetchRegistryToken walks the token flow of a Bearer challenge, passing along the
+// basic credentials from the environment when present
+func fetchRegistryToken(challenge string, repository string) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("registry demands credentials (%s)", strings.TrimSpace(challenge))
+	}
+	params := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		if kv := strings.SplitN(strings.TrimSpace(part), "=", 2); len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("bearer challenge carries no realm (%s)", challenge)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", repository)
+	}
+	tokenURL := fmt.Sprintf("%s?service=%s&scope=%s", realm, params["service"], scope)
+	response, err := registryGet(tokenURL, "", "")
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint %s answered %s", realm, response.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse token response of %s: %v", realm, err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return "", fmt.Errorf("token endpoint %s returned no token", realm)
+	}
+	return token.Token, nil
+}
@@ -0,0 +1,102 @@
+package chart
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// makeChartArchive packs the given chart files into a tgz the way helm push would
+func makeChartArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatalf("failed to write tar header: %v", err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatalf("failed to write tar entry: %v", err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatalf("failed to close gzip: %v", err)
+	}
+	return buffer.Bytes()
+}
+
+func TestProcessChartFromOCI(t *testing.T) {
+	archive := makeChartArchive(t, map[string]string{
+		"demo/Chart.yaml":        "apiVersion: v2\nname: demo\nversion: 1.0.0\n",
+		"demo/values.yaml":       "configName: demo-config\n",
+		"demo/templates/cm.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Values.configName }}\n",
+	})
+	manifest := fmt.Sprintf(`{"layers":[{"mediaType":"%s","digest":"sha256:demo","size":%d}]}`, helmChartLayerMediaType, len(archive))
+	registry := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/v2/charts/demo/manifests/1.0.0":
+			writer.Write([]byte(manifest))
+		case "/v2/charts/demo/blobs/sha256:demo":
+			writer.Write(archive)
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+	defer registry.Close()
+
+	// the test server listens on 127.0.0.1, which the puller speaks plain http to
+	reference := fmt.Sprintf("oci://%s/charts/demo", strings.TrimPrefix(registry.URL, "http://"))
+	content, err := ProcessChartFromOCI("demo-app", reference, "1.0.0", nil, []string{"configName=oci-config"})
+	if err != nil {
+		t.Fatalf("ProcessChartFromOCI() error = %v", err)
+	}
+	if len(content) != 1 || !strings.Contains(content[0], "oci-config") {
+		t.Errorf("ProcessChartFromOCI() rendered %v, want the configmap with the set override", content)
+	}
+
+	if _, err := ProcessChartFromOCI("demo-app", reference, "2.0.0", nil, nil); err == nil {
+		t.Errorf("ProcessChartFromOCI() expected an error for a missing tag")
+	}
+}
+
+func TestProcessChartFromOCIAuthError(t *testing.T) {
+	registry := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+		writer.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer registry.Close()
+
+	reference := fmt.Sprintf("oci://%s/charts/demo", strings.TrimPrefix(registry.URL, "http://"))
+	_, err := ProcessChartFromOCI("demo-app", reference, "1.0.0", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "HELM_REGISTRY_USERNAME") {
+		t.Errorf("ProcessChartFromOCI() error = %v, want a clear auth failure naming the credential variables", err)
+	}
+}
+
+func TestParseOCIReference(t *testing.T) {
+	host, repository, tag, err := parseOCIReference("oci://registry.example.com/charts/foo:2.1.0", "")
+	if err != nil || host != "registry.example.com" || repository != "charts/foo" || tag != "2.1.0" {
+		t.Errorf("parseOCIReference() = %s, %s, %s, %v, want the embedded tag", host, repository, tag, err)
+	}
+
+	_, _, tag, err = parseOCIReference("oci://registry.example.com/charts/foo", "")
+	if err != nil || tag != "latest" {
+		t.Errorf("parseOCIReference() tag = %s, %v, want the latest default", tag, err)
+	}
+
+	if _, _, _, err := parseOCIReference("oci://registry.example.com/charts/foo:1.0.0", "2.0.0"); err == nil {
+		t.Errorf("parseOCIReference() expected an error for a tag conflicting with the version")
+	}
+	if _, _, _, err := parseOCIReference("oci://no-repository", ""); err == nil {
+		t.Errorf("parseOCIReference() expected an error for a reference without repository")
+	}
+}
@@ -0,0 +1,261 @@
+package dump
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+)
+
+// system namespaces carry no sensitive information and keeping them makes the
+// anonymized snapshot easier to read
+var keptNamespaces = map[string]struct{}{
+	"default":     {},
+	"kube-system": {},
+	"kube-public": {},
+}
+
+// AnonymizeClusterResource replaces namespace, object and image names as well as label and
+// selector values with hashed placeholders. The same input always maps to the same
+// placeholder, and label keys, resource shapes and topology are preserved, so the
+// anonymized snapshot reproduces the scheduling behavior of the original cluster.
+func AnonymizeClusterResource(resources *simulator.ResourceTypes) {
+	for _, node := range resources.Nodes {
+		node.Name = hashed("node", node.Name)
+		node.Labels = anonymizeLabels(node.Labels)
+		anonymizeObjectMeta(&node.ObjectMeta)
+	}
+	for _, pod := range resources.Pods {
+		pod.Name = hashed("pod", pod.Name)
+		anonymizePodMeta(&pod.ObjectMeta)
+		anonymizePodSpec(&pod.Spec)
+	}
+	for _, deploy := range resources.Deployments {
+		deploy.Name = hashed("deploy", deploy.Name)
+		anonymizePodMeta(&deploy.ObjectMeta)
+		anonymizeLabelSelector(deploy.Spec.Selector)
+		anonymizePodMeta(&deploy.Spec.Template.ObjectMeta)
+		anonymizePodSpec(&deploy.Spec.Template.Spec)
+	}
+	for _, rs := range resources.ReplicaSets {
+		rs.Name = hashed("rs", rs.Name)
+		anonymizePodMeta(&rs.ObjectMeta)
+		anonymizeLabelSelector(rs.Spec.Selector)
+		anonymizePodMeta(&rs.Spec.Template.ObjectMeta)
+		anonymizePodSpec(&rs.Spec.Template.Spec)
+	}
+	for _, sts := range resources.StatefulSets {
+		sts.Name = hashed("sts", sts.Name)
+		anonymizePodMeta(&sts.ObjectMeta)
+		anonymizeLabelSelector(sts.Spec.Selector)
+		anonymizePodMeta(&sts.Spec.Template.ObjectMeta)
+		anonymizePodSpec(&sts.Spec.Template.Spec)
+		sts.Spec.ServiceName = hashed("svc", sts.Spec.ServiceName)
+	}
+	for _, ds := range resources.DaemonSets {
+		ds.Name = hashed("ds", ds.Name)
+		anonymizePodMeta(&ds.ObjectMeta)
+		anonymizeLabelSelector(ds.Spec.Selector)
+		anonymizePodMeta(&ds.Spec.Template.ObjectMeta)
+		anonymizePodSpec(&ds.Spec.Template.Spec)
+	}
+	for _, svc := range resources.Services {
+		svc.Name = hashed("svc", svc.Name)
+		anonymizePodMeta(&svc.ObjectMeta)
+		for key, value := range svc.Spec.Selector {
+			if !isSystemLabelKey(key) {
+				svc.Spec.Selector[key] = hashed("value", value)
+			}
+		}
+	}
+	for _, pvc := range resources.PersistentVolumeClaims {
+		pvc.Name = hashed("pvc", pvc.Name)
+		anonymizePodMeta(&pvc.ObjectMeta)
+	}
+	for _, pdb := range resources.PodDisruptionBudgets {
+		pdb.Name = hashed("pdb", pdb.Name)
+		anonymizePodMeta(&pdb.ObjectMeta)
+		anonymizeLabelSelector(pdb.Spec.Selector)
+	}
+	for _, job := range resources.Jobs {
+		job.Name = hashed("job", job.Name)
+		anonymizePodMeta(&job.ObjectMeta)
+		anonymizePodMeta(&job.Spec.Template.ObjectMeta)
+		anonymizePodSpec(&job.Spec.Template.Spec)
+	}
+	for _, cronJob := range resources.CronJobs {
+		cronJob.Name = hashed("cronjob", cronJob.Name)
+		anonymizePodMeta(&cronJob.ObjectMeta)
+		anonymizePodMeta(&cronJob.Spec.JobTemplate.Spec.Template.ObjectMeta)
+		anonymizePodSpec(&cronJob.Spec.JobTemplate.Spec.Template.Spec)
+	}
+	for _, cm := range resources.ConfigMaps {
+		cm.Name = hashed("cm", cm.Name)
+		anonymizePodMeta(&cm.ObjectMeta)
+		// config map contents are irrelevant for scheduling but may leak secrets
+		cm.Data = nil
+		cm.BinaryData = nil
+	}
+}
+
+// hashed maps a value to a stable placeholder such as "node-5f2a81c3d4". Empty values
+// stay empty so optional fields keep their meaning.
+func hashed(kind, value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(value))
+	return fmt.Sprintf("%s-%x", kind, sum[:5])
+}
+
+// isSystemLabelKey reports whether the label key belongs to kubernetes itself; the values
+// of such labels (e.g. the OS or architecture) carry scheduling semantics and are kept
+func isSystemLabelKey(key string) bool {
+	parts := strings.SplitN(key, "/", 2)
+	return len(parts) == 2 && (strings.HasSuffix(parts[0], "kubernetes.io") || strings.HasSuffix(parts[0], "k8s.io"))
+}
+
+func anonymizeNamespace(namespace string) string {
+	if _, exist := keptNamespaces[namespace]; exist || namespace == "" {
+		return namespace
+	}
+	return hashed("ns", namespace)
+}
+
+// anonymizeLabels hashes the label values while keeping every key, so selectors that are
+// anonymized the same way still match
+func anonymizeLabels(labels map[string]string) map[string]string {
+	for key, value := range labels {
+		if !isSystemLabelKey(key) {
+			labels[key] = hashed("value", value)
+		}
+	}
+	return labels
+}
+
+func anonymizeLabelSelector(selector *metav1.LabelSelector) {
+	if selector == nil {
+		return
+	}
+	for key, value := range selector.MatchLabels {
+		if !isSystemLabelKey(key) {
+			selector.MatchLabels[key] = hashed("value", value)
+		}
+	}
+	for i, expr := range selector.MatchExpressions {
+		if isSystemLabelKey(expr.Key) {
+			continue
+		}
+		for j, value := range expr.Values {
+			selector.MatchExpressions[i].Values[j] = hashed("value", value)
+		}
+	}
+}
+
+func anonymizeObjectMeta(meta *metav1.ObjectMeta) {
+	// annotations may embed arbitrary user data; the simon annotations that describe
+	// local storage and gpu shapes are kept because they affect scheduling
+	for key := range meta.Annotations {
+		if !strings.HasPrefix(key, "simon/") {
+			delete(meta.Annotations, key)
+		}
+	}
+	meta.OwnerReferences = anonymizeOwnerReferences(meta.OwnerReferences)
+}
+
+func anonymizePodMeta(meta *metav1.ObjectMeta) {
+	meta.Namespace = anonymizeNamespace(meta.Namespace)
+	meta.Labels = anonymizeLabels(meta.Labels)
+	anonymizeObjectMeta(meta)
+}
+
+func anonymizeOwnerReferences(refs []metav1.OwnerReference) []metav1.OwnerReference {
+	for i, ref := range refs {
+		refs[i].Name = hashed(strings.ToLower(ref.Kind), ref.Name)
+	}
+	return refs
+}
+
+func anonymizePodSpec(spec *corev1.PodSpec) {
+	if spec.NodeName != "" {
+		spec.NodeName = hashed("node", spec.NodeName)
+	}
+	for key, value := range spec.NodeSelector {
+		if !isSystemLabelKey(key) {
+			spec.NodeSelector[key] = hashed("value", value)
+		}
+	}
+	for i := range spec.InitContainers {
+		anonymizeContainer(&spec.InitContainers[i])
+	}
+	for i := range spec.Containers {
+		anonymizeContainer(&spec.Containers[i])
+	}
+	if affinity := spec.Affinity; affinity != nil {
+		if affinity.PodAffinity != nil {
+			for i := range affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				anonymizeAffinityTerm(&affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i])
+			}
+			for i := range affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+				anonymizeAffinityTerm(&affinity.PodAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].PodAffinityTerm)
+			}
+		}
+		if affinity.PodAntiAffinity != nil {
+			for i := range affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				anonymizeAffinityTerm(&affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i])
+			}
+			for i := range affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+				anonymizeAffinityTerm(&affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].PodAffinityTerm)
+			}
+		}
+		if affinity.NodeAffinity != nil && affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+			for i := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+				term := &affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[i]
+				for j, expr := range term.MatchExpressions {
+					if isSystemLabelKey(expr.Key) {
+						continue
+					}
+					for k, value := range expr.Values {
+						term.MatchExpressions[j].Values[k] = hashed("value", value)
+					}
+				}
+			}
+		}
+	}
+	for i := range spec.TopologySpreadConstraints {
+		anonymizeLabelSelector(spec.TopologySpreadConstraints[i].LabelSelector)
+	}
+	for i := range spec.Volumes {
+		spec.Volumes[i].Name = hashed("volume", spec.Volumes[i].Name)
+		if pvc := spec.Volumes[i].PersistentVolumeClaim; pvc != nil {
+			pvc.ClaimName = hashed("pvc", pvc.ClaimName)
+		}
+	}
+	if spec.ServiceAccountName != "" {
+		spec.ServiceAccountName = hashed("sa", spec.ServiceAccountName)
+	}
+}
+
+// anonymizeContainer hashes the container name and image and keeps the volume mount
+// names consistent with the hashed pod volumes
+func anonymizeContainer(container *corev1.Container) {
+	container.Name = hashed("container", container.Name)
+	container.Image = hashed("image", container.Image)
+	for i := range container.VolumeMounts {
+		container.VolumeMounts[i].Name = hashed("volume", container.VolumeMounts[i].Name)
+	}
+	// environment variables may embed credentials or internal endpoints
+	container.Env = nil
+	container.EnvFrom = nil
+}
+
+func anonymizeAffinityTerm(term *corev1.PodAffinityTerm) {
+	anonymizeLabelSelector(term.LabelSelector)
+	for i, ns := range term.Namespaces {
+		term.Namespaces[i] = anonymizeNamespace(ns)
+	}
+}
@@ -0,0 +1,48 @@
+package dump
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/test"
+)
+
+func TestAnonymizeClusterResource(t *testing.T) {
+	node := test.MakeFakeNode("prod-node-1", "8", "16Gi", test.WithNodeLabels(map[string]string{
+		"kubernetes.io/os": "linux",
+		"team":             "payments",
+	}))
+	pod := test.MakeFakePod("billing-api-0", "billing", "1", "1Gi", test.WithPodLabels(map[string]string{
+		"team": "payments",
+	}))
+	pod.Spec.NodeName = "prod-node-1"
+	pod.Spec.NodeSelector = map[string]string{"team": "payments"}
+	resources := simulator.ResourceTypes{
+		Nodes: []*corev1.Node{node},
+		Pods:  []*corev1.Pod{pod},
+	}
+
+	AnonymizeClusterResource(&resources)
+
+	if node.Name == "prod-node-1" || pod.Name == "billing-api-0" || pod.Namespace == "billing" {
+		t.Errorf("AnonymizeClusterResource() left an original name in place: node %s, pod %s/%s", node.Name, pod.Namespace, pod.Name)
+	}
+	if pod.Spec.NodeName != node.Name {
+		t.Errorf("AnonymizeClusterResource() broke the pod-to-node assignment: %s != %s", pod.Spec.NodeName, node.Name)
+	}
+	if node.Labels["kubernetes.io/os"] != "linux" {
+		t.Errorf("AnonymizeClusterResource() must keep system label values, got %s", node.Labels["kubernetes.io/os"])
+	}
+	if node.Labels["team"] == "payments" {
+		t.Errorf("AnonymizeClusterResource() left a sensitive label value in place")
+	}
+	if pod.Spec.NodeSelector["team"] != node.Labels["team"] {
+		t.Errorf("AnonymizeClusterResource() hashed the node selector value inconsistently: %s != %s", pod.Spec.NodeSelector["team"], node.Labels["team"])
+	}
+	cpu := node.Status.Allocatable[corev1.ResourceCPU]
+	if cpu.String() != "8" {
+		t.Errorf("AnonymizeClusterResource() must not touch resource shapes, got cpu %s", cpu.String())
+	}
+}
@@ -0,0 +1,117 @@
+package dump
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/open-simulator/pkg/simulator"
+	"github.com/alibaba/open-simulator/pkg/utils"
+)
+
+// Options configures a snapshot dump
+type Options struct {
+	Kubeconfig string
+	OutputDir  string
+	Anonymize  bool
+}
+
+// Dump takes a snapshot of the cluster behind the kubeconfig and writes it as YAML files
+// into the output directory, one file per resource type. With Anonymize set, sensitive
+// names are replaced by hashed placeholders while resource shapes and topology stay
+// intact, so the snapshot can be shared and still reproduces the scheduling behavior.
+func Dump(opts Options) error {
+	kubeclient, err := utils.CreateKubeClient(opts.Kubeconfig)
+	if err != nil {
+		return err
+	}
+	resources, err := simulator.CreateClusterResourceFromClient(kubeclient, true)
+	if err != nil {
+		return err
+	}
+	if opts.Anonymize {
+		AnonymizeClusterResource(&resources)
+	}
+
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %v", opts.OutputDir, err)
+	}
+
+	files := map[string][]interface{}{}
+	for _, node := range resources.Nodes {
+		node.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Node"}
+		files["nodes.yaml"] = append(files["nodes.yaml"], node)
+	}
+	for _, pod := range resources.Pods {
+		pod.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}
+		files["pods.yaml"] = append(files["pods.yaml"], pod)
+	}
+	for _, deploy := range resources.Deployments {
+		deploy.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}
+		files["deployments.yaml"] = append(files["deployments.yaml"], deploy)
+	}
+	for _, rs := range resources.ReplicaSets {
+		rs.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "ReplicaSet"}
+		files["replicasets.yaml"] = append(files["replicasets.yaml"], rs)
+	}
+	for _, sts := range resources.StatefulSets {
+		sts.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "StatefulSet"}
+		files["statefulsets.yaml"] = append(files["statefulsets.yaml"], sts)
+	}
+	for _, ds := range resources.DaemonSets {
+		ds.TypeMeta = metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"}
+		files["daemonsets.yaml"] = append(files["daemonsets.yaml"], ds)
+	}
+	for _, svc := range resources.Services {
+		svc.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Service"}
+		files["services.yaml"] = append(files["services.yaml"], svc)
+	}
+	for _, pvc := range resources.PersistentVolumeClaims {
+		pvc.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"}
+		files["persistentvolumeclaims.yaml"] = append(files["persistentvolumeclaims.yaml"], pvc)
+	}
+	for _, sc := range resources.StorageClasss {
+		sc.TypeMeta = metav1.TypeMeta{APIVersion: "storage.k8s.io/v1", Kind: "StorageClass"}
+		files["storageclasses.yaml"] = append(files["storageclasses.yaml"], sc)
+	}
+	for _, pdb := range resources.PodDisruptionBudgets {
+		pdb.TypeMeta = metav1.TypeMeta{APIVersion: "policy/v1beta1", Kind: "PodDisruptionBudget"}
+		files["poddisruptionbudgets.yaml"] = append(files["poddisruptionbudgets.yaml"], pdb)
+	}
+	for _, job := range resources.Jobs {
+		job.TypeMeta = metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"}
+		files["jobs.yaml"] = append(files["jobs.yaml"], job)
+	}
+	for _, cronJob := range resources.CronJobs {
+		cronJob.TypeMeta = metav1.TypeMeta{APIVersion: "batch/v1beta1", Kind: "CronJob"}
+		files["cronjobs.yaml"] = append(files["cronjobs.yaml"], cronJob)
+	}
+	for _, cm := range resources.ConfigMaps {
+		cm.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+		files["configmaps.yaml"] = append(files["configmaps.yaml"], cm)
+	}
+
+	for fileName, objects := range files {
+		var content []byte
+		for i, object := range objects {
+			data, err := yaml.Marshal(object)
+			if err != nil {
+				return fmt.Errorf("failed to marshal objects for %s: %v", fileName, err)
+			}
+			if i > 0 {
+				content = append(content, []byte("---\n")...)
+			}
+			content = append(content, data...)
+		}
+		path := filepath.Join(opts.OutputDir, fileName)
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+	}
+
+	return nil
+}
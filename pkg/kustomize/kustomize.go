@@ -0,0 +1,183 @@
+package kustomize
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/alibaba/open-simulator/pkg/utils"
+)
+
+// kustomization is the subset of the kustomization.yaml schema rendered natively:
+// resource composition plus the common name, namespace and label transformers. The full
+// kustomize engine is not vendored, and app manifests rarely need more to be schedulable.
+type kustomization struct {
+	Resources []string `json:"resources,omitempty"`
+	// Bases is deprecated in kustomize in favor of resources, but still widespread
+	Bases        []string          `json:"bases,omitempty"`
+	NamePrefix   string            `json:"namePrefix,omitempty"`
+	NameSuffix   string            `json:"nameSuffix,omitempty"`
+	Namespace    string            `json:"namespace,omitempty"`
+	CommonLabels map[string]string `json:"commonLabels,omitempty"`
+}
+
+// ProcessKustomize renders the kustomization at path into plain YAML documents, the same
+// shape chart.ProcessChart returns. The path may be the directory of an overlay or the
+// kustomization.yaml itself; resources and bases are resolved recursively, so overlays
+// stacked on bases work.
+func ProcessKustomize(path string) ([]string, error) {
+	kustomizationFile, err := resolveKustomizationFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := ioutil.ReadFile(kustomizationFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", kustomizationFile, err)
+	}
+	var k kustomization
+	if err := yaml.Unmarshal(content, &k); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %v", kustomizationFile, err)
+	}
+
+	dir := filepath.Dir(kustomizationFile)
+	var docs []string
+	for _, resourcePath := range append(append([]string{}, k.Resources...), k.Bases...) {
+		resourceDocs, err := loadResource(filepath.Join(dir, resourcePath))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, resourceDocs...)
+	}
+
+	var rendered []string
+	for _, doc := range docs {
+		transformed, err := k.transform(doc)
+		if err != nil {
+			return nil, err
+		}
+		if transformed != "" {
+			rendered = append(rendered, transformed)
+		}
+	}
+	return rendered, nil
+}
+
+// resolveKustomizationFile maps the app path to its kustomization file: an explicit file
+// path is used as is, a directory must contain kustomization.yaml or kustomization.yml
+func resolveKustomizationFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat kustomize path %s: %v", path, err)
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, name := range []string{"kustomization.yaml", "kustomization.yml"} {
+		candidate := filepath.Join(path, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no kustomization.yaml found in %s", path)
+}
+
+// loadResource resolves one resources entry: another kustomization directory is rendered
+// recursively, a plain directory contributes all its yaml files, a file its documents
+func loadResource(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat resource %s: %v", path, err)
+	}
+	if info.IsDir() {
+		if _, err := resolveKustomizationFile(path); err == nil {
+			return ProcessKustomize(path)
+		}
+		return utils.GetYamlContentFromDirectory(path)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource %s: %v", path, err)
+	}
+	var docs []string
+	for _, manifest := range releaseutil.SplitManifests(string(content)) {
+		docs = append(docs, manifest)
+	}
+	return docs, nil
+}
+
+// transform applies the name, namespace and label transformers of the kustomization to
+// one manifest. Label selectors are left untouched; the simulator only needs the pod
+// templates to carry the labels, not a consistent selector rewrite.
+func (k *kustomization) transform(doc string) (string, error) {
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+		return "", fmt.Errorf("failed to unmarshal rendered resource: %v", err)
+	}
+	if len(obj) == 0 {
+		return "", nil
+	}
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		obj["metadata"] = metadata
+	}
+	if name, ok := metadata["name"].(string); ok && (k.NamePrefix != "" || k.NameSuffix != "") {
+		metadata["name"] = k.NamePrefix + name + k.NameSuffix
+	}
+	if k.Namespace != "" {
+		metadata["namespace"] = k.Namespace
+	}
+	if len(k.CommonLabels) != 0 {
+		labels, ok := metadata["labels"].(map[string]interface{})
+		if !ok {
+			labels = map[string]interface{}{}
+			metadata["labels"] = labels
+		}
+		for key, value := range k.CommonLabels {
+			labels[key] = value
+		}
+		k.applyCommonLabelsToPodTemplate(obj)
+	}
+	content, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal transformed resource: %v", err)
+	}
+	return string(content), nil
+}
+
+// applyCommonLabelsToPodTemplate pushes the common labels into the pod template of a
+// workload manifest, so the pods generated from it carry them the way real kustomize
+// output does; services, anti-affinity and spread constraints select on these labels.
+// CronJobs nest the pod template one jobTemplate level deeper.
+func (k *kustomization) applyCommonLabelsToPodTemplate(obj map[string]interface{}) {
+	spec, ok := obj["spec"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if jobTemplate, ok := spec["jobTemplate"].(map[string]interface{}); ok {
+		if jobSpec, ok := jobTemplate["spec"].(map[string]interface{}); ok {
+			spec = jobSpec
+		}
+	}
+	template, ok := spec["template"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	metadata, ok := template["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		template["metadata"] = metadata
+	}
+	labels, ok := metadata["labels"].(map[string]interface{})
+	if !ok {
+		labels = map[string]interface{}{}
+		metadata["labels"] = labels
+	}
+	for key, value := range k.CommonLabels {
+		labels[key] = value
+	}
+}
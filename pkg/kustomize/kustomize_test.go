@@ -0,0 +1,85 @@
+package kustomize
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProcessKustomize(t *testing.T) {
+	dir := t.TempDir()
+	baseDir := filepath.Join(dir, "base")
+	overlayDir := filepath.Join(dir, "overlay")
+	for _, sub := range []string{baseDir, overlayDir} {
+		if err := os.Mkdir(sub, 0755); err != nil {
+			t.Fatalf("failed to create directory: %v", err)
+		}
+	}
+
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: demo
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: container
+        image: image
+`
+	baseKustomization := `resources:
+- deployment.yaml
+`
+	overlayKustomization := `resources:
+- ../base
+namePrefix: prod-
+namespace: production
+commonLabels:
+  env: prod
+`
+	files := map[string]string{
+		filepath.Join(baseDir, "deployment.yaml"):       deployment,
+		filepath.Join(baseDir, "kustomization.yaml"):    baseKustomization,
+		filepath.Join(overlayDir, "kustomization.yaml"): overlayKustomization,
+	}
+	for path, content := range files {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	// the overlay directory renders its base recursively with the transformers applied
+	docs, err := ProcessKustomize(overlayDir)
+	if err != nil {
+		t.Fatalf("failed to process overlay: %v", err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("expect 1 rendered document, got %d", len(docs))
+	}
+	for _, expected := range []string{"name: prod-demo", "namespace: production", "env: prod"} {
+		if !strings.Contains(docs[0], expected) {
+			t.Fatalf("rendered document misses %q:\n%s", expected, docs[0])
+		}
+	}
+	// the pod template carries the common labels too, otherwise selectors keyed on
+	// them simulate differently from the real kustomize output
+	if !strings.Contains(docs[0], "template:\n    metadata:\n      labels:\n        env: prod") {
+		t.Fatalf("rendered pod template misses the common labels:\n%s", docs[0])
+	}
+
+	// an explicit kustomization file path works like its directory
+	docs, err = ProcessKustomize(filepath.Join(baseDir, "kustomization.yaml"))
+	if err != nil {
+		t.Fatalf("failed to process base file: %v", err)
+	}
+	if len(docs) != 1 || !strings.Contains(docs[0], "name: demo") {
+		t.Fatalf("unexpected base rendering: %v", docs)
+	}
+
+	if _, err := ProcessKustomize(dir); err == nil {
+		t.Fatal("expect an error for a directory without kustomization.yaml")
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"net/http"
@@ -43,6 +43,11 @@ type Server struct {
 	pdbLister     policylisters.PodDisruptionBudgetLister
 	scLister      storagelisters.StorageClassLister
 	cmLister      corelisters.ConfigMapLister
+
+	// simulateSlots bounds how many simulations may run at once; a full channel means
+	// new simulation requests are rejected instead of piling up on large snapshots
+	simulateSlots chan struct{}
+	inFlight      int64
 }
 
 type DeployAppRequest struct {
@@ -92,9 +97,10 @@ type NodeStatus struct {
 	Pods []string `json:"pods"`
 }
 
-var deployLock, scaleLock sync.Mutex
-
-func NewServer(kubeconfig, master string) (*Server, error) {
+func NewServer(kubeconfig, master string, maxConcurrentSimulations int) (*Server, error) {
+	if maxConcurrentSimulations <= 0 {
+		maxConcurrentSimulations = 1
+	}
 	cfg, err := clientcmd.BuildConfigFromFlags(master, kubeconfig)
 	if err != nil {
 		return nil, fmt.Errorf("Error building kubeconfig: %s", err.Error())
@@ -133,6 +139,7 @@ func NewServer(kubeconfig, master string) (*Server, error) {
 		pdbLister:     kubeInformerFactory.Policy().V1beta1().PodDisruptionBudgets().Lister(),
 		scLister:      kubeInformerFactory.Storage().V1().StorageClasses().Lister(),
 		cmLister:      kubeInformerFactory.Core().V1().ConfigMaps().Lister(),
+		simulateSlots: make(chan struct{}, maxConcurrentSimulations),
 	}, nil
 }
 
@@ -162,158 +169,185 @@ func (server *Server) setupRouter(opts ...simulator.Option) *gin.Engine {
 		})
 	})
 
+	// expose the in-flight simulation count for operational monitoring
+	r.GET("/metrics", func(c *gin.Context) {
+		c.String(http.StatusOK, "simon_simulations_in_flight %d\n", server.simulationsInFlight())
+	})
+
 	// deloy apps
 	r.POST("api/deploy-apps", func(c *gin.Context) {
-		if deployLock.TryLock() {
-			defer deployLock.Unlock()
-			// unmarshal
-			req := &DeployAppRequest{}
-			reqData, err := ioutil.ReadAll(c.Request.Body)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, err.Error())
-				return
-			}
-			if err := apimachineryjson.Unmarshal(reqData, req); err != nil {
-				c.JSON(http.StatusBadRequest, fmt.Sprintf("fail to unmarshal content: %s\n", err.Error()))
-				return
-			}
+		if !server.tryAcquireSimulateSlot() {
+			c.JSON(http.StatusTooManyRequests, "the server has reached its simulation concurrency limit, please try again later")
+			return
+		}
+		defer server.releaseSimulateSlot()
+		// unmarshal
+		req := &DeployAppRequest{}
+		reqData, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := apimachineryjson.Unmarshal(reqData, req); err != nil {
+			c.JSON(http.StatusBadRequest, fmt.Sprintf("fail to unmarshal content: %s\n", err.Error()))
+			return
+		}
 
-			// get current cluster resources
-			ClusterResource, err := server.getCurrentClusterResource()
+		// get current cluster resources
+		ClusterResource, err := server.getCurrentClusterResource()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to get current cluster resources: %s", err.Error()))
+			return
+		}
+		for _, newNode := range req.NewNodes {
+			node, err := utils.NewFakeNode(newNode)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to get current cluster resources: %s", err.Error()))
+				c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to create a new fake node: %s", err.Error()))
 				return
 			}
-			for _, newNode := range req.NewNodes {
-				node, err := utils.NewFakeNode(newNode)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to create a new fake node: %s", err.Error()))
-					return
-				}
-				ClusterResource.Nodes = append(ClusterResource.Nodes, node)
-			}
+			ClusterResource.Nodes = append(ClusterResource.Nodes, node)
+		}
 
-			// app resources
-			AppResources := []simulator.AppResource{
-				{
-					Name: "test",
-					Resource: simulator.ResourceTypes{
-						Pods:         req.Pods,
-						Deployments:  req.Deployments,
-						StatefulSets: req.StatefulSets,
-						DaemonSets:   req.DaemonSets,
-						Jobs:         req.Jobs,
-						ConfigMaps:   req.ConfigMaps,
-					},
+		// app resources
+		AppResources := []simulator.AppResource{
+			{
+				Name: "test",
+				Resource: simulator.ResourceTypes{
+					Pods:         req.Pods,
+					Deployments:  req.Deployments,
+					StatefulSets: req.StatefulSets,
+					DaemonSets:   req.DaemonSets,
+					Jobs:         req.Jobs,
+					ConfigMaps:   req.ConfigMaps,
 				},
-			}
-			pendingPods, err := server.getPendingPods()
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to get pending pods: %s", err.Error()))
-				return
-			}
-			AppResources[0].Resource.Pods = append(AppResources[0].Resource.Pods, pendingPods...)
+			},
+		}
+		pendingPods, err := server.getPendingPods()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to get pending pods: %s", err.Error()))
+			return
+		}
+		AppResources[0].Resource.Pods = append(AppResources[0].Resource.Pods, pendingPods...)
 
-			// simulate
-			result, err := simulator.Simulate(ClusterResource, AppResources, opts...)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, err.Error())
-				return
-			}
+		// simulate
+		result, err := simulator.Simulate(ClusterResource, AppResources, opts...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, err.Error())
+			return
+		}
 
-			response := getSimulateResponse(result)
+		response := getSimulateResponse(result)
 
-			c.JSON(http.StatusOK, response)
-		} else {
-			c.JSON(http.StatusServiceUnavailable, "The server is busy, please try again later")
-		}
+		c.JSON(http.StatusOK, response)
 	})
 
 	// scale apps
 	r.POST("api/scale-apps", func(c *gin.Context) {
-		if scaleLock.TryLock() {
-			defer scaleLock.Unlock()
-			// unmarshal
-			req := &ScaleAppRequest{}
-			reqData, err := ioutil.ReadAll(c.Request.Body)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, err.Error())
-				return
-			}
-			if err := apimachineryjson.Unmarshal(reqData, req); err != nil {
-				c.JSON(http.StatusBadRequest, fmt.Sprintf("fail to unmarshal content: %s\n", err.Error()))
-				return
-			}
+		if !server.tryAcquireSimulateSlot() {
+			c.JSON(http.StatusTooManyRequests, "the server has reached its simulation concurrency limit, please try again later")
+			return
+		}
+		defer server.releaseSimulateSlot()
+		// unmarshal
+		req := &ScaleAppRequest{}
+		reqData, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := apimachineryjson.Unmarshal(reqData, req); err != nil {
+			c.JSON(http.StatusBadRequest, fmt.Sprintf("fail to unmarshal content: %s\n", err.Error()))
+			return
+		}
 
-			// get current cluster resources
-			ClusterResource, err := server.getCurrentClusterResource()
+		// get current cluster resources
+		ClusterResource, err := server.getCurrentClusterResource()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to get current cluster resources: %s", err.Error()))
+			return
+		}
+		for _, newNode := range req.NewNodes {
+			node, err := utils.NewFakeNode(newNode)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to get current cluster resources: %s", err.Error()))
+				c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to create a new fake node: %s", err.Error()))
 				return
 			}
-			for _, newNode := range req.NewNodes {
-				node, err := utils.NewFakeNode(newNode)
-				if err != nil {
-					c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to create a new fake node: %s", err.Error()))
-					return
-				}
-				ClusterResource.Nodes = append(ClusterResource.Nodes, node)
-			}
+			ClusterResource.Nodes = append(ClusterResource.Nodes, node)
+		}
 
-			// remove app pods that will be scaled
-			ClusterResource.Pods, err = server.removePodsOfApp(ClusterResource.Pods, req)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, err.Error())
-				return
-			}
-			for _, reqDaemonset := range req.DaemonSets {
-				for j, ds := range ClusterResource.DaemonSets {
-					if ds.Name == reqDaemonset.Name && ds.Namespace == reqDaemonset.Namespace {
-						ClusterResource.DaemonSets[j] = reqDaemonset.DeepCopy()
-						break
-					}
+		// remove app pods that will be scaled
+		ClusterResource.Pods, err = server.removePodsOfApp(ClusterResource.Pods, req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, reqDaemonset := range req.DaemonSets {
+			for j, ds := range ClusterResource.DaemonSets {
+				if ds.Name == reqDaemonset.Name && ds.Namespace == reqDaemonset.Namespace {
+					ClusterResource.DaemonSets[j] = reqDaemonset.DeepCopy()
+					break
 				}
 			}
+		}
 
-			// app resources
-			AppResources := []simulator.AppResource{
-				{
-					Name: "test",
-					Resource: simulator.ResourceTypes{
-						Deployments:  req.Deployments,
-						StatefulSets: req.StatefulSets,
-					},
+		// app resources
+		AppResources := []simulator.AppResource{
+			{
+				Name: "test",
+				Resource: simulator.ResourceTypes{
+					Deployments:  req.Deployments,
+					StatefulSets: req.StatefulSets,
 				},
-			}
-			pendingPods, err := server.getPendingPods()
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to get pending pods: %s", err.Error()))
-				return
-			}
-			pendingPods, err = server.removePodsOfApp(pendingPods, req)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, err.Error())
-				return
-			}
-			AppResources[0].Resource.Pods = append(AppResources[0].Resource.Pods, pendingPods...)
-
-			// simulate
-			result, err := simulator.Simulate(ClusterResource, AppResources, opts...)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, err.Error())
-				return
-			}
-			response := getSimulateResponse(result)
+			},
+		}
+		pendingPods, err := server.getPendingPods()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, fmt.Sprintf("fail to get pending pods: %s", err.Error()))
+			return
+		}
+		pendingPods, err = server.removePodsOfApp(pendingPods, req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, err.Error())
+			return
+		}
+		AppResources[0].Resource.Pods = append(AppResources[0].Resource.Pods, pendingPods...)
 
-			c.JSON(http.StatusOK, response)
-		} else {
-			c.JSON(http.StatusServiceUnavailable, "The server is busy, please try again later")
+		// simulate
+		result, err := simulator.Simulate(ClusterResource, AppResources, opts...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, err.Error())
+			return
 		}
+		response := getSimulateResponse(result)
+
+		c.JSON(http.StatusOK, response)
 	})
 
 	return r
 }
 
+// tryAcquireSimulateSlot reserves one of the bounded simulation slots, reporting false
+// when the concurrency limit is already reached
+func (server *Server) tryAcquireSimulateSlot() bool {
+	select {
+	case server.simulateSlots <- struct{}{}:
+		atomic.AddInt64(&server.inFlight, 1)
+		return true
+	default:
+		return false
+	}
+}
+
+func (server *Server) releaseSimulateSlot() {
+	<-server.simulateSlots
+	atomic.AddInt64(&server.inFlight, -1)
+}
+
+// simulationsInFlight returns how many simulations are currently running
+func (server *Server) simulationsInFlight() int64 {
+	return atomic.LoadInt64(&server.inFlight)
+}
+
 func (server *Server) getPendingPods() ([]*corev1.Pod, error) {
 	pendingPods := []*corev1.Pod{}
 	pods, err := server.podLister.List(labels.Everything())
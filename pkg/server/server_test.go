@@ -0,0 +1,20 @@
+package server
+
+import "testing"
+
+func TestSimulateConcurrencyLimit(t *testing.T) {
+	server := &Server{simulateSlots: make(chan struct{}, 2)}
+	if !server.tryAcquireSimulateSlot() || !server.tryAcquireSimulateSlot() {
+		t.Fatalf("expected the first two acquisitions to succeed")
+	}
+	if server.tryAcquireSimulateSlot() {
+		t.Fatalf("expected the third acquisition to be rejected at limit 2")
+	}
+	if got := server.simulationsInFlight(); got != 2 {
+		t.Errorf("simulationsInFlight() = %d, want 2", got)
+	}
+	server.releaseSimulateSlot()
+	if !server.tryAcquireSimulateSlot() {
+		t.Fatalf("expected acquisition to succeed after a slot was released")
+	}
+}
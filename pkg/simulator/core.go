@@ -1,6 +1,7 @@
 package simulator
 
 import (
+	"fmt"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -10,7 +11,11 @@ import (
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	storagev1 "k8s.io/api/storage/v1"
 
+	simontype "github.com/alibaba/open-simulator/pkg/type"
 	"github.com/alibaba/open-simulator/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
 	utiltrace "k8s.io/utils/trace"
 )
@@ -19,12 +24,18 @@ import (
 type SimulateResult struct {
 	UnscheduledPods []UnscheduledPod `json:"unscheduledPods"`
 	NodeStatus      []NodeStatus     `json:"nodeStatus"`
+	// PVCBindings records per claim which persistent volume satisfied it, or why none
+	// could
+	PVCBindings []PVCBinding `json:"pvcBindings,omitempty"`
 }
 
 // 无法成功调度的 Pod 信息
 type UnscheduledPod struct {
 	Pod    *corev1.Pod `json:"pod"`
 	Reason string      `json:"reason"`
+	// FilterFailures maps, per candidate node, the filter plugin that rejected the pod
+	// to its failure message; only filled when verbose failures are requested
+	FilterFailures map[string]map[string]string `json:"filterFailures,omitempty"`
 }
 
 // 已成功调度的 Pod 信息
@@ -44,16 +55,30 @@ type ResourceTypes struct {
 	ReplicaSets            []*appsv1.ReplicaSet
 	Services               []*corev1.Service
 	PersistentVolumeClaims []*corev1.PersistentVolumeClaim
+	PersistentVolumes      []*corev1.PersistentVolume
 	StorageClasss          []*storagev1.StorageClass
 	PodDisruptionBudgets   []*policyv1beta1.PodDisruptionBudget
 	Jobs                   []*batchv1.Job
 	CronJobs               []*batchv1beta1.CronJob
 	ConfigMaps             []*corev1.ConfigMap
+	CapacityReservations   []CapacityReservation
+}
+
+// CapacityReservation marks a block of resources as unavailable on specific nodes, modeling
+// pre-reserved capacity (e.g. a ProvisioningRequest). The reserved resources are subtracted
+// from the allocatable of the target nodes before any pod is scheduled. An empty node list
+// applies the reservation to every node.
+type CapacityReservation struct {
+	Name      string
+	Nodes     []string
+	Resources corev1.ResourceList
 }
 
 type AppResource struct {
 	Name     string
 	Resource ResourceTypes
+	// DependsOn lists names of apps that must be scheduled before this one
+	DependsOn []string
 }
 
 // Simulate
@@ -104,7 +129,11 @@ func Simulate(cluster ResourceTypes, apps []AppResource, opts ...Option) (*Simul
 	failedPods = append(failedPods, result.UnscheduledPods...)
 	trace.Step("Trace Simulate run cluster done")
 
-	// schedule pods
+	// schedule pods in an order that respects app dependencies
+	apps, err = sortAppsByDependency(apps)
+	if err != nil {
+		return nil, err
+	}
 	for _, app := range apps {
 		result, err = sim.ScheduleApp(app)
 		if err != nil {
@@ -115,5 +144,411 @@ func Simulate(cluster ResourceTypes, apps []AppResource, opts ...Option) (*Simul
 	result.UnscheduledPods = failedPods
 	trace.Step("Trace Simulate schedule app done")
 
+	// bind the claims of the cluster and the deployed apps; pod volumes are simulated
+	// as host paths, so this pass is what surfaces stateful capacity problems
+	if result.PVCBindings, err = sim.bindPersistentVolumeClaims(); err != nil {
+		return nil, err
+	}
+
+	if err := sim.writeScoreDump(); err != nil {
+		return nil, err
+	}
+	if err := sim.writeDebugDump(); err != nil {
+		return nil, err
+	}
+
 	return result, nil
 }
+
+// WaveResult captures the cluster state right after one rollout wave
+type WaveResult struct {
+	// Apps names the apps deployed in this wave
+	Apps []string
+	// UnscheduledPods lists the pods of this wave the scheduler could not place
+	UnscheduledPods []UnscheduledPod
+	// NodeStatus is the cumulative cluster state after the wave
+	NodeStatus []NodeStatus
+}
+
+// SimulateWaves deploys the apps wave by wave on a single simulator instance and
+// snapshots the cluster after each wave, showing how capacity is consumed incrementally
+// and which wave would trigger a scale-out.
+func SimulateWaves(cluster ResourceTypes, waves [][]AppResource, opts ...Option) ([]WaveResult, error) {
+	watch.DefaultChanSize = 1000
+
+	sim, err := NewSimulator(opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		sim.Close()
+	}()
+
+	cluster.Pods, err = GetValidPodExcludeDaemonSet(cluster)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range cluster.DaemonSets {
+		validPods, err := utils.MakeValidPodsByDaemonset(item, cluster.Nodes)
+		if err != nil {
+			return nil, err
+		}
+		cluster.Pods = append(cluster.Pods, validPods...)
+	}
+
+	result, err := sim.RunCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	var waveResults []WaveResult
+	for _, wave := range waves {
+		apps, err := sortAppsByDependency(wave)
+		if err != nil {
+			return nil, err
+		}
+		waveResult := WaveResult{NodeStatus: result.NodeStatus}
+		for _, app := range apps {
+			waveResult.Apps = append(waveResult.Apps, app.Name)
+			result, err = sim.ScheduleApp(app)
+			if err != nil {
+				return nil, err
+			}
+			waveResult.UnscheduledPods = append(waveResult.UnscheduledPods, result.UnscheduledPods...)
+			waveResult.NodeStatus = result.NodeStatus
+		}
+		waveResults = append(waveResults, waveResult)
+	}
+	return waveResults, nil
+}
+
+// DrainResult captures the outcome of rescheduling the pods of a drained node
+type DrainResult struct {
+	// Result is the scheduling outcome of the replacement pods on the remaining nodes
+	Result *SimulateResult
+	// PeakNodes is the number of nodes carrying pods while the drained pods still hold
+	// their resources, i.e. the transient demand of the transition
+	PeakNodes int
+	// SteadyNodes is the number of nodes carrying pods once the drained pods are gone
+	SteadyNodes int
+}
+
+// SimulateDrain cordons the given node and reschedules its pods onto the rest of the
+// cluster. With overlap set the drained pods keep holding their capacity while the
+// replacements are placed, the way a preStop hook or graceful termination lets old and
+// new pod briefly coexist, so the peak demand of the transition is captured instead of
+// only the steady state.
+func SimulateDrain(cluster ResourceTypes, drainedNode string, overlap bool, opts ...Option) (*DrainResult, error) {
+	var nodes []*corev1.Node
+	found := false
+	for _, node := range cluster.Nodes {
+		node := node.DeepCopy()
+		if node.Name == drainedNode {
+			node.Spec.Unschedulable = true
+			found = true
+		}
+		nodes = append(nodes, node)
+	}
+	if !found {
+		return nil, fmt.Errorf("node %s to drain does not exist in the cluster ", drainedNode)
+	}
+	cluster.Nodes = nodes
+
+	// the drained pods are replayed as unbound replacements; with overlap the originals
+	// stay bound in the cluster snapshot, without it they are removed up front
+	var replacements []*corev1.Pod
+	var remainingPods []*corev1.Pod
+	drainedPods := make(map[string]struct{})
+	for _, pod := range cluster.Pods {
+		if pod.Spec.NodeName == drainedNode {
+			replacement := pod.DeepCopy()
+			replacement.Name = fmt.Sprintf("%s-replacement", pod.Name)
+			replacement.UID = ""
+			replacement.ResourceVersion = ""
+			replacement.Spec.NodeName = ""
+			replacement.Status = corev1.PodStatus{}
+			replacements = append(replacements, replacement)
+			drainedPods[fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)] = struct{}{}
+			if !overlap {
+				continue
+			}
+		}
+		remainingPods = append(remainingPods, pod)
+	}
+	cluster.Pods = remainingPods
+
+	apps := []AppResource{{
+		Name:     "drained-pod-replacements",
+		Resource: ResourceTypes{Pods: replacements},
+	}}
+	result, err := Simulate(cluster, apps, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// a node counts towards the steady state only when something besides the
+	// terminating originals runs on it
+	peakNodes, steadyNodes := 0, 0
+	for _, status := range result.NodeStatus {
+		if len(status.Pods) == 0 {
+			continue
+		}
+		peakNodes++
+		for _, pod := range status.Pods {
+			if _, drained := drainedPods[fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)]; !drained {
+				steadyNodes++
+				break
+			}
+		}
+	}
+
+	return &DrainResult{
+		Result:      result,
+		PeakNodes:   peakNodes,
+		SteadyNodes: steadyNodes,
+	}, nil
+}
+
+// PDBViolation records a PodDisruptionBudget that removing a node would break: evicting
+// the node's pods at once leaves fewer healthy replicas than the budget demands
+type PDBViolation struct {
+	// PDB is the namespace/name of the violated budget
+	PDB string `json:"pdb"`
+	// Evicted is the number of pods covered by the budget that live on the removed node
+	Evicted int `json:"evicted"`
+	// Allowed is the number of covered pods the budget lets go down at once
+	Allowed int    `json:"allowed"`
+	Message string `json:"message"`
+}
+
+// RemoveNodeResult captures the outcome of removing a node from the cluster
+type RemoveNodeResult struct {
+	// Result is the scheduling outcome of the evicted pods on the remaining nodes
+	Result *SimulateResult
+	// PDBViolations lists the disruption budgets the simultaneous eviction would break
+	PDBViolations []PDBViolation
+}
+
+// SimulateRemoveNode removes the given node from the cluster and reschedules its pods
+// onto the remaining nodes, the way a scale-down or decommissioning would. Unlike
+// SimulateDrain the node is gone from the snapshot entirely, and the eviction is checked
+// against the given PodDisruptionBudgets: taking down every pod of the node at once may
+// leave a budget below its minimum even when the pods could be rescheduled eventually.
+func SimulateRemoveNode(cluster ResourceTypes, removedNode string, pdbs []*policyv1beta1.PodDisruptionBudget, opts ...Option) (*RemoveNodeResult, error) {
+	var nodes []*corev1.Node
+	found := false
+	for _, node := range cluster.Nodes {
+		if node.Name == removedNode {
+			found = true
+			continue
+		}
+		nodes = append(nodes, node.DeepCopy())
+	}
+	if !found {
+		return nil, fmt.Errorf("node %s to remove does not exist in the cluster ", removedNode)
+	}
+
+	// the evicted pods are replayed as unbound replacements on the shrunken cluster
+	var replacements []*corev1.Pod
+	var remainingPods []*corev1.Pod
+	var evictedPods []*corev1.Pod
+	for _, pod := range cluster.Pods {
+		if pod.Spec.NodeName != removedNode {
+			remainingPods = append(remainingPods, pod)
+			continue
+		}
+		evictedPods = append(evictedPods, pod)
+		replacement := pod.DeepCopy()
+		replacement.Name = fmt.Sprintf("%s-replacement", pod.Name)
+		replacement.UID = ""
+		replacement.ResourceVersion = ""
+		replacement.Spec.NodeName = ""
+		replacement.Status = corev1.PodStatus{}
+		replacements = append(replacements, replacement)
+	}
+
+	violations := validatePodDisruptionBudgets(cluster.Pods, evictedPods, append(append([]*policyv1beta1.PodDisruptionBudget{}, cluster.PodDisruptionBudgets...), pdbs...))
+
+	cluster.Nodes = nodes
+	cluster.Pods = remainingPods
+	apps := []AppResource{{
+		Name:     "evicted-pod-replacements",
+		Resource: ResourceTypes{Pods: replacements},
+	}}
+	result, err := Simulate(cluster, apps, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RemoveNodeResult{
+		Result:        result,
+		PDBViolations: violations,
+	}, nil
+}
+
+// validatePodDisruptionBudgets checks evicting the given pods all at once against each
+// budget and returns the budgets whose allowed disruptions are exceeded
+func validatePodDisruptionBudgets(allPods, evictedPods []*corev1.Pod, pdbs []*policyv1beta1.PodDisruptionBudget) []PDBViolation {
+	var violations []PDBViolation
+	for _, pdb := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			violations = append(violations, PDBViolation{
+				PDB:     fmt.Sprintf("%s/%s", pdb.Namespace, pdb.Name),
+				Message: fmt.Sprintf("invalid label selector: %v", err),
+			})
+			continue
+		}
+		covered := 0
+		for _, pod := range allPods {
+			if pod.Namespace == pdb.Namespace && selector.Matches(labels.Set(pod.Labels)) {
+				covered++
+			}
+		}
+		evicted := 0
+		for _, pod := range evictedPods {
+			if pod.Namespace == pdb.Namespace && selector.Matches(labels.Set(pod.Labels)) {
+				evicted++
+			}
+		}
+		if evicted == 0 {
+			continue
+		}
+
+		allowed := covered
+		if pdb.Spec.MinAvailable != nil {
+			minAvailable, err := intstr.GetValueFromIntOrPercent(pdb.Spec.MinAvailable, covered, true)
+			if err != nil {
+				violations = append(violations, PDBViolation{
+					PDB:     fmt.Sprintf("%s/%s", pdb.Namespace, pdb.Name),
+					Message: fmt.Sprintf("invalid minAvailable: %v", err),
+				})
+				continue
+			}
+			allowed = covered - minAvailable
+		} else if pdb.Spec.MaxUnavailable != nil {
+			maxUnavailable, err := intstr.GetValueFromIntOrPercent(pdb.Spec.MaxUnavailable, covered, false)
+			if err != nil {
+				violations = append(violations, PDBViolation{
+					PDB:     fmt.Sprintf("%s/%s", pdb.Namespace, pdb.Name),
+					Message: fmt.Sprintf("invalid maxUnavailable: %v", err),
+				})
+				continue
+			}
+			allowed = maxUnavailable
+		}
+		if allowed < 0 {
+			allowed = 0
+		}
+		if evicted > allowed {
+			violations = append(violations, PDBViolation{
+				PDB:     fmt.Sprintf("%s/%s", pdb.Namespace, pdb.Name),
+				Evicted: evicted,
+				Allowed: allowed,
+				Message: fmt.Sprintf("evicts %d of %d covered pod(s) at once, but the budget allows only %d disruption(s)", evicted, covered, allowed),
+			})
+		}
+	}
+	return violations
+}
+
+// ScaleOptions configures the node-scaling loop of RunWithScaling
+type ScaleOptions struct {
+	// NewNode is the node template added whenever pods stay unscheduled; nil disables
+	// scaling and RunWithScaling degrades to a single Simulate run
+	NewNode *corev1.Node
+	// MaxNewNodes caps the number of added nodes; zero falls back to
+	// simontype.MaxNumNewNode
+	MaxNewNodes int
+	// SimulatorOptions are passed through to every simulation run of the loop
+	SimulatorOptions []Option
+}
+
+// ScaleResult is the outcome of the node-scaling loop
+type ScaleResult struct {
+	// Result is the scheduling outcome of the last simulation run, including the added
+	// nodes; its UnscheduledPods are non-empty when even the node cap did not suffice
+	Result *SimulateResult
+	// AddedNodes is the number of nodes the loop added to the cluster
+	AddedNodes int
+}
+
+// RunWithScaling simulates the apps against the cluster and, as long as pods stay
+// unscheduled, grows the cluster by one node from the template and retries, the way the
+// apply command does. It is the library entrypoint for consumers that want the scaling
+// behavior without going through the CLI; whether the final state suffices is judged by
+// the UnscheduledPods of the returned result, not by an error.
+func RunWithScaling(cluster ResourceTypes, apps []AppResource, scaleOpts ScaleOptions) (*ScaleResult, error) {
+	maxNewNodes := scaleOpts.MaxNewNodes
+	if maxNewNodes == 0 {
+		maxNewNodes = simontype.MaxNumNewNode
+	}
+	if maxNewNodes < 0 {
+		return nil, fmt.Errorf("invalid max new nodes %d, must be non-negative ", scaleOpts.MaxNewNodes)
+	}
+
+	for addedNodes := 0; ; addedNodes++ {
+		newClusterResource := cluster
+		nodes, err := utils.NewFakeNodesWithNaming(scaleOpts.NewNode, addedNodes, utils.NodeNaming{}, cluster.Nodes)
+		if err != nil {
+			return nil, err
+		}
+		newClusterResource.Nodes = append(append([]*corev1.Node{}, cluster.Nodes...), nodes...)
+		result, err := Simulate(newClusterResource, apps, scaleOpts.SimulatorOptions...)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.UnscheduledPods) == 0 || scaleOpts.NewNode == nil || addedNodes >= maxNewNodes {
+			return &ScaleResult{Result: result, AddedNodes: addedNodes}, nil
+		}
+	}
+}
+
+// sortAppsByDependency returns the apps in a topological order where every app comes after
+// the apps it declares in DependsOn. Apps without dependency relations keep their relative
+// order. A cycle or a dependency on an unknown app is reported as an error.
+func sortAppsByDependency(apps []AppResource) ([]AppResource, error) {
+	indexByName := make(map[string]int, len(apps))
+	for i, app := range apps {
+		indexByName[app.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(apps))
+	sorted := make([]AppResource, 0, len(apps))
+
+	var visit func(index int) error
+	visit = func(index int) error {
+		switch state[index] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle detected involving app %s", apps[index].Name)
+		}
+		state[index] = visiting
+		for _, dependency := range apps[index].DependsOn {
+			dependencyIndex, exist := indexByName[dependency]
+			if !exist {
+				return fmt.Errorf("app %s depends on unknown app %s", apps[index].Name, dependency)
+			}
+			if err := visit(dependencyIndex); err != nil {
+				return err
+			}
+		}
+		state[index] = visited
+		sorted = append(sorted, apps[index])
+		return nil
+	}
+
+	for i := range apps {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
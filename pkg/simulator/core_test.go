@@ -1,7 +1,10 @@
 package simulator
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -14,7 +17,11 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 type args struct {
@@ -361,6 +368,345 @@ func TestSimulate(t *testing.T) {
 	}
 }
 
+func TestSimulateWithCapacityReservation(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("worker-1", "8", "16Gi"),
+		},
+		CapacityReservations: []CapacityReservation{
+			{
+				Name:  "reserved-block",
+				Nodes: []string{"worker-1"},
+				Resources: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("7"),
+				},
+			},
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("big-pod", "default", "2", "1Gi"),
+				},
+			},
+		},
+	}
+
+	got, err := Simulate(cluster, apps)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() scheduled the pod into reserved capacity, got %d unscheduled pod(s)", len(got.UnscheduledPods))
+	}
+}
+
+func TestSimulateWithNamespaceNodeSelector(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("pool-a-1", "8", "16Gi",
+				test.WithNodeLabels(map[string]string{"pool": "a"}),
+			),
+			test.MakeFakeNode("pool-b-1", "8", "16Gi",
+				test.WithNodeLabels(map[string]string{"pool": "b"}),
+			),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("confined-pod", "confined", "1", "1Gi"),
+				},
+			},
+		},
+	}
+
+	got, err := Simulate(cluster, apps, WithNamespaceNodeSelectors(map[string]map[string]string{
+		"confined": {"pool": "a"},
+	}))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 0: %s", len(got.UnscheduledPods), got.UnscheduledPods[0].Reason)
+	}
+	for _, nodeStatus := range got.NodeStatus {
+		for _, pod := range nodeStatus.Pods {
+			if pod.Namespace == "confined" && pod.Spec.NodeName != "pool-a-1" {
+				t.Errorf("pod %s/%s scheduled onto %s, want pool-a-1", pod.Namespace, pod.Name, pod.Spec.NodeName)
+			}
+		}
+	}
+}
+
+func TestSimulateWithScoreDump(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "8", "16Gi"),
+			test.MakeFakeNode("node-2", "8", "16Gi"),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-1", "default", "1", "1Gi"),
+				},
+			},
+		},
+	}
+
+	scoreDumpFile := filepath.Join(t.TempDir(), "scores.csv")
+	if _, err := Simulate(cluster, apps, WithScoreDump(scoreDumpFile)); err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+
+	content, err := ioutil.ReadFile(scoreDumpFile)
+	if err != nil {
+		t.Fatalf("failed to read score dump: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if lines[0] != "pod,node,plugin,score" {
+		t.Errorf("score dump header = %q, want pod,node,plugin,score", lines[0])
+	}
+	if len(lines) < 3 {
+		t.Errorf("score dump has %d line(s), want scores for both candidate nodes", len(lines))
+	}
+}
+
+func TestSimulateWithPendingPods(t *testing.T) {
+	makeCluster := func() ResourceTypes {
+		pendingPod := test.MakeFakePod("pending-pod", "default", "1", "1Gi")
+		boundPod := test.MakeFakePod("bound-pod", "default", "1", "1Gi")
+		boundPod.Spec.NodeName = "node-1"
+		return ResourceTypes{
+			Nodes: []*corev1.Node{
+				test.MakeFakeNode("node-1", "8", "16Gi"),
+			},
+			Pods: []*corev1.Pod{pendingPod, boundPod},
+		}
+	}
+
+	countPods := func(result *SimulateResult) int {
+		pods := 0
+		for _, status := range result.NodeStatus {
+			pods += len(status.Pods)
+		}
+		return pods
+	}
+
+	// by default pending pods from the snapshot are scheduled like the rest
+	got, err := Simulate(makeCluster(), nil)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if pods := countPods(got); pods != 2 {
+		t.Errorf("Simulate() scheduled %d pod(s), want 2", pods)
+	}
+
+	got, err = Simulate(makeCluster(), nil, WithIgnorePendingPods(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if pods := countPods(got); pods != 1 {
+		t.Errorf("Simulate() with ignored pending pods scheduled %d pod(s), want 1", pods)
+	}
+}
+
+func TestSimulateWithPodOverhead(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "2", "4Gi"),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-1", "default", "1500m", "1Gi"),
+				},
+			},
+		},
+	}
+
+	// without the overhead the pod fits on the node
+	got, err := Simulate(cluster, apps)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 0", len(got.UnscheduledPods))
+	}
+
+	// a 1-cpu per-pod tax pushes the demand past the 2-cpu allocatable
+	got, err = Simulate(cluster, apps, WithPodOverhead(corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("1"),
+	}))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() with pod overhead got %d unscheduled pod(s), want 1", len(got.UnscheduledPods))
+	}
+}
+
+func TestSimulateResourceShortfallReason(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("small-node", "2", "4Gi"),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("big-pod", "default", "4", "2Gi"),
+				},
+			},
+		},
+	}
+
+	got, err := Simulate(cluster, apps)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 1", len(got.UnscheduledPods))
+	}
+	reason := got.UnscheduledPods[0].Reason
+	if !strings.Contains(reason, "needs 2 more cpu on the best-fit node small-node") {
+		t.Errorf("Simulate() unscheduled reason %q misses the resource shortfall", reason)
+	}
+}
+
+func TestValidateServiceSpread(t *testing.T) {
+	makeStatus := func(nodeName, zone string, podCount int) NodeStatus {
+		node := test.MakeFakeNode(nodeName, "8", "16Gi",
+			test.WithNodeLabels(map[string]string{corev1.LabelTopologyZone: zone}),
+		)
+		var pods []*corev1.Pod
+		for i := 0; i < podCount; i++ {
+			pod := test.MakeFakePod(fmt.Sprintf("%s-pod-%d", nodeName, i), "default", "1", "1Gi",
+				test.WithPodLabels(map[string]string{"app": "frontend"}),
+			)
+			pod.Spec.NodeName = nodeName
+			pods = append(pods, pod)
+		}
+		return NodeStatus{Node: node, Pods: pods}
+	}
+	nodeStatuses := []NodeStatus{
+		makeStatus("node-a", "zone-a", 3),
+		makeStatus("node-b", "zone-b", 1),
+	}
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "frontend"}},
+		},
+	}
+
+	requirements := []ServiceSpreadRequirement{
+		{Service: "default/frontend", MaxPercent: 50},
+	}
+	violations := ValidateServiceSpread(nodeStatuses, services, requirements)
+	if len(violations) != 1 {
+		t.Fatalf("ValidateServiceSpread() got %d violation(s), want 1: %v", len(violations), violations)
+	}
+	if violations[0].Domain != "zone-a" || violations[0].Pods != 3 || violations[0].TotalPods != 4 {
+		t.Errorf("ValidateServiceSpread() violation = %+v, want zone-a with 3 of 4 pods", violations[0])
+	}
+
+	requirements[0].MaxPercent = 80
+	if violations := ValidateServiceSpread(nodeStatuses, services, requirements); len(violations) != 0 {
+		t.Errorf("ValidateServiceSpread() got %d violation(s), want 0", len(violations))
+	}
+}
+
+func TestValidateTopologySpread(t *testing.T) {
+	constraint := corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       corev1.LabelTopologyZone,
+		WhenUnsatisfiable: corev1.DoNotSchedule,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "frontend"},
+		},
+	}
+	makeStatus := func(nodeName, zone string, podCount int) NodeStatus {
+		node := test.MakeFakeNode(nodeName, "8", "16Gi",
+			test.WithNodeLabels(map[string]string{corev1.LabelTopologyZone: zone}),
+		)
+		var pods []*corev1.Pod
+		for i := 0; i < podCount; i++ {
+			pod := test.MakeFakePod(fmt.Sprintf("%s-pod-%d", nodeName, i), "default", "1", "1Gi",
+				test.WithPodLabels(map[string]string{"app": "frontend"}),
+				test.WithPodNodeName(nodeName),
+			)
+			pod.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{constraint}
+			pods = append(pods, pod)
+		}
+		return NodeStatus{Node: node, Pods: pods}
+	}
+
+	// 3 pods in zone-a against 1 in zone-b is a skew of 2 with maxSkew 1
+	nodeStatuses := []NodeStatus{
+		makeStatus("node-a", "zone-a", 3),
+		makeStatus("node-b", "zone-b", 1),
+	}
+	violations := ValidateTopologySpread(nodeStatuses)
+	if len(violations) != 1 {
+		t.Fatalf("ValidateTopologySpread() got %d violation(s), want the shared constraint once: %v", len(violations), violations)
+	}
+	if violations[0].TopologyKey != corev1.LabelTopologyZone || violations[0].Skew != 2 || violations[0].MaxSkew != 1 {
+		t.Errorf("ValidateTopologySpread() violation = %+v, want skew 2 over maxSkew 1", violations[0])
+	}
+
+	// 2 against 1 keeps the skew within maxSkew
+	nodeStatuses = []NodeStatus{
+		makeStatus("node-a", "zone-a", 2),
+		makeStatus("node-b", "zone-b", 1),
+	}
+	if violations := ValidateTopologySpread(nodeStatuses); len(violations) != 0 {
+		t.Errorf("ValidateTopologySpread() got %d violation(s), want 0", len(violations))
+	}
+}
+
+func TestSortAppsByDependency(t *testing.T) {
+	apps := []AppResource{
+		{Name: "frontend", DependsOn: []string{"backend"}},
+		{Name: "backend", DependsOn: []string{"database"}},
+		{Name: "database"},
+		{Name: "monitoring"},
+	}
+
+	sorted, err := sortAppsByDependency(apps)
+	if err != nil {
+		t.Fatalf("sortAppsByDependency() error = %v", err)
+	}
+	var order []string
+	for _, app := range sorted {
+		order = append(order, app.Name)
+	}
+	want := []string{"database", "backend", "frontend", "monitoring"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("sortAppsByDependency() order = %v, want %v", order, want)
+	}
+
+	cycle := []AppResource{
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"a"}},
+	}
+	if _, err := sortAppsByDependency(cycle); err == nil {
+		t.Errorf("sortAppsByDependency() expected an error for a dependency cycle")
+	}
+}
+
 func checkResult(args args, got *SimulateResult, failedPodsNum int64) (bool, string) {
 	// check number of failed pods
 	if failedPodsNum != int64(len(got.UnscheduledPods)) {
@@ -589,3 +935,1577 @@ func checkResult(args args, got *SimulateResult, failedPodsNum int64) (bool, str
 
 	return true, ""
 }
+
+func TestSimulateWithEmptyDirStorage(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "8", "16Gi",
+				test.WithNodeResources(corev1.ResourceList{
+					corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+				}),
+			),
+		},
+	}
+	makeApps := func(sizeLimit string) []AppResource {
+		limit := resource.MustParse(sizeLimit)
+		return []AppResource{
+			{
+				Name: "app",
+				Resource: ResourceTypes{
+					Pods: []*corev1.Pod{
+						test.MakeFakePod("cache-pod", "default", "1", "1Gi",
+							test.WithPodVolumes([]corev1.Volume{
+								{
+									Name: "scratch",
+									VolumeSource: corev1.VolumeSource{
+										EmptyDir: &corev1.EmptyDirVolumeSource{
+											SizeLimit: &limit,
+										},
+									},
+								},
+							}),
+						),
+					},
+				},
+			},
+		}
+	}
+
+	// a small scratch volume fits within the 10Gi ephemeral storage of the node
+	got, err := Simulate(cluster, makeApps("2Gi"))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 0", len(got.UnscheduledPods))
+	}
+
+	// a sizeLimit beyond the node's ephemeral storage keeps the pod pending
+	got, err = Simulate(cluster, makeApps("20Gi"))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() with oversized emptyDir got %d unscheduled pod(s), want 1", len(got.UnscheduledPods))
+	}
+}
+
+func TestSimulateWithUtilizationCap(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "10", "16Gi"),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-1", "default", "4500m", "1Gi"),
+					test.MakeFakePod("pod-2", "default", "4500m", "1Gi"),
+				},
+			},
+		},
+	}
+
+	// without a cap both pods pack onto the node at 90% cpu utilization
+	got, err := Simulate(cluster, apps)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 0", len(got.UnscheduledPods))
+	}
+
+	// an 80% cpu cap leaves room for only one of the pods, forcing scale-out
+	got, err = Simulate(cluster, apps, WithUtilizationCaps(map[corev1.ResourceName]int64{
+		corev1.ResourceCPU: 80,
+	}))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() with utilization cap got %d unscheduled pod(s), want 1", len(got.UnscheduledPods))
+	}
+	if reason := got.UnscheduledPods[0].Reason; !strings.Contains(reason, "utilization cap") {
+		t.Errorf("unscheduled reason = %q, want it to mention the utilization cap", reason)
+	}
+}
+
+func TestSimulateWithMultipleProfiles(t *testing.T) {
+	schedulerConfig := filepath.Join(t.TempDir(), "scheduler-config.yaml")
+	schedulerConfigContent := `apiVersion: kubescheduler.config.k8s.io/v1beta1
+kind: KubeSchedulerConfiguration
+profiles:
+- schedulerName: default-scheduler
+- schedulerName: binpack-scheduler
+  plugins:
+    score:
+      enabled:
+      - name: NodeResourcesMostAllocated
+      disabled:
+      - name: NodeResourcesLeastAllocated
+      - name: NodeResourcesBalancedAllocation
+`
+	if err := ioutil.WriteFile(schedulerConfig, []byte(schedulerConfigContent), 0644); err != nil {
+		t.Fatalf("failed to write scheduler config: %v", err)
+	}
+
+	// node-2 starts half full so spreading and packing disagree about the best node
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "8", "16Gi"),
+			test.MakeFakeNode("node-2", "8", "16Gi"),
+		},
+		Pods: []*corev1.Pod{
+			test.MakeFakePod("ballast", "default", "4", "8Gi",
+				test.WithPodNodeName("node-2"),
+			),
+		},
+	}
+	packPod := test.MakeFakePod("pod-pack", "default", "1", "1Gi")
+	packPod.Spec.SchedulerName = "binpack-scheduler"
+	apps := []AppResource{
+		{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-spread", "default", "1", "1Gi"),
+					packPod,
+				},
+			},
+		},
+	}
+
+	got, err := Simulate(cluster, apps, WithSchedulerConfig(schedulerConfig))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 0: %v", len(got.UnscheduledPods), got.UnscheduledPods[0].Reason)
+	}
+
+	placements := make(map[string]string)
+	for _, status := range got.NodeStatus {
+		for _, pod := range status.Pods {
+			placements[pod.Name] = status.Node.Name
+		}
+	}
+	if placements["pod-spread"] != "node-1" {
+		t.Errorf("pod-spread placed on %s, want the empty node-1", placements["pod-spread"])
+	}
+	if placements["pod-pack"] != "node-2" {
+		t.Errorf("pod-pack placed on %s, want the fuller node-2", placements["pod-pack"])
+	}
+}
+
+func TestSimulateFeatureLabelReason(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "8", "16Gi"),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("avx-pod", "default", "1", "1Gi",
+						test.WithPodNodeSelector(map[string]string{
+							"feature.node.kubernetes.io/cpu-cpuid.AVX512F": "true",
+						}),
+					),
+				},
+			},
+		},
+	}
+
+	got, err := Simulate(cluster, apps)
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 1", len(got.UnscheduledPods))
+	}
+	reason := got.UnscheduledPods[0].Reason
+	if !strings.Contains(reason, "no node with required feature label feature.node.kubernetes.io/cpu-cpuid.AVX512F") {
+		t.Errorf("unscheduled reason = %q, want it to name the missing feature label", reason)
+	}
+}
+
+func TestSimulateWaves(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "8", "16Gi"),
+		},
+	}
+	waves := [][]AppResource{
+		{
+			{
+				Name: "wave-1-app",
+				Resource: ResourceTypes{
+					Pods: []*corev1.Pod{
+						test.MakeFakePod("pod-1", "default", "1", "1Gi"),
+					},
+				},
+			},
+		},
+		{
+			{
+				Name: "wave-2-app",
+				Resource: ResourceTypes{
+					Pods: []*corev1.Pod{
+						test.MakeFakePod("pod-2", "default", "1", "1Gi"),
+						test.MakeFakePod("pod-3", "default", "1", "1Gi"),
+					},
+				},
+			},
+		},
+	}
+
+	got, err := SimulateWaves(cluster, waves)
+	if err != nil {
+		t.Fatalf("SimulateWaves() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("SimulateWaves() returned %d wave result(s), want 2", len(got))
+	}
+
+	countPods := func(statuses []NodeStatus) int {
+		pods := 0
+		for _, status := range statuses {
+			pods += len(status.Pods)
+		}
+		return pods
+	}
+	if pods := countPods(got[0].NodeStatus); pods != 1 {
+		t.Errorf("after wave 1 the cluster holds %d pod(s), want 1", pods)
+	}
+	if pods := countPods(got[1].NodeStatus); pods != 3 {
+		t.Errorf("after wave 2 the cluster holds %d pod(s), want 3", pods)
+	}
+	if len(got[0].UnscheduledPods) != 0 || len(got[1].UnscheduledPods) != 0 {
+		t.Errorf("SimulateWaves() got unscheduled pods, want none")
+	}
+}
+
+func TestSimulateWithIgnorePodsSelector(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "4", "8Gi"),
+		},
+		Pods: []*corev1.Pod{
+			test.MakeFakePod("debug-pod", "default", "3", "4Gi",
+				test.WithPodLabels(map[string]string{"planning.skip": "true"})),
+			test.MakeFakePod("workload-pod", "default", "3", "4Gi"),
+		},
+	}
+
+	// without the selector both pods compete for the node and one does not fit
+	result, err := Simulate(cluster, nil, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() without ignore-pods got %d unscheduled pod(s), want 1", len(result.UnscheduledPods))
+	}
+
+	// the ignored pod frees up the capacity it would have used
+	result, err = Simulate(cluster, nil, DisablePTerm(true), WithIgnorePodsSelector("planning.skip=true"))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() with ignore-pods got %d unscheduled pod(s), want 0", len(result.UnscheduledPods))
+	}
+	for _, status := range result.NodeStatus {
+		for _, pod := range status.Pods {
+			if pod.Labels["planning.skip"] == "true" {
+				t.Errorf("ignored pod %s was scheduled to node %s", pod.Name, status.Node.Name)
+			}
+		}
+	}
+}
+
+func TestSimulateWithLimitOnlyContainer(t *testing.T) {
+	// both pods declare only limits; the request must default to the limit, so the
+	// second 3-cpu pod cannot fit next to the first on a 4-cpu node
+	limits := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("3"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "4", "8Gi"),
+		},
+		Pods: []*corev1.Pod{
+			test.MakeFakePod("limit-only-1", "default", "", "", test.WithPodLimits(limits)),
+			test.MakeFakePod("limit-only-2", "default", "", "", test.WithPodLimits(limits)),
+		},
+	}
+
+	result, err := Simulate(cluster, nil, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 1", len(result.UnscheduledPods))
+	}
+	if reason := result.UnscheduledPods[0].Reason; !strings.Contains(reason, "Insufficient cpu") {
+		t.Errorf("Simulate() unscheduled reason = %q, want it to report insufficient cpu", reason)
+	}
+}
+
+func TestSimulateAffinityToDaemonSetPod(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "8", "16Gi",
+				test.WithNodeLabels(map[string]string{corev1.LabelHostname: "node-1"})),
+			test.MakeFakeNode("node-2", "8", "16Gi",
+				test.WithNodeLabels(map[string]string{corev1.LabelHostname: "node-2"})),
+		},
+	}
+	// the app lists the affinity pod before the DaemonSet; the DaemonSet pods must
+	// still be placed first or the required inter-pod affinity can never match
+	apps := []AppResource{
+		{
+			Name: "csi",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("wants-csi", "default", "1", "1Gi",
+						test.WithPodAffinity(&corev1.Affinity{
+							PodAffinity: &corev1.PodAffinity{
+								RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+									{
+										LabelSelector: &metav1.LabelSelector{
+											MatchLabels: map[string]string{"app": "csi-node"},
+										},
+										TopologyKey: corev1.LabelHostname,
+									},
+								},
+							},
+						})),
+				},
+				DaemonSets: []*appsv1.DaemonSet{
+					test.MakeFakeDaemonSet("csi-node", "default", "100m", "128Mi",
+						test.WithDaemonSetPodLabels(map[string]string{"app": "csi-node"})),
+				},
+			},
+		},
+	}
+
+	result, err := Simulate(cluster, apps, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 0: %v", len(result.UnscheduledPods), result.UnscheduledPods[0].Reason)
+	}
+	for _, status := range result.NodeStatus {
+		var hasAffinityPod, hasDaemonPod bool
+		for _, pod := range status.Pods {
+			switch {
+			case pod.Name == "wants-csi":
+				hasAffinityPod = true
+			case pod.Labels["app"] == "csi-node":
+				hasDaemonPod = true
+			}
+		}
+		if hasAffinityPod && !hasDaemonPod {
+			t.Errorf("pod wants-csi landed on node %s without a csi-node pod", status.Node.Name)
+		}
+	}
+}
+
+func TestSimulateGangScheduling(t *testing.T) {
+	gpu := corev1.ResourceName("nvidia.com/gpu")
+	makeGangPod := func(name string) *corev1.Pod {
+		pod := test.MakeFakePod(name, "default", "1", "1Gi",
+			test.WithPodLabels(map[string]string{simontype.LabelGangName: "training"}))
+		pod.Spec.Containers[0].Resources.Requests[gpu] = resource.MustParse("1")
+		pod.Spec.Containers[0].Resources.Limits = corev1.ResourceList{gpu: resource.MustParse("1")}
+		return pod
+	}
+	// the node offers 2 GPUs but the 4-GPU gang needs all of its pods placed at once
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "16", "32Gi",
+				test.WithNodeResources(corev1.ResourceList{gpu: resource.MustParse("2")})),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "training",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					makeGangPod("worker-1"), makeGangPod("worker-2"),
+					makeGangPod("worker-3"), makeGangPod("worker-4"),
+				},
+			},
+		},
+	}
+
+	result, err := Simulate(cluster, apps, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 4 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want the whole gang of 4", len(result.UnscheduledPods))
+	}
+	var gangReasons int
+	for _, unscheduled := range result.UnscheduledPods {
+		if strings.Contains(unscheduled.Reason, "gang training could not be scheduled") {
+			gangReasons++
+		}
+	}
+	if gangReasons != 2 {
+		t.Errorf("Simulate() got %d gang rollback reason(s), want 2", gangReasons)
+	}
+	for _, status := range result.NodeStatus {
+		for _, pod := range status.Pods {
+			if pod.Labels[simontype.LabelGangName] == "training" {
+				t.Errorf("gang pod %s is still placed on node %s", pod.Name, status.Node.Name)
+			}
+		}
+	}
+}
+
+func TestSimulateVGSelectionPolicy(t *testing.T) {
+	// vg-a is empty and vg-b is more than half full, so least-allocated must land the
+	// volume in vg-a while most-allocated keeps filling vg-b
+	gi := int64(1024 * 1024 * 1024)
+	nodeStorage := utils.NodeStorage{
+		VGs: []localcache.SharedResource{
+			{Name: "vg-a", Capacity: 100 * gi, Requested: 0},
+			{Name: "vg-b", Capacity: 100 * gi, Requested: 60 * gi},
+		},
+	}
+	podStorage, err := json.Marshal(utils.VolumeRequest{
+		Volumes: []utils.Volume{
+			{Size: 10 * gi, Kind: "LVM", StorageClassName: utils.OpenLocalSCNameLVM},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal pod storage: %v", err)
+	}
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "4", "8Gi",
+				test.WithNodeLocalStorage(nodeStorage)),
+		},
+		StorageClasss: []*storagev1.StorageClass{
+			{
+				ObjectMeta:  metav1.ObjectMeta{Name: utils.OpenLocalSCNameLVM},
+				Provisioner: "local.csi.aliyun.com",
+				Parameters:  map[string]string{"volumeType": "LVM"},
+			},
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "storage-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("storage-pod", "default", "1", "1Gi",
+						test.WithPodAnnotations(map[string]string{simontype.AnnoPodLocalStorage: string(podStorage)})),
+				},
+			},
+		},
+	}
+
+	vgRequested := func(policy string) map[string]int64 {
+		result, err := Simulate(cluster, apps, DisablePTerm(true), WithVGSelectionPolicy(policy))
+		if err != nil {
+			t.Fatalf("Simulate() with policy %s error = %v", policy, err)
+		}
+		if len(result.UnscheduledPods) != 0 {
+			t.Fatalf("Simulate() with policy %s got %d unscheduled pod(s), want 0", policy, len(result.UnscheduledPods))
+		}
+		requested := make(map[string]int64)
+		for _, status := range result.NodeStatus {
+			storage, err := utils.GetNodeStorage(status.Node)
+			if err != nil {
+				t.Fatalf("failed to read node storage: %v", err)
+			}
+			if storage == nil {
+				continue
+			}
+			for _, vg := range storage.VGs {
+				requested[vg.Name] = vg.Requested
+			}
+			for _, pod := range status.Pods {
+				if pod.Name == "storage-pod" && pod.Annotations[simontype.AnnoPodVolumeVGs] == "" {
+					t.Errorf("pod %s carries no volume placement annotation", pod.Name)
+				}
+			}
+		}
+		return requested
+	}
+
+	requested := vgRequested("least-allocated")
+	if requested["vg-a"] != 10*gi || requested["vg-b"] != 60*gi {
+		t.Errorf("least-allocated filled vg-a=%d vg-b=%d, want the volume in vg-a", requested["vg-a"], requested["vg-b"])
+	}
+	requested = vgRequested("most-allocated")
+	if requested["vg-a"] != 0 || requested["vg-b"] != 70*gi {
+		t.Errorf("most-allocated filled vg-a=%d vg-b=%d, want the volume in vg-b", requested["vg-a"], requested["vg-b"])
+	}
+}
+
+func TestSimulateNodeVolumeLimit(t *testing.T) {
+	// the VG has room for both volumes, so only the attach limit of one volume per
+	// node forces the second storage pod onto a second node
+	gi := int64(1024 * 1024 * 1024)
+	nodeStorage := utils.NodeStorage{
+		VGs: []localcache.SharedResource{
+			{Name: "vg-a", Capacity: 1000 * gi},
+		},
+	}
+	podStorage, err := json.Marshal(utils.VolumeRequest{
+		Volumes: []utils.Volume{
+			{Size: 10 * gi, Kind: "LVM", StorageClassName: utils.OpenLocalSCNameLVM},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal pod storage: %v", err)
+	}
+	makeStorageNode := func(name string) *corev1.Node {
+		return test.MakeFakeNode(name, "8", "16Gi",
+			test.WithNodeAnnotations(map[string]string{simontype.AnnoNodeVolumeLimit: "1"}),
+			test.WithNodeLocalStorage(nodeStorage))
+	}
+	makeStoragePod := func(name string) *corev1.Pod {
+		return test.MakeFakePod(name, "default", "1", "1Gi",
+			test.WithPodAnnotations(map[string]string{simontype.AnnoPodLocalStorage: string(podStorage)}))
+	}
+	apps := []AppResource{
+		{
+			Name: "storage-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					makeStoragePod("storage-pod-1"),
+					makeStoragePod("storage-pod-2"),
+				},
+			},
+		},
+	}
+
+	// one node takes only one of the two volumes
+	cluster := ResourceTypes{Nodes: []*corev1.Node{makeStorageNode("node-1")}}
+	result, err := Simulate(cluster, apps, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 1", len(result.UnscheduledPods))
+	}
+	if !strings.Contains(result.UnscheduledPods[0].Reason, "node volume attach limit exceeded") {
+		t.Errorf("Simulate() reason = %q, want the volume attach limit", result.UnscheduledPods[0].Reason)
+	}
+
+	// a second node provides the missing attach slot
+	cluster = ResourceTypes{Nodes: []*corev1.Node{makeStorageNode("node-1"), makeStorageNode("node-2")}}
+	result, err = Simulate(cluster, apps, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() with a second node got %d unscheduled pod(s), want 0", len(result.UnscheduledPods))
+	}
+}
+
+func TestSimulateDrain(t *testing.T) {
+	makeCluster := func() ResourceTypes {
+		return ResourceTypes{
+			Nodes: []*corev1.Node{
+				test.MakeFakeNode("node-1", "8", "16Gi"),
+				test.MakeFakeNode("node-2", "8", "16Gi"),
+			},
+			Pods: []*corev1.Pod{
+				test.MakeFakePod("old-pod", "default", "6", "8Gi", test.WithPodNodeName("node-1")),
+			},
+		}
+	}
+
+	// with overlap the terminating pod still occupies node-1 while its replacement
+	// lands on node-2, so the transition needs one node more than the steady state
+	got, err := SimulateDrain(makeCluster(), "node-1", true, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("SimulateDrain() error = %v", err)
+	}
+	if len(got.Result.UnscheduledPods) != 0 {
+		t.Fatalf("SimulateDrain() got %d unscheduled pod(s), want 0", len(got.Result.UnscheduledPods))
+	}
+	if got.PeakNodes != 2 || got.SteadyNodes != 1 {
+		t.Errorf("SimulateDrain() with overlap got peak %d, steady %d node(s), want 2 and 1", got.PeakNodes, got.SteadyNodes)
+	}
+	replacementNode := ""
+	for _, status := range got.Result.NodeStatus {
+		for _, pod := range status.Pods {
+			if pod.Name == "old-pod-replacement" {
+				replacementNode = status.Node.Name
+			}
+		}
+	}
+	if replacementNode != "node-2" {
+		t.Errorf("replacement pod landed on %q, want node-2", replacementNode)
+	}
+
+	// without overlap old and new pod never coexist, so no spike shows up
+	got, err = SimulateDrain(makeCluster(), "node-1", false, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("SimulateDrain() error = %v", err)
+	}
+	if got.PeakNodes != 1 || got.SteadyNodes != 1 {
+		t.Errorf("SimulateDrain() without overlap got peak %d, steady %d node(s), want 1 and 1", got.PeakNodes, got.SteadyNodes)
+	}
+
+	if _, err := SimulateDrain(makeCluster(), "node-3", false, DisablePTerm(true)); err == nil {
+		t.Errorf("SimulateDrain() of an unknown node expected an error")
+	}
+}
+
+func TestSimulateRemoveNode(t *testing.T) {
+	makePod := func(name, nodeName string) *corev1.Pod {
+		return test.MakeFakePod(name, "default", "1", "1Gi",
+			test.WithPodLabels(map[string]string{"app": "frontend"}),
+			test.WithPodNodeName(nodeName),
+		)
+	}
+	makeCluster := func() ResourceTypes {
+		return ResourceTypes{
+			Nodes: []*corev1.Node{
+				test.MakeFakeNode("node-1", "8", "16Gi"),
+				test.MakeFakeNode("node-2", "8", "16Gi"),
+			},
+			Pods: []*corev1.Pod{
+				makePod("frontend-1", "node-1"),
+				makePod("frontend-2", "node-1"),
+				makePod("frontend-3", "node-2"),
+			},
+		}
+	}
+	minAvailable := intstr.FromInt(2)
+	pdbs := []*policyv1beta1.PodDisruptionBudget{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend-pdb", Namespace: "default"},
+			Spec: policyv1beta1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": "frontend"},
+				},
+			},
+		},
+	}
+
+	// evicting both frontend pods of node-1 at once leaves 1 of 3 replicas, below the
+	// required 2, even though node-2 has room to reschedule them
+	got, err := SimulateRemoveNode(makeCluster(), "node-1", pdbs, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("SimulateRemoveNode() error = %v", err)
+	}
+	if len(got.Result.UnscheduledPods) != 0 {
+		t.Fatalf("SimulateRemoveNode() got %d unscheduled pod(s), want 0", len(got.Result.UnscheduledPods))
+	}
+	if len(got.PDBViolations) != 1 {
+		t.Fatalf("SimulateRemoveNode() got %d pdb violation(s), want 1: %v", len(got.PDBViolations), got.PDBViolations)
+	}
+	if violation := got.PDBViolations[0]; violation.PDB != "default/frontend-pdb" || violation.Evicted != 2 || violation.Allowed != 1 {
+		t.Errorf("SimulateRemoveNode() violation = %+v, want 2 evicted against 1 allowed", violation)
+	}
+	for _, status := range got.Result.NodeStatus {
+		if status.Node.Name == "node-1" {
+			t.Errorf("removed node %s is still part of the result", status.Node.Name)
+		}
+	}
+
+	// taking down node-2 evicts only one covered pod, which the budget allows
+	got, err = SimulateRemoveNode(makeCluster(), "node-2", pdbs, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("SimulateRemoveNode() error = %v", err)
+	}
+	if len(got.PDBViolations) != 0 {
+		t.Errorf("SimulateRemoveNode() got %d pdb violation(s), want 0: %v", len(got.PDBViolations), got.PDBViolations)
+	}
+
+	if _, err := SimulateRemoveNode(makeCluster(), "node-3", nil, DisablePTerm(true)); err == nil {
+		t.Errorf("SimulateRemoveNode() of an unknown node expected an error")
+	}
+}
+
+func TestDeschedule(t *testing.T) {
+	// node-a runs at 87% cpu while node-b is empty; moving the largest pod over
+	// relieves node-a without overloading node-b
+	result := &SimulateResult{
+		NodeStatus: []NodeStatus{
+			{
+				Node: test.MakeFakeNode("node-a", "4", "8Gi"),
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-large", "default", "2", "2Gi", test.WithPodNodeName("node-a")),
+					test.MakeFakePod("pod-small", "default", "1500m", "1500Mi", test.WithPodNodeName("node-a")),
+				},
+			},
+			{
+				Node: test.MakeFakeNode("node-b", "4", "8Gi"),
+			},
+		},
+	}
+
+	got, err := Deschedule(result, DescheduleOptions{})
+	if err != nil {
+		t.Fatalf("Deschedule() error = %v", err)
+	}
+	if len(got.Moves) != 1 {
+		t.Fatalf("Deschedule() made %d move(s), want 1: %v", len(got.Moves), got.Moves)
+	}
+	if move := got.Moves[0]; move.Pod != "default/pod-large" || move.From != "node-a" || move.To != "node-b" {
+		t.Errorf("Deschedule() move = %+v, want the largest pod from node-a to node-b", move)
+	}
+	for _, status := range got.Result.NodeStatus {
+		if utilization := NodeUtilizationPercent(status); utilization > 80 {
+			t.Errorf("node %s still at %d%% after descheduling", status.Node.Name, utilization)
+		}
+	}
+	// the input placement must stay untouched
+	if len(result.NodeStatus[0].Pods) != 2 || len(result.NodeStatus[1].Pods) != 0 {
+		t.Errorf("Deschedule() modified the input result")
+	}
+
+	if _, err := Deschedule(result, DescheduleOptions{HighThreshold: 30, LowThreshold: 50}); err == nil {
+		t.Errorf("Deschedule() with low above high expected an error")
+	}
+}
+
+func TestSimulateEphemeralStorage(t *testing.T) {
+	// ephemeral-storage is schedulable like cpu and memory: a pod requesting more than
+	// the node's allocatable must not be placed
+	makeStoragePod := func(name, ephemeral string) *corev1.Pod {
+		pod := test.MakeFakePod(name, "default", "1", "1Gi")
+		pod.Spec.Containers[0].Resources.Requests[corev1.ResourceEphemeralStorage] = resource.MustParse(ephemeral)
+		return pod
+	}
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "4", "8Gi",
+				test.WithNodeResources(corev1.ResourceList{corev1.ResourceEphemeralStorage: resource.MustParse("10Gi")})),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "storage-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					makeStoragePod("fits", "5Gi"),
+					makeStoragePod("too-big", "20Gi"),
+				},
+			},
+		},
+	}
+
+	result, err := Simulate(cluster, apps, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want only the pod above the node's ephemeral storage", len(result.UnscheduledPods))
+	}
+	if name := result.UnscheduledPods[0].Pod.Name; name != "too-big" {
+		t.Errorf("Simulate() left %s unscheduled, want too-big", name)
+	}
+}
+
+func TestSimulateInitContainerRequests(t *testing.T) {
+	// a pod's effective request is max(sum of regular containers, largest init
+	// container); this pins the scheduler down to those semantics
+	makeInitPod := func(name, cpu, memory, initCPU, initMemory string) *corev1.Pod {
+		return test.MakeFakePod(name, "default", cpu, memory,
+			test.WithPodInitContainers([]corev1.Container{
+				{
+					Name:  "init",
+					Image: "busybox",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(initCPU),
+							corev1.ResourceMemory: resource.MustParse(initMemory),
+						},
+					},
+				},
+			}),
+		)
+	}
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "4", "8Gi"),
+		},
+	}
+
+	// the init-heavy pod counts as 3 cpu, not 2+3=5, so it and the 1-cpu pod fill the
+	// 4-cpu node exactly
+	apps := []AppResource{
+		{
+			Name: "init-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					makeInitPod("init-heavy", "2", "2Gi", "3", "3Gi"),
+					test.MakeFakePod("regular", "default", "1", "1Gi"),
+				},
+			},
+		},
+	}
+	result, err := Simulate(cluster, apps, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 0; the init request must not be added to the regular containers", len(result.UnscheduledPods))
+	}
+
+	// an init container above the node's allocatable blocks the pod even though its
+	// regular containers would fit
+	apps = []AppResource{
+		{
+			Name: "oversized-init-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					makeInitPod("oversized-init", "1", "1Gi", "5", "1Gi"),
+				},
+			},
+		},
+	}
+	result, err = Simulate(cluster, apps, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want the oversized init container to block the pod", len(result.UnscheduledPods))
+	}
+}
+
+func TestSimulateAppRequestMultiplier(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "4", "8Gi"),
+		},
+	}
+	// at full requests the two apps (3 cpu each) would not fit the 4 cpu node; the
+	// canary running at 0.1x of production scale makes room
+	apps := []AppResource{
+		{
+			Name: "prod-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("prod-pod", "default", "3", "3000Mi"),
+				},
+			},
+		},
+		{
+			Name: "canary-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("canary-pod", "default", "3", "3000Mi"),
+				},
+			},
+		},
+	}
+
+	result, err := Simulate(cluster, apps, DisablePTerm(true), WithAppRequestMultipliers(map[string]float64{"canary-app": 0.1}))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() got %d unscheduled pod(s), want 0", len(result.UnscheduledPods))
+	}
+
+	requestsByPod := make(map[string]*corev1.ResourceList)
+	for _, status := range result.NodeStatus {
+		for _, pod := range status.Pods {
+			requestsByPod[pod.Name] = &pod.Spec.Containers[0].Resources.Requests
+		}
+	}
+	if cpu := requestsByPod["canary-pod"].Cpu(); cpu.MilliValue() != 300 {
+		t.Errorf("canary pod requests %s cpu, want 300m", cpu)
+	}
+	if memory := requestsByPod["canary-pod"].Memory(); memory.Value() != 300*1024*1024 {
+		t.Errorf("canary pod requests %s memory, want 300Mi", memory)
+	}
+	if cpu := requestsByPod["prod-pod"].Cpu(); cpu.MilliValue() != 3000 {
+		t.Errorf("prod pod requests %s cpu, want 3", cpu)
+	}
+
+	if _, err := Simulate(cluster, apps, DisablePTerm(true), WithAppRequestMultipliers(map[string]float64{"canary-app": -1})); err == nil {
+		t.Errorf("Simulate() with a negative multiplier expected an error")
+	}
+}
+
+func TestSimulateNativeSidecar(t *testing.T) {
+	// main container 1 cpu, an ordinary init container of 500m and a 2 cpu sidecar;
+	// with 1.28 sidecar semantics the steady-state demand is 3 cpu per pod, while the
+	// plain init-maximum accounting would only see 2 cpu
+	makePods := func(sidecar bool) []*corev1.Pod {
+		var opts []test.FakePodOption
+		opts = append(opts, test.WithPodInitContainers([]corev1.Container{
+			{
+				Name:  "setup",
+				Image: "busybox",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				},
+			},
+			{
+				Name:  "proxy",
+				Image: "envoy",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+				},
+			},
+		}))
+		if sidecar {
+			opts = append(opts, test.WithPodAnnotations(map[string]string{
+				simontype.AnnoPodSidecarContainers: "proxy",
+			}))
+		}
+		return []*corev1.Pod{
+			test.MakeFakePod("pod-1", "default", "1", "1Gi", opts...),
+			test.MakeFakePod("pod-2", "default", "1", "1Gi", opts...),
+		}
+	}
+	makeApps := func(sidecar bool) []AppResource {
+		return []AppResource{{
+			Name:     "sidecar-app",
+			Resource: ResourceTypes{Pods: makePods(sidecar)},
+		}}
+	}
+	makeCluster := func() ResourceTypes {
+		return ResourceTypes{Nodes: []*corev1.Node{test.MakeFakeNode("node-1", "4", "8Gi")}}
+	}
+
+	// without the sidecar annotation both pods pass the init-maximum accounting
+	result, err := Simulate(makeCluster(), makeApps(false), DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() without sidecar got %d unscheduled pod(s), want 0", len(result.UnscheduledPods))
+	}
+
+	// with the sidecar counted into steady-state demand only one pod fits the node
+	result, err = Simulate(makeCluster(), makeApps(true), DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() with sidecar got %d unscheduled pod(s), want 1", len(result.UnscheduledPods))
+	}
+	for _, status := range result.NodeStatus {
+		for _, pod := range status.Pods {
+			if len(pod.Spec.Containers) != 2 || len(pod.Spec.InitContainers) != 1 {
+				t.Errorf("pod %s has %d container(s) and %d init container(s), want the sidecar moved into the regular containers",
+					pod.Name, len(pod.Spec.Containers), len(pod.Spec.InitContainers))
+			}
+		}
+	}
+}
+
+func TestSimulateWithDebugDump(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "8", "16Gi"),
+			test.MakeFakeNode("node-2", "8", "16Gi"),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-1", "default", "1", "1Gi"),
+					test.MakeFakePod("huge-pod", "default", "32", "1Gi"),
+				},
+			},
+		},
+	}
+
+	debugDumpFile := filepath.Join(t.TempDir(), "debug.json")
+	if _, err := Simulate(cluster, apps, DisablePTerm(true), WithDebugDump(debugDumpFile)); err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+
+	content, err := ioutil.ReadFile(debugDumpFile)
+	if err != nil {
+		t.Fatalf("failed to read debug dump: %v", err)
+	}
+	var records []struct {
+		Pod            string            `json:"pod"`
+		ChosenNode     string            `json:"chosenNode"`
+		FilterVerdicts map[string]string `json:"filterVerdicts"`
+	}
+	if err := json.Unmarshal(content, &records); err != nil {
+		t.Fatalf("failed to unmarshal debug dump: %v", err)
+	}
+	recordByPod := make(map[string]int)
+	for i, record := range records {
+		recordByPod[record.Pod] = i
+	}
+
+	placed, exist := recordByPod["default/pod-1"]
+	if !exist {
+		t.Fatalf("debug dump misses a record for default/pod-1")
+	}
+	if records[placed].ChosenNode == "" {
+		t.Errorf("debug dump record of pod-1 has no chosen node")
+	}
+	if len(records[placed].FilterVerdicts) != 2 {
+		t.Errorf("debug dump record of pod-1 has %d filter verdict(s), want one per node", len(records[placed].FilterVerdicts))
+	}
+
+	failed, exist := recordByPod["default/huge-pod"]
+	if !exist {
+		t.Fatalf("debug dump misses a record for default/huge-pod")
+	}
+	if records[failed].ChosenNode != "" {
+		t.Errorf("debug dump record of huge-pod names node %s, want none", records[failed].ChosenNode)
+	}
+	for node, verdict := range records[failed].FilterVerdicts {
+		if !strings.Contains(verdict, "Insufficient cpu") {
+			t.Errorf("debug dump verdict of huge-pod on %s = %q, want Insufficient cpu", node, verdict)
+		}
+	}
+}
+
+func TestSimulateSystemOverheadOnGeneratedNodes(t *testing.T) {
+	// nodes generated from a template get the same all-node reservation as the rest of
+	// the cluster, so simulated headroom already excludes the system pods
+	makeCluster := func(reserve bool) ResourceTypes {
+		template := test.MakeFakeNode("node-template", "4", "8Gi")
+		nodes, err := utils.NewFakeNodesWithNaming(template, 1, utils.NodeNaming{}, nil)
+		if err != nil {
+			t.Fatalf("NewFakeNodesWithNaming() error = %v", err)
+		}
+		cluster := ResourceTypes{Nodes: nodes}
+		if reserve {
+			cluster.CapacityReservations = []CapacityReservation{
+				{
+					Name: "system-pods",
+					Resources: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+				},
+			}
+		}
+		return cluster
+	}
+	makeApps := func() []AppResource {
+		return []AppResource{{
+			Name: "app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("big-pod", "default", "3500m", "1Gi"),
+				},
+			},
+		}}
+	}
+
+	// without the reservation the generated node has room for the pod
+	got, err := Simulate(makeCluster(false), makeApps(), DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 0 {
+		t.Fatalf("Simulate() without reservation got %d unscheduled pod(s), want 0", len(got.UnscheduledPods))
+	}
+
+	// the system overhead is reserved before any app pod is placed
+	got, err = Simulate(makeCluster(true), makeApps(), DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(got.UnscheduledPods) != 1 {
+		t.Fatalf("Simulate() scheduled the pod into the system reservation, got %d unscheduled pod(s)", len(got.UnscheduledPods))
+	}
+	for _, status := range got.NodeStatus {
+		if _, exist := status.Node.Annotations[simontype.AnnoNodeReservedResource]; !exist {
+			t.Errorf("generated node %s carries no reservation annotation", status.Node.Name)
+		}
+	}
+}
+
+func TestRunWithScaling(t *testing.T) {
+	makeCluster := func() ResourceTypes {
+		return ResourceTypes{
+			Nodes: []*corev1.Node{
+				test.MakeFakeNode("node-1", "4", "8Gi"),
+			},
+		}
+	}
+	apps := []AppResource{
+		{
+			Name: "demo-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-1", "default", "3", "3Gi"),
+					test.MakeFakePod("pod-2", "default", "3", "3Gi"),
+					test.MakeFakePod("pod-3", "default", "3", "3Gi"),
+				},
+			},
+		},
+	}
+	template := test.MakeFakeNode("template", "4", "8Gi")
+
+	scaleResult, err := RunWithScaling(makeCluster(), apps, ScaleOptions{NewNode: template, SimulatorOptions: []Option{DisablePTerm(true)}})
+	if err != nil {
+		t.Fatalf("failed to run with scaling: %v", err)
+	}
+	if scaleResult.AddedNodes != 2 {
+		t.Fatalf("expect 2 added nodes, got %d", scaleResult.AddedNodes)
+	}
+	if len(scaleResult.Result.UnscheduledPods) != 0 {
+		t.Fatalf("expect all pods scheduled, got %d unscheduled", len(scaleResult.Result.UnscheduledPods))
+	}
+
+	scaleResult, err = RunWithScaling(makeCluster(), apps, ScaleOptions{NewNode: template, MaxNewNodes: 1, SimulatorOptions: []Option{DisablePTerm(true)}})
+	if err != nil {
+		t.Fatalf("failed to run with capped scaling: %v", err)
+	}
+	if scaleResult.AddedNodes != 1 || len(scaleResult.Result.UnscheduledPods) != 1 {
+		t.Fatalf("expect the cap to leave 1 pod unscheduled after 1 added node, got %d added and %d unscheduled", scaleResult.AddedNodes, len(scaleResult.Result.UnscheduledPods))
+	}
+
+	scaleResult, err = RunWithScaling(makeCluster(), apps, ScaleOptions{SimulatorOptions: []Option{DisablePTerm(true)}})
+	if err != nil {
+		t.Fatalf("failed to run without a template: %v", err)
+	}
+	if scaleResult.AddedNodes != 0 || len(scaleResult.Result.UnscheduledPods) != 2 {
+		t.Fatalf("expect a single run without scaling, got %d added and %d unscheduled", scaleResult.AddedNodes, len(scaleResult.Result.UnscheduledPods))
+	}
+
+	if _, err := RunWithScaling(makeCluster(), apps, ScaleOptions{NewNode: template, MaxNewNodes: -1}); err == nil {
+		t.Fatal("expect an error for a negative max new nodes")
+	}
+}
+
+func TestSimulateVerboseFailures(t *testing.T) {
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "4", "8Gi"),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "demo-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("huge-pod", "default", "32", "4Gi"),
+				},
+			},
+		},
+	}
+
+	result, err := Simulate(cluster, apps, DisablePTerm(true), WithVerboseFailures(true))
+	if err != nil {
+		t.Fatalf("failed to simulate: %v", err)
+	}
+	if len(result.UnscheduledPods) != 1 {
+		t.Fatalf("expect 1 unscheduled pod, got %d", len(result.UnscheduledPods))
+	}
+	failures := result.UnscheduledPods[0].FilterFailures["node-1"]
+	if len(failures) == 0 {
+		t.Fatalf("expect filter failures for node-1, got %+v", result.UnscheduledPods[0].FilterFailures)
+	}
+	found := false
+	for plugin, message := range failures {
+		if plugin == "NodeResourcesFit" && strings.Contains(message, "Insufficient cpu") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expect NodeResourcesFit to report insufficient cpu, got %+v", failures)
+	}
+
+	result, err = Simulate(cluster, apps, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("failed to simulate without verbose failures: %v", err)
+	}
+	if len(result.UnscheduledPods) != 1 || result.UnscheduledPods[0].FilterFailures != nil {
+		t.Fatalf("expect no filter failures by default, got %+v", result.UnscheduledPods)
+	}
+}
+
+func TestCreateClusterResourceFromSnapshotDir(t *testing.T) {
+	dir := t.TempDir()
+	nodeYaml := `apiVersion: v1
+kind: Node
+metadata:
+  name: node-1
+status:
+  allocatable:
+    cpu: "8"
+    memory: 16Gi
+`
+	// kubectl get -o yaml wraps the dumped objects in a v1 List
+	podListYaml := `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: pod-1
+    namespace: default
+  spec:
+    nodeName: node-1
+    containers:
+    - name: container
+      image: image
+- apiVersion: v1
+  kind: PersistentVolumeClaim
+  metadata:
+    name: pvc-1
+    namespace: default
+  spec:
+    resources:
+      requests:
+        storage: 10Gi
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "nodes.yaml"), []byte(nodeYaml), 0644); err != nil {
+		t.Fatalf("failed to write node dump: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "pods.yaml"), []byte(podListYaml), 0644); err != nil {
+		t.Fatalf("failed to write pod dump: %v", err)
+	}
+
+	cluster, err := CreateClusterResourceFromSnapshotDir(dir)
+	if err != nil {
+		t.Fatalf("failed to load snapshot dir: %v", err)
+	}
+	if len(cluster.Nodes) != 1 || cluster.Nodes[0].Name != "node-1" {
+		t.Fatalf("expect node-1 from the snapshot, got %+v", cluster.Nodes)
+	}
+	if len(cluster.Pods) != 1 || cluster.Pods[0].Name != "pod-1" {
+		t.Fatalf("expect pod-1 from the unwrapped list, got %+v", cluster.Pods)
+	}
+	if len(cluster.PersistentVolumeClaims) != 1 || cluster.PersistentVolumeClaims[0].Name != "pvc-1" {
+		t.Fatalf("expect pvc-1 from the unwrapped list, got %+v", cluster.PersistentVolumeClaims)
+	}
+}
+
+func TestSimulateScoringStrategy(t *testing.T) {
+	// one node already half loaded, one empty; the strategy decides where a small pod
+	// ends up
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("busy-node", "8", "16Gi"),
+			test.MakeFakeNode("empty-node", "8", "16Gi"),
+		},
+		Pods: []*corev1.Pod{
+			test.MakeFakePod("existing-pod", "default", "4", "8Gi", test.WithPodNodeName("busy-node")),
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "demo-app",
+			Resource: ResourceTypes{
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("new-pod", "default", "1", "1Gi"),
+				},
+			},
+		},
+	}
+
+	nodeOf := func(result *SimulateResult) string {
+		for _, status := range result.NodeStatus {
+			for _, pod := range status.Pods {
+				if pod.Name == "new-pod" {
+					return status.Node.Name
+				}
+			}
+		}
+		return ""
+	}
+
+	result, err := Simulate(cluster, apps, DisablePTerm(true), WithScoringStrategy(ScoringStrategyMostAllocated))
+	if err != nil {
+		t.Fatalf("failed to simulate with most-allocated: %v", err)
+	}
+	if node := nodeOf(result); node != "busy-node" {
+		t.Fatalf("expect most-allocated to pack onto busy-node, got %s", node)
+	}
+
+	result, err = Simulate(cluster, apps, DisablePTerm(true), WithScoringStrategy(ScoringStrategyLeastAllocated))
+	if err != nil {
+		t.Fatalf("failed to simulate with least-allocated: %v", err)
+	}
+	if node := nodeOf(result); node != "empty-node" {
+		t.Fatalf("expect least-allocated to spread onto empty-node, got %s", node)
+	}
+
+	if _, err := Simulate(cluster, apps, DisablePTerm(true), WithScoringStrategy("bin-pack")); err == nil {
+		t.Fatal("expect an error for an unknown scoring strategy")
+	}
+}
+
+func TestSimulatePVCBinding(t *testing.T) {
+	// two static volumes, one dynamic class and one class that exists nowhere cover the
+	// four outcomes: bound to the smallest fit, provisioned, stuck and missing class
+	staticClass := "local-static"
+	dynamicClass := "dynamic"
+	ghostClass := "ghost"
+	makeVolume := func(name, capacity string) *corev1.PersistentVolume {
+		return &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(capacity),
+				},
+				StorageClassName: staticClass,
+			},
+		}
+	}
+	makeClaim := func(name, capacity string, class *string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: class,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse(capacity),
+					},
+				},
+			},
+		}
+	}
+	cluster := ResourceTypes{
+		Nodes: []*corev1.Node{
+			test.MakeFakeNode("node-1", "8", "16Gi"),
+		},
+		PersistentVolumes: []*corev1.PersistentVolume{
+			makeVolume("pv-big", "20Gi"),
+			makeVolume("pv-small", "5Gi"),
+		},
+		StorageClasss: []*storagev1.StorageClass{
+			{
+				ObjectMeta:  metav1.ObjectMeta{Name: staticClass},
+				Provisioner: "kubernetes.io/no-provisioner",
+			},
+			{
+				ObjectMeta:  metav1.ObjectMeta{Name: dynamicClass},
+				Provisioner: "csi.example.com",
+			},
+		},
+	}
+	apps := []AppResource{
+		{
+			Name: "storage-app",
+			Resource: ResourceTypes{
+				PersistentVolumeClaims: []*corev1.PersistentVolumeClaim{
+					makeClaim("claim-static", "4Gi", &staticClass),
+					makeClaim("claim-dynamic", "8Gi", &dynamicClass),
+					makeClaim("claim-stuck", "50Gi", &staticClass),
+					makeClaim("claim-missing", "1Gi", &ghostClass),
+				},
+			},
+		},
+	}
+
+	result, err := Simulate(cluster, apps, DisablePTerm(true))
+	if err != nil {
+		t.Fatalf("Simulate() error = %v", err)
+	}
+	if len(result.PVCBindings) != 4 {
+		t.Fatalf("Simulate() got %d pvc binding(s), want 4", len(result.PVCBindings))
+	}
+	byName := make(map[string]PVCBinding)
+	for _, binding := range result.PVCBindings {
+		byName[binding.Name] = binding
+	}
+	if binding := byName["claim-static"]; binding.Volume != "pv-small" {
+		t.Errorf("claim-static bound to %q, want the smallest fitting volume pv-small", binding.Volume)
+	}
+	if binding := byName["claim-dynamic"]; !binding.Provisioned || binding.Volume == "" {
+		t.Errorf("claim-dynamic got %+v, want a provisioned volume", binding)
+	}
+	if binding := byName["claim-stuck"]; binding.Volume != "" || !strings.Contains(binding.Reason, "does not provision dynamically") {
+		t.Errorf("claim-stuck got %+v, want unbound with a no-dynamic-provisioning reason", binding)
+	}
+	if binding := byName["claim-missing"]; binding.Volume != "" || !strings.Contains(binding.Reason, "does not exist") {
+		t.Errorf("claim-missing got %+v, want unbound because the class is missing", binding)
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	resultA := &SimulateResult{
+		NodeStatus: []NodeStatus{
+			{
+				Node: test.MakeFakeNode("node-1", "4", "8Gi"),
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-moved", "default", "1", "1Gi", test.WithPodNodeName("node-1")),
+					test.MakeFakePod("pod-stable", "default", "1", "1Gi", test.WithPodNodeName("node-1")),
+				},
+			},
+			{
+				Node: test.MakeFakeNode("node-2", "4", "8Gi"),
+			},
+		},
+	}
+	resultB := &SimulateResult{
+		NodeStatus: []NodeStatus{
+			{
+				Node: test.MakeFakeNode("node-1", "4", "8Gi"),
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-stable", "default", "1", "1Gi", test.WithPodNodeName("node-1")),
+				},
+			},
+			{
+				Node: test.MakeFakeNode("node-2", "4", "8Gi"),
+				Pods: []*corev1.Pod{
+					test.MakeFakePod("pod-moved", "default", "1", "1Gi", test.WithPodNodeName("node-2")),
+				},
+			},
+		},
+		UnscheduledPods: []UnscheduledPod{
+			{Pod: test.MakeFakePod("pod-stuck", "default", "1", "1Gi"), Reason: "no fit"},
+		},
+	}
+
+	diff := DiffResults(resultA, resultB)
+	if len(diff.PodMoves) != 1 {
+		t.Fatalf("DiffResults() found %d move(s), want 1: %v", len(diff.PodMoves), diff.PodMoves)
+	}
+	if move := diff.PodMoves[0]; move.Pod != "default/pod-moved" || move.From != "node-1" || move.To != "node-2" {
+		t.Errorf("DiffResults() move = %+v, want default/pod-moved from node-1 to node-2", move)
+	}
+	if len(diff.UtilizationChanges) != 2 {
+		t.Fatalf("DiffResults() found %d utilization change(s), want both nodes: %v", len(diff.UtilizationChanges), diff.UtilizationChanges)
+	}
+	if change := diff.UtilizationChanges[0]; change.Node != "node-1" || change.Before <= change.After {
+		t.Errorf("DiffResults() node-1 change = %+v, want utilization to drop", change)
+	}
+	if change := diff.UtilizationChanges[1]; change.Node != "node-2" || change.Before >= change.After {
+		t.Errorf("DiffResults() node-2 change = %+v, want utilization to rise", change)
+	}
+	if len(diff.NewlyUnscheduled) != 1 || diff.NewlyUnscheduled[0] != "default/pod-stuck" {
+		t.Errorf("DiffResults() newly unscheduled = %v, want default/pod-stuck", diff.NewlyUnscheduled)
+	}
+	if diff.Empty() {
+		t.Errorf("DiffResults() diff should not be empty")
+	}
+
+	if diff := DiffResults(resultA, resultA); !diff.Empty() {
+		t.Errorf("DiffResults() of a result against itself = %+v, want empty", diff)
+	}
+}
+
+func TestEffectiveSchedulerConfig(t *testing.T) {
+	content, err := EffectiveSchedulerConfig("", "")
+	if err != nil {
+		t.Fatalf("EffectiveSchedulerConfig() error = %v", err)
+	}
+	rendered := string(content)
+	if !strings.Contains(rendered, "kind: KubeSchedulerConfiguration") {
+		t.Errorf("EffectiveSchedulerConfig() = %q, want a typed KubeSchedulerConfiguration", rendered)
+	}
+	// the merged config must show the simulator's own plugins, not the file alone
+	if !strings.Contains(rendered, simontype.SimonPluginName) {
+		t.Errorf("EffectiveSchedulerConfig() does not list the simon bind plugin:\n%s", rendered)
+	}
+
+	packed, err := EffectiveSchedulerConfig("", ScoringStrategyMostAllocated)
+	if err != nil {
+		t.Fatalf("EffectiveSchedulerConfig() error = %v", err)
+	}
+	if !strings.Contains(string(packed), "NodeResourcesMostAllocated") {
+		t.Errorf("EffectiveSchedulerConfig() does not reflect the scoring strategy:\n%s", string(packed))
+	}
+
+	if _, err := EffectiveSchedulerConfig("", "tightest"); err == nil {
+		t.Errorf("EffectiveSchedulerConfig() expected an error for an unknown scoring strategy")
+	}
+}
+
+func TestAnalyzeAntiAffinity(t *testing.T) {
+	webSelector := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	requiredAntiAffinity := &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+				{LabelSelector: webSelector, TopologyKey: "kubernetes.io/hostname"},
+			},
+		},
+	}
+	preferredAntiAffinity := &corev1.Affinity{
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{Weight: 100, PodAffinityTerm: corev1.PodAffinityTerm{LabelSelector: webSelector, TopologyKey: "topology.kubernetes.io/zone"}},
+			},
+		},
+	}
+
+	// both nodes share one zone; web-1 and web-2 share node-1, so web-1's required
+	// hostname term fails hard while web-3's preferred zone term is traded away
+	nodeStatuses := []NodeStatus{
+		{
+			Node: test.MakeFakeNode("node-1", "4", "8Gi",
+				test.WithNodeLabels(map[string]string{"kubernetes.io/hostname": "node-1", "topology.kubernetes.io/zone": "zone-a"})),
+			Pods: []*corev1.Pod{
+				test.MakeFakePod("web-1", "default", "1", "1Gi", test.WithPodNodeName("node-1"),
+					test.WithPodLabels(map[string]string{"app": "web"}), test.WithPodAffinity(requiredAntiAffinity)),
+				test.MakeFakePod("web-2", "default", "1", "1Gi", test.WithPodNodeName("node-1"),
+					test.WithPodLabels(map[string]string{"app": "web"})),
+			},
+		},
+		{
+			Node: test.MakeFakeNode("node-2", "4", "8Gi",
+				test.WithNodeLabels(map[string]string{"kubernetes.io/hostname": "node-2", "topology.kubernetes.io/zone": "zone-a"})),
+			Pods: []*corev1.Pod{
+				test.MakeFakePod("web-3", "default", "1", "1Gi", test.WithPodNodeName("node-2"),
+					test.WithPodLabels(map[string]string{"app": "web"}), test.WithPodAffinity(preferredAntiAffinity)),
+			},
+		},
+	}
+
+	statuses := AnalyzeAntiAffinity(nodeStatuses)
+	if len(statuses) != 2 {
+		t.Fatalf("AnalyzeAntiAffinity() returned %d status(es), want one per pod with anti-affinity: %v", len(statuses), statuses)
+	}
+	byPod := make(map[string]AntiAffinityStatus, len(statuses))
+	for _, status := range statuses {
+		byPod[status.Pod] = status
+	}
+	required := byPod["default/web-1"]
+	if required.Satisfied() || len(required.RequiredViolations) != 1 || len(required.PreferredViolations) != 0 {
+		t.Errorf("web-1 status = %+v, want exactly one required failure", required)
+	}
+	preferred := byPod["default/web-3"]
+	if preferred.Satisfied() || len(preferred.RequiredViolations) != 0 || len(preferred.PreferredViolations) != 1 {
+		t.Errorf("web-3 status = %+v, want exactly one preferred violation", preferred)
+	}
+	if preferred.CoLocatedPeers != 2 {
+		t.Errorf("web-3 co-located peers = %d, want both zone peers counted", preferred.CoLocatedPeers)
+	}
+
+	// spread across hostnames the required term is satisfied
+	nodeStatuses[0].Pods = nodeStatuses[0].Pods[:1]
+	statuses = AnalyzeAntiAffinity(nodeStatuses)
+	if status := statuses[0]; status.Pod != "default/web-1" || !status.Satisfied() {
+		t.Errorf("AnalyzeAntiAffinity() after spreading = %+v, want web-1 satisfied", statuses)
+	}
+}
+
+func TestScalePodRequests(t *testing.T) {
+	pod := test.MakeFakePod("canary", "default", "1", "1Gi",
+		test.WithPodLimits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1500m")}))
+
+	// scaling up clamps the cpu request at its limit, the unlimited memory doubles
+	scalePodRequests(pod, 2)
+	if pod.Spec.Containers[0].Resources.Requests.Cpu().MilliValue() != 1500 {
+		t.Errorf("scaled cpu request = %s, want it clamped to the 1500m limit", pod.Spec.Containers[0].Resources.Requests.Cpu())
+	}
+	if pod.Spec.Containers[0].Resources.Requests.Memory().String() != "2Gi" {
+		t.Errorf("scaled memory request = %s, want 2Gi", pod.Spec.Containers[0].Resources.Requests.Memory())
+	}
+
+	// scaling down never reaches the limit and stays untouched by the clamp
+	canary := test.MakeFakePod("canary-small", "default", "1", "1Gi",
+		test.WithPodLimits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1500m")}))
+	scalePodRequests(canary, 0.5)
+	if canary.Spec.Containers[0].Resources.Requests.Cpu().MilliValue() != 500 {
+		t.Errorf("scaled cpu request = %s, want 500m", canary.Spec.Containers[0].Resources.Requests.Cpu())
+	}
+}
@@ -0,0 +1,104 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// debugRecord is the raw scheduler framework outcome for one pod: the prefilter status,
+// the filter verdict of every candidate node and the node the pod ended up on
+type debugRecord struct {
+	Pod             string            `json:"pod"`
+	ChosenNode      string            `json:"chosenNode,omitempty"`
+	PreFilterStatus string            `json:"preFilterStatus,omitempty"`
+	FilterVerdicts  map[string]string `json:"filterVerdicts,omitempty"`
+}
+
+// recordPodDebug re-runs the prefilter and filter plugins of the pod's scheduling
+// profile against every node and records the per-node verdicts. Like the score dump the
+// plugins run right after the pod was handled, so the verdicts reflect the cluster state
+// the scheduler saw.
+func (sim *Simulator) recordPodDebug(pod *corev1.Pod) {
+	fwk, ok := sim.scheduler.Profiles[pod.Spec.SchedulerName]
+	if !ok {
+		return
+	}
+	record := debugRecord{
+		Pod:        fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+		ChosenNode: pod.Spec.NodeName,
+	}
+	if record.ChosenNode == "" {
+		// the bind plugin updates the pod in the fake cluster, not the local object;
+		// an unscheduled pod was already deleted again and keeps no chosen node
+		if scheduledPod, err := sim.fakeclient.CoreV1().Pods(pod.Namespace).Get(context.Background(), pod.Name, metav1.GetOptions{}); err == nil {
+			record.ChosenNode = scheduledPod.Spec.NodeName
+		}
+	}
+	defer func() {
+		sim.debugRecords = append(sim.debugRecords, record)
+	}()
+
+	nodeList, err := sim.fakeclient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil || len(nodeList.Items) == 0 {
+		return
+	}
+	podList, err := sim.fakeclient.CoreV1().Pods(corev1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+	podsByNode := make(map[string][]*corev1.Pod)
+	for i := range podList.Items {
+		nodePod := &podList.Items[i]
+		podsByNode[nodePod.Spec.NodeName] = append(podsByNode[nodePod.Spec.NodeName], nodePod)
+	}
+
+	state := framework.NewCycleState()
+	if status := fwk.RunPreFilterPlugins(sim.ctx, state, pod); !status.IsSuccess() {
+		record.PreFilterStatus = status.Message()
+		return
+	}
+
+	record.FilterVerdicts = make(map[string]string, len(nodeList.Items))
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		nodeInfo := framework.NewNodeInfo(podsByNode[node.Name]...)
+		nodeInfo.SetNode(node)
+		if status := fwk.RunFilterPlugins(sim.ctx, state, pod, nodeInfo).Merge(); status.IsSuccess() {
+			record.FilterVerdicts[node.Name] = "accepted"
+		} else {
+			record.FilterVerdicts[node.Name] = status.Message()
+		}
+	}
+}
+
+// writeDebugDump writes the recorded framework outcomes as JSON to the configured path;
+// it is a no-op when no debug dump was requested
+func (sim *Simulator) writeDebugDump() error {
+	if sim.debugDumpPath == "" {
+		return nil
+	}
+	content, err := json.MarshalIndent(sim.debugRecords, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal debug dump: %v", err)
+	}
+	if err := os.WriteFile(sim.debugDumpPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write debug dump file %s: %v", sim.debugDumpPath, err)
+	}
+	return nil
+}
+
+// WithDebugDump records the raw scheduler framework outcome (prefilter status, per-node
+// filter verdicts, chosen node) for every pod and writes it as JSON to the given path.
+// The dump is aimed at diagnosing scheduler-behavior discrepancies and can get large, so
+// it is off unless a path is set.
+func WithDebugDump(debugDumpPath string) Option {
+	return func(o *simulatorOptions) {
+		o.debugDumpPath = debugDumpPath
+	}
+}
@@ -0,0 +1,221 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+
+	"github.com/alibaba/open-simulator/pkg/utils"
+)
+
+// DescheduleOptions configures the LowNodeUtilization-style rebalancing of Deschedule
+type DescheduleOptions struct {
+	// HighThreshold is the utilization percentage above which a node sheds pods, 0
+	// falls back to 80
+	HighThreshold int
+	// LowThreshold is the utilization percentage below which a node receives pods, 0
+	// falls back to 20
+	LowThreshold int
+}
+
+// PodMove records one pod relocated by the descheduling pass
+type PodMove struct {
+	// Pod is the namespace/name of the moved pod
+	Pod  string `json:"pod"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DescheduleResult captures the rebalanced placement and how it was reached
+type DescheduleResult struct {
+	// Result is the placement after the moves; UnscheduledPods is carried over unchanged
+	Result *SimulateResult
+	// Moves lists the relocations in the order they were made
+	Moves []PodMove
+}
+
+// Deschedule rebalances the placement of a finished simulation the way the descheduler's
+// LowNodeUtilization strategy would: pods move from nodes above the high threshold to
+// nodes below the low threshold until the overloaded nodes are relieved or no pod fits
+// anywhere. Only resource requests, node affinity and taints are re-checked for a move;
+// daemonset pods stay where they are. The input result is not modified.
+func Deschedule(result *SimulateResult, opts DescheduleOptions) (*DescheduleResult, error) {
+	high := opts.HighThreshold
+	if high == 0 {
+		high = 80
+	}
+	low := opts.LowThreshold
+	if low == 0 {
+		low = 20
+	}
+	if high < 0 || high > 100 || low < 0 || low > 100 {
+		return nil, fmt.Errorf("deschedule thresholds must be percentages between 0 and 100, got high %d and low %d", high, low)
+	}
+	if low >= high {
+		return nil, fmt.Errorf("deschedule low threshold %d must be below the high threshold %d", low, high)
+	}
+
+	nodeStatuses := make([]NodeStatus, 0, len(result.NodeStatus))
+	for _, status := range result.NodeStatus {
+		pods := make([]*corev1.Pod, 0, len(status.Pods))
+		for _, pod := range status.Pods {
+			pods = append(pods, pod.DeepCopy())
+		}
+		nodeStatuses = append(nodeStatuses, NodeStatus{Node: status.Node, Pods: pods})
+	}
+
+	var moves []PodMove
+	for {
+		sources := make([]int, 0, len(nodeStatuses))
+		for i := range nodeStatuses {
+			if NodeUtilizationPercent(nodeStatuses[i]) > high {
+				sources = append(sources, i)
+			}
+		}
+		// relieve the fullest node first
+		sort.Slice(sources, func(i, j int) bool {
+			return NodeUtilizationPercent(nodeStatuses[sources[i]]) > NodeUtilizationPercent(nodeStatuses[sources[j]])
+		})
+
+		moved := false
+		for _, source := range sources {
+			move := bestMove(nodeStatuses, source, high, low)
+			if move == nil {
+				continue
+			}
+			pod := nodeStatuses[source].Pods[move.podIndex]
+			pod.Spec.NodeName = nodeStatuses[move.target].Node.Name
+			nodeStatuses[source].Pods = append(nodeStatuses[source].Pods[:move.podIndex], nodeStatuses[source].Pods[move.podIndex+1:]...)
+			nodeStatuses[move.target].Pods = append(nodeStatuses[move.target].Pods, pod)
+			moves = append(moves, PodMove{
+				Pod:  fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+				From: nodeStatuses[source].Node.Name,
+				To:   nodeStatuses[move.target].Node.Name,
+			})
+			moved = true
+			break
+		}
+		if !moved {
+			break
+		}
+	}
+
+	return &DescheduleResult{
+		Result: &SimulateResult{
+			UnscheduledPods: result.UnscheduledPods,
+			NodeStatus:      nodeStatuses,
+			PVCBindings:     result.PVCBindings,
+		},
+		Moves: moves,
+	}, nil
+}
+
+type candidateMove struct {
+	podIndex int
+	target   int
+}
+
+// bestMove picks the largest movable pod of the source node and the emptiest
+// underutilized node it fits on without pushing the target over the high threshold,
+// or nil when no such pair exists
+func bestMove(nodeStatuses []NodeStatus, source, high, low int) *candidateMove {
+	podIndices := make([]int, 0, len(nodeStatuses[source].Pods))
+	for i, pod := range nodeStatuses[source].Pods {
+		if podIsDaemonSet(pod) {
+			continue
+		}
+		podIndices = append(podIndices, i)
+	}
+	// the largest pod relieves the source with the fewest moves
+	sort.Slice(podIndices, func(i, j int) bool {
+		return podRequestMilli(nodeStatuses[source].Pods[podIndices[i]]) > podRequestMilli(nodeStatuses[source].Pods[podIndices[j]])
+	})
+
+	targets := make([]int, 0, len(nodeStatuses))
+	for i := range nodeStatuses {
+		if i != source && NodeUtilizationPercent(nodeStatuses[i]) < low {
+			targets = append(targets, i)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return NodeUtilizationPercent(nodeStatuses[targets[i]]) < NodeUtilizationPercent(nodeStatuses[targets[j]])
+	})
+
+	for _, podIndex := range podIndices {
+		pod := nodeStatuses[source].Pods[podIndex]
+		// the node-name predicate would pin the pod to its current node
+		unbound := pod.DeepCopy()
+		unbound.Spec.NodeName = ""
+		for _, target := range targets {
+			if !utils.NodeShouldRunPod(nodeStatuses[target].Node, unbound) {
+				continue
+			}
+			if utilizationWithPod(nodeStatuses[target], pod) > high {
+				continue
+			}
+			return &candidateMove{podIndex: podIndex, target: target}
+		}
+	}
+	return nil
+}
+
+// NodeUtilizationPercent returns the higher of the node's cpu and memory request
+// occupancy, the metric the descheduling pass balances
+func NodeUtilizationPercent(status NodeStatus) int {
+	return utilizationWithPod(status, nil)
+}
+
+// utilizationWithPod computes the node's utilization as if the extra pod (may be nil)
+// were already placed on it
+func utilizationWithPod(status NodeStatus, extra *corev1.Pod) int {
+	var usedCPU, usedMemory int64
+	for _, pod := range status.Pods {
+		req, _ := resourcehelper.PodRequestsAndLimits(pod)
+		cpu := req[corev1.ResourceCPU]
+		memory := req[corev1.ResourceMemory]
+		usedCPU += cpu.MilliValue()
+		usedMemory += memory.Value()
+	}
+	if extra != nil {
+		req, _ := resourcehelper.PodRequestsAndLimits(extra)
+		cpu := req[corev1.ResourceCPU]
+		memory := req[corev1.ResourceMemory]
+		usedCPU += cpu.MilliValue()
+		usedMemory += memory.Value()
+	}
+
+	allocatableCPU := status.Node.Status.Allocatable[corev1.ResourceCPU]
+	allocatableMemory := status.Node.Status.Allocatable[corev1.ResourceMemory]
+	utilization := 0
+	if allocatableCPU.MilliValue() > 0 {
+		if cpuPercent := int(usedCPU * 100 / allocatableCPU.MilliValue()); cpuPercent > utilization {
+			utilization = cpuPercent
+		}
+	}
+	if allocatableMemory.Value() > 0 {
+		if memoryPercent := int(usedMemory * 100 / allocatableMemory.Value()); memoryPercent > utilization {
+			utilization = memoryPercent
+		}
+	}
+	return utilization
+}
+
+// podRequestMilli sizes a pod by its cpu request for the largest-first move order
+func podRequestMilli(pod *corev1.Pod) int64 {
+	req, _ := resourcehelper.PodRequestsAndLimits(pod)
+	cpu := req[corev1.ResourceCPU]
+	return cpu.MilliValue()
+}
+
+// podIsDaemonSet reports whether the pod is owned by a daemonset and therefore pinned to
+// its node
+func podIsDaemonSet(pod *corev1.Pod) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
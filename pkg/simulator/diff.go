@@ -0,0 +1,97 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+)
+
+// NodeUtilizationChange records a node whose request utilization differs between two
+// simulation results; a node present in only one result shows up with 0 on the other side
+type NodeUtilizationChange struct {
+	Node   string `json:"node"`
+	Before int    `json:"before"`
+	After  int    `json:"after"`
+}
+
+// ResultDiff is what changed in placement between two simulation results of the same
+// cluster, typically before and after a config tweak
+type ResultDiff struct {
+	// PodMoves lists pods that are placed in both results but on different nodes
+	PodMoves []PodMove `json:"podMoves,omitempty"`
+	// UtilizationChanges lists nodes whose utilization percentage differs
+	UtilizationChanges []NodeUtilizationChange `json:"utilizationChanges,omitempty"`
+	// NewlyUnscheduled lists namespace/name of pods unscheduled in the second result
+	// but not in the first
+	NewlyUnscheduled []string `json:"newlyUnscheduled,omitempty"`
+}
+
+// Empty reports whether the two results place every pod the same way
+func (diff *ResultDiff) Empty() bool {
+	return len(diff.PodMoves) == 0 && len(diff.UtilizationChanges) == 0 && len(diff.NewlyUnscheduled) == 0
+}
+
+// DiffResults compares two simulation results and returns the pods that moved nodes,
+// the nodes whose utilization changed and the pods that became unscheduled, making
+// placement regressions between config versions visible.
+func DiffResults(a, b *SimulateResult) *ResultDiff {
+	diff := &ResultDiff{}
+
+	placementOf := func(result *SimulateResult) map[string]string {
+		placement := make(map[string]string)
+		for _, status := range result.NodeStatus {
+			for _, pod := range status.Pods {
+				placement[fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)] = status.Node.Name
+			}
+		}
+		return placement
+	}
+	placementA := placementOf(a)
+	placementB := placementOf(b)
+	for pod, nodeA := range placementA {
+		if nodeB, exist := placementB[pod]; exist && nodeA != nodeB {
+			diff.PodMoves = append(diff.PodMoves, PodMove{Pod: pod, From: nodeA, To: nodeB})
+		}
+	}
+	sort.Slice(diff.PodMoves, func(i, j int) bool { return diff.PodMoves[i].Pod < diff.PodMoves[j].Pod })
+
+	utilizationOf := func(result *SimulateResult) map[string]int {
+		utilization := make(map[string]int, len(result.NodeStatus))
+		for _, status := range result.NodeStatus {
+			utilization[status.Node.Name] = NodeUtilizationPercent(status)
+		}
+		return utilization
+	}
+	utilizationA := utilizationOf(a)
+	utilizationB := utilizationOf(b)
+	nodes := make(map[string]struct{}, len(utilizationA)+len(utilizationB))
+	for node := range utilizationA {
+		nodes[node] = struct{}{}
+	}
+	for node := range utilizationB {
+		nodes[node] = struct{}{}
+	}
+	for node := range nodes {
+		if utilizationA[node] != utilizationB[node] {
+			diff.UtilizationChanges = append(diff.UtilizationChanges, NodeUtilizationChange{
+				Node:   node,
+				Before: utilizationA[node],
+				After:  utilizationB[node],
+			})
+		}
+	}
+	sort.Slice(diff.UtilizationChanges, func(i, j int) bool { return diff.UtilizationChanges[i].Node < diff.UtilizationChanges[j].Node })
+
+	unscheduledA := make(map[string]struct{}, len(a.UnscheduledPods))
+	for _, unscheduled := range a.UnscheduledPods {
+		unscheduledA[fmt.Sprintf("%s/%s", unscheduled.Pod.Namespace, unscheduled.Pod.Name)] = struct{}{}
+	}
+	for _, unscheduled := range b.UnscheduledPods {
+		pod := fmt.Sprintf("%s/%s", unscheduled.Pod.Namespace, unscheduled.Pod.Name)
+		if _, exist := unscheduledA[pod]; !exist {
+			diff.NewlyUnscheduled = append(diff.NewlyUnscheduled, pod)
+		}
+	}
+	sort.Strings(diff.NewlyUnscheduled)
+
+	return diff
+}
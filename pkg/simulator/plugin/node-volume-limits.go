@@ -0,0 +1,69 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+	"github.com/alibaba/open-simulator/pkg/utils"
+)
+
+// NodeVolumeLimitPlugin filters out nodes whose volume attach limit would be exceeded,
+// mirroring the CSINode attachable volume limits of a real cluster: a node with free
+// storage capacity can still refuse a pod when all its attach slots are taken
+type NodeVolumeLimitPlugin struct{}
+
+var _ = framework.FilterPlugin(&NodeVolumeLimitPlugin{})
+
+func NewNodeVolumeLimitPlugin(configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
+	return &NodeVolumeLimitPlugin{}, nil
+}
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (plugin *NodeVolumeLimitPlugin) Name() string {
+	return simontype.NodeVolumeLimitPluginName
+}
+
+// Filter invoked at the filter extension point.
+func (plugin *NodeVolumeLimitPlugin) Filter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	requested := podVolumeCount(pod)
+	if requested == 0 {
+		return framework.NewStatus(framework.Success)
+	}
+
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+	limitStr, exist := node.Annotations[simontype.AnnoNodeVolumeLimit]
+	if !exist {
+		// nodes without the annotation accept any number of volumes
+		return framework.NewStatus(framework.Success)
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		return framework.NewStatus(framework.Error,
+			fmt.Sprintf("invalid volume limit %s on node %s: %v", limitStr, node.Name, err))
+	}
+
+	attached := 0
+	for _, podInfo := range nodeInfo.Pods {
+		attached += podVolumeCount(podInfo.Pod)
+	}
+	if attached+requested > limit {
+		return framework.NewStatus(framework.Unschedulable,
+			fmt.Sprintf("node volume attach limit exceeded: %d of %d volume(s) attached on node %s", attached, limit, node.Name))
+	}
+	return framework.NewStatus(framework.Success)
+}
+
+// podVolumeCount counts the local volumes the pod would attach to its node
+func podVolumeCount(pod *corev1.Pod) int {
+	lvmPVCs, devicePVCs := utils.GetPodLocalPVCs(pod)
+	return len(lvmPVCs) + len(devicePVCs)
+}
@@ -8,6 +8,7 @@ import (
 	localtype "github.com/alibaba/open-local/pkg"
 	localalgorithm "github.com/alibaba/open-local/pkg/scheduler/algorithm"
 	localalgo "github.com/alibaba/open-local/pkg/scheduler/algorithm/algo"
+	localcache "github.com/alibaba/open-local/pkg/scheduler/algorithm/cache"
 	localpriorities "github.com/alibaba/open-local/pkg/scheduler/algorithm/priorities"
 	"github.com/pquerna/ffjson/ffjson"
 	log "github.com/sirupsen/logrus"
@@ -23,11 +24,32 @@ import (
 	"github.com/alibaba/open-simulator/pkg/utils"
 )
 
+// VG selection policies for open-local LVM volumes. The vendored allocator always
+// bin-packs; a policy re-targets each volume among the VGs that still fit it, so
+// storage planners can model how different strategies fill up the node.
+const (
+	VGSelectionFirstFit       = "first-fit"
+	VGSelectionLeastAllocated = "least-allocated"
+	VGSelectionMostAllocated  = "most-allocated"
+)
+
+// ValidateVGSelectionPolicy rejects policy names the plugin does not implement
+func ValidateVGSelectionPolicy(policy string) error {
+	switch policy {
+	case "", VGSelectionFirstFit, VGSelectionLeastAllocated, VGSelectionMostAllocated:
+		return nil
+	}
+	return fmt.Errorf("unknown vg selection policy %s, supported: %s, %s, %s ", policy, VGSelectionFirstFit, VGSelectionLeastAllocated, VGSelectionMostAllocated)
+}
+
 // LocalPlugin is a plugin for scheduling framework
 type LocalPlugin struct {
 	fakeclient externalclientset.Interface
 	// open-local need storageInformer to get sc
 	storageInformer storagev1informers.Interface
+	// vgSelectionPolicy re-targets LVM volumes among the VGs of the chosen node,
+	// empty keeps the allocator's choice
+	vgSelectionPolicy string
 }
 
 var _ = framework.FilterPlugin(&LocalPlugin{})
@@ -35,10 +57,11 @@ var _ = framework.ScorePlugin(&LocalPlugin{})
 var _ = framework.BindPlugin(&LocalPlugin{})
 
 // NewLocalPlugin
-func NewLocalPlugin(fakeclient externalclientset.Interface, storageInformers storagev1informers.Interface, configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
+func NewLocalPlugin(fakeclient externalclientset.Interface, storageInformers storagev1informers.Interface, vgSelectionPolicy string, configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
 	return &LocalPlugin{
-		storageInformer: storageInformers,
-		fakeclient:      fakeclient,
+		storageInformer:   storageInformers,
+		fakeclient:        fakeclient,
+		vgSelectionPolicy: vgSelectionPolicy,
 	}, nil
 }
 
@@ -221,6 +244,7 @@ func (plugin *LocalPlugin) Bind(ctx context.Context, state *framework.CycleState
 	if err != nil {
 		return framework.NewStatus(framework.Error, err.Error())
 	}
+	retargetLVMUnits(nodeStorage, units, plugin.vgSelectionPolicy)
 	for i := range units {
 		if units[i].VolumeType == localtype.VolumeTypeLVM {
 			for j := range nodeStorage.VGs {
@@ -249,6 +273,75 @@ func (plugin *LocalPlugin) Bind(ctx context.Context, state *framework.CycleState
 		return framework.NewStatus(framework.Error, err.Error())
 	}
 
+	// record which VG each volume landed in; the simon bind plugin folds the
+	// assignments into its pod update so reports can show the placement
+	vgAssignments := make(map[string]string)
+	for i := range units {
+		if units[i].VolumeType == localtype.VolumeTypeLVM {
+			vgAssignments[units[i].PVCName] = units[i].VgName
+		}
+	}
+	if len(vgAssignments) != 0 {
+		state.Write(vgAssignmentsStateKey, &vgAssignmentsStateData{assignments: vgAssignments})
+	}
+
 	// should always skip
 	return framework.NewStatus(framework.Skip)
 }
+
+// vgAssignmentsStateKey carries the VG each LVM volume landed in through the scheduling
+// cycle, from the local bind plugin to the simon bind plugin that stamps it onto the pod
+const vgAssignmentsStateKey framework.StateKey = "simon/vg-assignments"
+
+type vgAssignmentsStateData struct {
+	assignments map[string]string
+}
+
+func (d *vgAssignmentsStateData) Clone() framework.StateData {
+	return d
+}
+
+// retargetLVMUnits moves each LVM unit onto the VG the policy prefers among the VGs
+// that still have enough free capacity, tracking the free space as earlier units land.
+// A unit no VG can take keeps the allocator's choice.
+func retargetLVMUnits(nodeStorage *utils.NodeStorage, units []localcache.AllocatedUnit, policy string) {
+	if policy == "" {
+		return
+	}
+	free := make(map[string]int64, len(nodeStorage.VGs))
+	for _, vg := range nodeStorage.VGs {
+		free[vg.Name] = vg.Capacity - vg.Requested
+	}
+	for i := range units {
+		if units[i].VolumeType != localtype.VolumeTypeLVM {
+			continue
+		}
+		chosen := ""
+		for _, vg := range nodeStorage.VGs {
+			if free[vg.Name] < units[i].Requested {
+				continue
+			}
+			if chosen == "" {
+				chosen = vg.Name
+				if policy == VGSelectionFirstFit {
+					break
+				}
+				continue
+			}
+			switch policy {
+			case VGSelectionLeastAllocated:
+				if free[vg.Name] > free[chosen] {
+					chosen = vg.Name
+				}
+			case VGSelectionMostAllocated:
+				if free[vg.Name] < free[chosen] {
+					chosen = vg.Name
+				}
+			}
+		}
+		if chosen != "" {
+			units[i].VgName = chosen
+		}
+		free[units[i].VgName] -= units[i].Requested
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/pquerna/ffjson/ffjson"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -113,6 +114,15 @@ func (plugin *SimonPlugin) BindPodToNode(ctx context.Context, state *framework.C
 	updatedPod.Spec.NodeName = nodeName
 	updatedPod.Status.Phase = corev1.PodRunning
 
+	// stamp the VG placement the local bind plugin left in the cycle state, if any
+	if stateData, err := state.Read(vgAssignmentsStateKey); err == nil {
+		if vgAssignments, ok := stateData.(*vgAssignmentsStateData); ok {
+			if data, err := ffjson.Marshal(vgAssignments.assignments); err == nil {
+				metav1.SetMetaDataAnnotation(&updatedPod.ObjectMeta, simontype.AnnoPodVolumeVGs, string(data))
+			}
+		}
+	}
+
 	// Step 2: update pod
 	// here assuming the pod is already in the resource storage
 	// so the update is needed to emit update event in case a handler is registered
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+	framework "k8s.io/kubernetes/pkg/scheduler/framework"
+
+	simontype "github.com/alibaba/open-simulator/pkg/type"
+)
+
+// UtilizationCapPlugin filters out nodes that a pod would push above a configured
+// utilization percentage, so packing leaves the requested headroom and scale-out
+// happens earlier than it would at 100% utilization
+type UtilizationCapPlugin struct {
+	// caps maps a resource name to the maximum node utilization in percent
+	caps map[corev1.ResourceName]int64
+}
+
+var _ = framework.FilterPlugin(&UtilizationCapPlugin{})
+
+func NewUtilizationCapPlugin(caps map[corev1.ResourceName]int64, configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
+	return &UtilizationCapPlugin{
+		caps: caps,
+	}, nil
+}
+
+// Name returns name of the plugin. It is used in logs, etc.
+func (plugin *UtilizationCapPlugin) Name() string {
+	return simontype.UtilizationCapPluginName
+}
+
+// Filter invoked at the filter extension point.
+func (plugin *UtilizationCapPlugin) Filter(ctx context.Context, state *framework.CycleState, pod *corev1.Pod, nodeInfo *framework.NodeInfo) *framework.Status {
+	if len(plugin.caps) == 0 {
+		return framework.NewStatus(framework.Success)
+	}
+	node := nodeInfo.Node()
+	if node == nil {
+		return framework.NewStatus(framework.Error, "node not found")
+	}
+
+	podReq, _ := resourcehelper.PodRequestsAndLimits(pod)
+	used := make(corev1.ResourceList)
+	for _, podInfo := range nodeInfo.Pods {
+		req, _ := resourcehelper.PodRequestsAndLimits(podInfo.Pod)
+		for resourceName, quantity := range req {
+			usedRes := used[resourceName]
+			usedRes.Add(quantity)
+			used[resourceName] = usedRes
+		}
+	}
+
+	for resourceName, cap := range plugin.caps {
+		allocatable, exist := node.Status.Allocatable[resourceName]
+		if !exist || allocatable.IsZero() {
+			continue
+		}
+		demand := used[resourceName]
+		demand.Add(podReq[resourceName])
+		// demand / allocatable > cap / 100, compared without division
+		if demand.MilliValue()*100 > allocatable.MilliValue()*cap {
+			return framework.NewStatus(framework.Unschedulable,
+				fmt.Sprintf("node %s would exceed the %d%% utilization cap on %s", node.Name, cap, resourceName))
+		}
+	}
+	return framework.NewStatus(framework.Success)
+}
@@ -0,0 +1,60 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/alibaba/open-simulator/pkg/algo"
+)
+
+const (
+	// PodOrderGreedy feeds pods by their dominant share of cluster capacity, the
+	// ordering the UseGreed flag historically selected
+	PodOrderGreedy = "greedy"
+	// PodOrderLargestFirst feeds pods by descending cpu+memory request magnitude
+	PodOrderLargestFirst = "largest-first"
+	// PodOrderAppDeclared keeps the order the app manifests declare the pods in
+	PodOrderAppDeclared = "app-declared"
+	// PodOrderPriorityClass feeds pods by descending resolved pod priority, matching
+	// admission order on priority-heavy clusters
+	PodOrderPriorityClass = "priority-class"
+)
+
+// ValidatePodOrder rejects unknown pod order strategies before a simulation starts
+func ValidatePodOrder(order string) error {
+	switch order {
+	case "", PodOrderGreedy, PodOrderLargestFirst, PodOrderAppDeclared, PodOrderPriorityClass:
+		return nil
+	}
+	return fmt.Errorf("invalid pod order %s, supported: %s, %s, %s, %s ", order, PodOrderGreedy, PodOrderLargestFirst, PodOrderAppDeclared, PodOrderPriorityClass)
+}
+
+// applyPodOrder reorders the pods of one app according to the configured strategy. It
+// runs after the affinity and toleration pre-sorts so the strategy dominates them;
+// app-declared instead skips the pre-sorts and leaves the manifest order untouched.
+func (sim *Simulator) applyPodOrder(pods []*corev1.Pod) error {
+	switch sim.podOrder {
+	case PodOrderGreedy:
+		nodeList, err := sim.fakeclient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		sort.Sort(algo.NewGreedQueue(nodeList.Items, pods))
+	case PodOrderLargestFirst:
+		sort.Sort(algo.NewLargestRequestQueue(pods))
+	case PodOrderPriorityClass:
+		sort.Sort(algo.NewPriorityClassQueue(pods))
+	}
+	return nil
+}
+
+// WithPodOrder sets the order pods are fed to the scheduler within each app
+func WithPodOrder(order string) Option {
+	return func(o *simulatorOptions) {
+		o.podOrder = order
+	}
+}
@@ -0,0 +1,198 @@
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// noProvisioner is the provisioner value marking a storage class as static-only
+const noProvisioner = "kubernetes.io/no-provisioner"
+
+// PVCBinding records how one persistent volume claim was satisfied, or why it was not
+type PVCBinding struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	StorageClass string `json:"storageClass,omitempty"`
+	Requested    string `json:"requested,omitempty"`
+	// Volume is the name of the bound persistent volume, empty when the claim stayed
+	// unbound
+	Volume string `json:"volume,omitempty"`
+	// Provisioned marks volumes synthesized from the claim's storage class instead of
+	// matched against a pre-existing persistent volume
+	Provisioned bool   `json:"provisioned,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// bindPersistentVolumeClaims runs a static binding pass over every claim in the
+// simulated cluster: a claim binds to the smallest available volume of its storage
+// class with enough capacity and compatible access modes, and claims of a class with a
+// dynamic provisioner get a synthetic volume. Pod volumes are simulated as host paths,
+// so this pass is what surfaces claims no volume could satisfy.
+func (sim *Simulator) bindPersistentVolumeClaims() ([]PVCBinding, error) {
+	pvcList, err := sim.fakeclient.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pvcs: %v", err)
+	}
+	pvList, err := sim.fakeclient.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pvs: %v", err)
+	}
+	scList, err := sim.fakeclient.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list storage classes: %v", err)
+	}
+
+	classes := make(map[string]*storagev1.StorageClass, len(scList.Items))
+	for i := range scList.Items {
+		classes[scList.Items[i].Name] = &scList.Items[i]
+	}
+	var available []*corev1.PersistentVolume
+	for i := range pvList.Items {
+		if pvList.Items[i].Spec.ClaimRef == nil {
+			available = append(available, &pvList.Items[i])
+		}
+	}
+	// the smallest fitting volume wins, so large volumes stay free for large claims
+	sort.Slice(available, func(i, j int) bool {
+		capacityI := available[i].Spec.Capacity[corev1.ResourceStorage]
+		capacityJ := available[j].Spec.Capacity[corev1.ResourceStorage]
+		return capacityI.Cmp(capacityJ) < 0
+	})
+
+	var bindings []PVCBinding
+	for i := range pvcList.Items {
+		claim := &pvcList.Items[i]
+		requested := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+		binding := PVCBinding{
+			Namespace:    claim.Namespace,
+			Name:         claim.Name,
+			StorageClass: claimStorageClass(claim),
+			Requested:    requested.String(),
+		}
+		if claim.Spec.VolumeName != "" {
+			// already bound, e.g. a claim taken from a real cluster
+			binding.Volume = claim.Spec.VolumeName
+			bindings = append(bindings, binding)
+			continue
+		}
+		if volume := takeMatchingVolume(&available, claim, requested); volume != nil {
+			if err := sim.bindClaim(claim, volume); err != nil {
+				return nil, err
+			}
+			binding.Volume = volume.Name
+		} else if class, exist := classes[binding.StorageClass]; exist && class.Provisioner != noProvisioner {
+			volume := provisionedVolume(claim, requested)
+			if _, err := sim.fakeclient.CoreV1().PersistentVolumes().Create(context.TODO(), volume, metav1.CreateOptions{}); err != nil {
+				return nil, err
+			}
+			if err := sim.bindClaim(claim, volume); err != nil {
+				return nil, err
+			}
+			binding.Volume = volume.Name
+			binding.Provisioned = true
+		} else {
+			switch {
+			case binding.StorageClass == "":
+				binding.Reason = "no available persistent volume fits the claim"
+			case !exist:
+				binding.Reason = fmt.Sprintf("storage class %s does not exist", binding.StorageClass)
+			default:
+				binding.Reason = fmt.Sprintf("no available persistent volume of storage class %s fits the claim and the class does not provision dynamically", binding.StorageClass)
+			}
+		}
+		bindings = append(bindings, binding)
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		if bindings[i].Namespace != bindings[j].Namespace {
+			return bindings[i].Namespace < bindings[j].Namespace
+		}
+		return bindings[i].Name < bindings[j].Name
+	})
+	return bindings, nil
+}
+
+// claimStorageClass returns the storage class a claim asks for, empty for classless
+// claims that only match classless volumes
+func claimStorageClass(claim *corev1.PersistentVolumeClaim) string {
+	if claim.Spec.StorageClassName != nil {
+		return *claim.Spec.StorageClassName
+	}
+	return ""
+}
+
+// takeMatchingVolume removes and returns the smallest available volume that matches the
+// claim's storage class, capacity and access modes, or nil when none fits
+func takeMatchingVolume(available *[]*corev1.PersistentVolume, claim *corev1.PersistentVolumeClaim, requested resource.Quantity) *corev1.PersistentVolume {
+	for i, volume := range *available {
+		if volume.Spec.StorageClassName != claimStorageClass(claim) {
+			continue
+		}
+		capacity := volume.Spec.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(requested) < 0 {
+			continue
+		}
+		if !accessModesCompatible(volume.Spec.AccessModes, claim.Spec.AccessModes) {
+			continue
+		}
+		*available = append((*available)[:i], (*available)[i+1:]...)
+		return volume
+	}
+	return nil
+}
+
+// accessModesCompatible reports whether the volume offers every mode the claim asks for
+func accessModesCompatible(offered []corev1.PersistentVolumeAccessMode, wanted []corev1.PersistentVolumeAccessMode) bool {
+	for _, want := range wanted {
+		found := false
+		for _, offer := range offered {
+			if offer == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// provisionedVolume synthesizes the volume a dynamic provisioner would create for the
+// claim, sized exactly to the request
+func provisionedVolume(claim *corev1.PersistentVolumeClaim, requested resource.Quantity) *corev1.PersistentVolume {
+	return &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("provisioned-%s-%s", claim.Namespace, claim.Name),
+		},
+		Spec: corev1.PersistentVolumeSpec{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceStorage: requested,
+			},
+			AccessModes:      claim.Spec.AccessModes,
+			StorageClassName: claimStorageClass(claim),
+		},
+	}
+}
+
+// bindClaim marks claim and volume as bound to each other in the fake cluster
+func (sim *Simulator) bindClaim(claim *corev1.PersistentVolumeClaim, volume *corev1.PersistentVolume) error {
+	volume.Spec.ClaimRef = &corev1.ObjectReference{
+		Kind:      "PersistentVolumeClaim",
+		Namespace: claim.Namespace,
+		Name:      claim.Name,
+	}
+	volume.Status.Phase = corev1.VolumeBound
+	if _, err := sim.fakeclient.CoreV1().PersistentVolumes().Update(context.TODO(), volume, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	claim.Spec.VolumeName = volume.Name
+	claim.Status.Phase = corev1.ClaimBound
+	_, err := sim.fakeclient.CoreV1().PersistentVolumeClaims(claim.Namespace).Update(context.TODO(), claim, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,41 @@
+package simulator
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeschedulerconfigv1beta1 "k8s.io/kube-scheduler/config/v1beta1"
+	kubeschedulerscheme "k8s.io/kubernetes/pkg/scheduler/apis/config/scheme"
+
+	"sigs.k8s.io/yaml"
+)
+
+// EffectiveSchedulerConfig renders the KubeSchedulerConfiguration the simulation
+// actually runs with as YAML: the given config file merged with the simon, open-local
+// and open-gpu-share plugins and the scoring strategy applied, the same path
+// NewSimulator takes. It exists so placement decisions can be debugged against the
+// real plugin sets instead of the config file alone.
+func EffectiveSchedulerConfig(schedulerConfigFile string, scoringStrategy string) ([]byte, error) {
+	cc, err := GetAndSetSchedulerConfig(schedulerConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateScoringStrategy(scoringStrategy); err != nil {
+		return nil, err
+	}
+	applyScoringStrategy(cc.ComponentConfig.Profiles, scoringStrategy)
+
+	versionedCfg := kubeschedulerconfigv1beta1.KubeSchedulerConfiguration{}
+	if err := kubeschedulerscheme.Scheme.Convert(&cc.ComponentConfig, &versionedCfg, nil); err != nil {
+		return nil, fmt.Errorf("failed to convert scheduler config: %v ", err)
+	}
+	versionedCfg.TypeMeta = metav1.TypeMeta{
+		APIVersion: kubeschedulerconfigv1beta1.SchemeGroupVersion.String(),
+		Kind:       "KubeSchedulerConfiguration",
+	}
+	content, err := yaml.Marshal(&versionedCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scheduler config: %v ", err)
+	}
+	return content, nil
+}
@@ -0,0 +1,98 @@
+package simulator
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// scoreRecord is one row of the score dump: the score one scoring plugin gave one
+// candidate node for one pod
+type scoreRecord struct {
+	pod    string
+	node   string
+	plugin string
+	score  int64
+}
+
+// recordPodScores re-runs the scoring plugins of the pod's scheduling profile against all
+// nodes and records the per-plugin, per-node scores. The scores are recomputed right
+// after the pod has been placed, so they reflect the cluster state the scheduler saw
+// during its own scoring pass.
+func (sim *Simulator) recordPodScores(pod *corev1.Pod) {
+	fwk, ok := sim.scheduler.Profiles[pod.Spec.SchedulerName]
+	if !ok {
+		return
+	}
+	nodeList, err := sim.fakeclient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil || len(nodeList.Items) == 0 {
+		return
+	}
+	nodes := make([]*corev1.Node, 0, len(nodeList.Items))
+	for i := range nodeList.Items {
+		nodes = append(nodes, &nodeList.Items[i])
+	}
+
+	state := framework.NewCycleState()
+	if status := fwk.RunPreFilterPlugins(sim.ctx, state, pod); !status.IsSuccess() {
+		return
+	}
+	if status := fwk.RunPreScorePlugins(sim.ctx, state, pod, nodes); !status.IsSuccess() {
+		return
+	}
+	pluginToNodeScores, status := fwk.RunScorePlugins(sim.ctx, state, pod, nodes)
+	if !status.IsSuccess() {
+		return
+	}
+
+	for plugin, nodeScores := range pluginToNodeScores {
+		for _, nodeScore := range nodeScores {
+			sim.scoreRecords = append(sim.scoreRecords, scoreRecord{
+				pod:    fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+				node:   nodeScore.Name,
+				plugin: plugin,
+				score:  nodeScore.Score,
+			})
+		}
+	}
+}
+
+// writeScoreDump writes the recorded scores as CSV (pod, node, plugin, score) to the
+// configured path; it is a no-op when no score dump was requested
+func (sim *Simulator) writeScoreDump() error {
+	if sim.scoreDumpPath == "" {
+		return nil
+	}
+	file, err := os.Create(sim.scoreDumpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create score dump file %s: %v", sim.scoreDumpPath, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"pod", "node", "plugin", "score"}); err != nil {
+		return err
+	}
+	for _, record := range sim.scoreRecords {
+		if err := writer.Write([]string{record.pod, record.node, record.plugin, strconv.FormatInt(record.score, 10)}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WithScoreDump records the score every scoring plugin gives every candidate node for
+// each scheduled pod and writes the result as CSV to the given path. The dump can get
+// large on big clusters, so it is off unless a path is set.
+func WithScoreDump(scoreDumpPath string) Option {
+	return func(o *simulatorOptions) {
+		o.scoreDumpPath = scoreDumpPath
+	}
+}
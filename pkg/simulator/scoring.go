@@ -0,0 +1,63 @@
+package simulator
+
+import (
+	"fmt"
+
+	kubeschedulerconfig "k8s.io/kubernetes/pkg/scheduler/apis/config"
+	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/noderesources"
+)
+
+const (
+	// ScoringStrategyMostAllocated packs pods onto the fewest nodes by preferring the
+	// most loaded node that still fits, the bin-packing strategy for cost optimization
+	ScoringStrategyMostAllocated = "most-allocated"
+	// ScoringStrategyLeastAllocated spreads pods over the emptiest nodes, the scheduler
+	// default
+	ScoringStrategyLeastAllocated = "least-allocated"
+	// ScoringStrategyBalanced prefers nodes where cpu and memory end up evenly used,
+	// avoiding nodes stranded with one exhausted resource
+	ScoringStrategyBalanced = "balanced"
+)
+
+// ValidateScoringStrategy rejects unknown scoring strategies before a simulation starts
+func ValidateScoringStrategy(strategy string) error {
+	switch strategy {
+	case "", ScoringStrategyMostAllocated, ScoringStrategyLeastAllocated, ScoringStrategyBalanced:
+		return nil
+	}
+	return fmt.Errorf("invalid scoring strategy %s, supported: %s, %s, %s ", strategy, ScoringStrategyMostAllocated, ScoringStrategyLeastAllocated, ScoringStrategyBalanced)
+}
+
+// applyScoringStrategy rewires the node resource score plugins of every profile to the
+// requested strategy. This scheduler version models the strategies as three separate
+// plugins, with NodeResourcesLeastAllocated enabled by default, so the other strategies
+// swap that plugin out rather than configuring NodeResourcesFit args.
+func applyScoringStrategy(profiles []kubeschedulerconfig.KubeSchedulerProfile, strategy string) {
+	if strategy == "" || strategy == ScoringStrategyLeastAllocated {
+		return
+	}
+	for i := range profiles {
+		if profiles[i].Plugins == nil {
+			profiles[i].Plugins = &kubeschedulerconfig.Plugins{}
+		}
+		if profiles[i].Plugins.Score == nil {
+			profiles[i].Plugins.Score = &kubeschedulerconfig.PluginSet{}
+		}
+		score := profiles[i].Plugins.Score
+		score.Disabled = append(score.Disabled, kubeschedulerconfig.Plugin{Name: noderesources.LeastAllocatedName})
+		if strategy == ScoringStrategyMostAllocated {
+			score.Enabled = append(score.Enabled, kubeschedulerconfig.Plugin{Name: noderesources.MostAllocatedName})
+		}
+		// the balanced strategy leans on NodeResourcesBalancedAllocation, which is
+		// already enabled by default, so dropping least-allocated is enough
+	}
+}
+
+// WithScoringStrategy sets the node resource scoring strategy used by the scheduler.
+// It only changes how nodes are ranked; pod ordering, e.g. the greedy sort of UseGreed,
+// stays untouched.
+func WithScoringStrategy(strategy string) Option {
+	return func(o *simulatorOptions) {
+		o.scoringStrategy = strategy
+	}
+}
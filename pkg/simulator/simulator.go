@@ -2,6 +2,7 @@ package simulator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -9,13 +10,18 @@ import (
 
 	"github.com/pterm/pterm"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	kubeinformers "k8s.io/client-go/informers"
 	externalclientset "k8s.io/client-go/kubernetes"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
 	"k8s.io/kubernetes/pkg/scheduler"
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	frameworkruntime "k8s.io/kubernetes/pkg/scheduler/framework/runtime"
@@ -53,6 +59,19 @@ type Simulator struct {
 	disablePTerm    bool
 	patchPodFuncMap PatchPodsFuncMap
 
+	namespaceNodeSelectors map[string]map[string]string
+	podOverhead            corev1.ResourceList
+	ignorePendingPods      bool
+	ignorePodsSelector     labels.Selector
+	scoreDumpPath          string
+	scoreRecords           []scoreRecord
+	debugDumpPath          string
+	debugRecords           []debugRecord
+	prioritySort           bool
+	podOrder               string
+	appRequestMultipliers  map[string]float64
+	verboseFailures        bool
+
 	status status
 }
 
@@ -66,22 +85,48 @@ type PatchPodFunc = func(pods []*corev1.Pod, client externalclientset.Interface)
 type PatchPodsFuncMap map[string]PatchPodFunc
 
 type simulatorOptions struct {
-	kubeconfig      string
-	schedulerConfig string
-	disablePTerm    bool
-	extraRegistry   frameworkruntime.Registry
-	patchPodFuncMap PatchPodsFuncMap
+	kubeconfig             string
+	schedulerConfig        string
+	disablePTerm           bool
+	extraRegistry          frameworkruntime.Registry
+	patchPodFuncMap        PatchPodsFuncMap
+	namespaceNodeSelectors map[string]map[string]string
+	podOverhead            corev1.ResourceList
+	ignorePendingPods      bool
+	ignorePodsSelector     string
+	scoreDumpPath          string
+	debugDumpPath          string
+	utilizationCaps        map[corev1.ResourceName]int64
+	prioritySort           bool
+	podOrder               string
+	vgSelectionPolicy      string
+	appRequestMultipliers  map[string]float64
+	verboseFailures        bool
+	scoringStrategy        string
 }
 
 // Option configures a Simulator
 type Option func(*simulatorOptions)
 
 var defaultSimulatorOptions = simulatorOptions{
-	kubeconfig:      "",
-	schedulerConfig: "",
-	disablePTerm:    false,
-	extraRegistry:   make(map[string]frameworkruntime.PluginFactory),
-	patchPodFuncMap: make(map[string]PatchPodFunc),
+	kubeconfig:             "",
+	schedulerConfig:        "",
+	disablePTerm:           false,
+	extraRegistry:          make(map[string]frameworkruntime.PluginFactory),
+	patchPodFuncMap:        make(map[string]PatchPodFunc),
+	namespaceNodeSelectors: nil,
+	podOverhead:            nil,
+	ignorePendingPods:      false,
+	ignorePodsSelector:     "",
+	scoreDumpPath:          "",
+	debugDumpPath:          "",
+	utilizationCaps:        nil,
+	prioritySort:           false,
+	podOrder:               "",
+	vgSelectionPolicy:      "",
+	appRequestMultipliers:  nil,
+	verboseFailures:        false,
+	scoringStrategy:        "",
 }
 
 // NewSimulator generates all components that will be needed to simulate scheduling and returns a complete simulator
@@ -98,6 +143,31 @@ func NewSimulator(opts ...Option) (*Simulator, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := ValidateScoringStrategy(options.scoringStrategy); err != nil {
+		return nil, err
+	}
+	applyScoringStrategy(kubeSchedulerConfig.ComponentConfig.Profiles, options.scoringStrategy)
+
+	var ignorePodsSelector labels.Selector
+	if options.ignorePodsSelector != "" {
+		if ignorePodsSelector, err = labels.Parse(options.ignorePodsSelector); err != nil {
+			return nil, fmt.Errorf("invalid ignore-pods selector %s: %v", options.ignorePodsSelector, err)
+		}
+	}
+
+	if err := simonplugin.ValidateVGSelectionPolicy(options.vgSelectionPolicy); err != nil {
+		return nil, err
+	}
+
+	if err := ValidatePodOrder(options.podOrder); err != nil {
+		return nil, err
+	}
+
+	for appName, multiplier := range options.appRequestMultipliers {
+		if multiplier <= 0 {
+			return nil, fmt.Errorf("invalid request multiplier %g for app %s, must be positive", multiplier, appName)
+		}
+	}
 
 	// Step 2: create client
 	fakeClient := fakeclientset.NewSimpleClientset()
@@ -111,16 +181,26 @@ func NewSimulator(opts ...Option) (*Simulator, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	scheduleOneCtx, scheduleOneCancel := context.WithCancel(context.Background())
 	sim := &Simulator{
-		fakeclient:            fakeClient,
-		kubeclient:            kubeclient,
-		simulatorStop:         make(chan struct{}),
-		ctx:                   ctx,
-		cancelFunc:            cancel,
-		scheduleOneCtx:        scheduleOneCtx,
-		scheduleOneCancelFunc: scheduleOneCancel,
-		disablePTerm:          options.disablePTerm,
-		patchPodFuncMap:       options.patchPodFuncMap,
-		eventBroadcaster:      kubeSchedulerConfig.EventBroadcaster,
+		fakeclient:             fakeClient,
+		kubeclient:             kubeclient,
+		simulatorStop:          make(chan struct{}),
+		ctx:                    ctx,
+		cancelFunc:             cancel,
+		scheduleOneCtx:         scheduleOneCtx,
+		scheduleOneCancelFunc:  scheduleOneCancel,
+		disablePTerm:           options.disablePTerm,
+		patchPodFuncMap:        options.patchPodFuncMap,
+		namespaceNodeSelectors: options.namespaceNodeSelectors,
+		podOverhead:            options.podOverhead,
+		ignorePendingPods:      options.ignorePendingPods,
+		ignorePodsSelector:     ignorePodsSelector,
+		scoreDumpPath:          options.scoreDumpPath,
+		debugDumpPath:          options.debugDumpPath,
+		prioritySort:           options.prioritySort,
+		podOrder:               options.podOrder,
+		appRequestMultipliers:  options.appRequestMultipliers,
+		verboseFailures:        options.verboseFailures,
+		eventBroadcaster:       kubeSchedulerConfig.EventBroadcaster,
 	}
 
 	// Step 4: create informer
@@ -142,10 +222,16 @@ func NewSimulator(opts ...Option) (*Simulator, error) {
 	deployInformer := kubeInformerFactory.Apps().V1().Deployments().Informer()
 
 	// Step 5: add event handler for pods
+	// pods of every configured profile take part in the simulation, not only the
+	// default one, so multi-profile configs route by schedulerName as in a real cluster
+	profileNames := make(map[string]bool, len(kubeSchedulerConfig.ComponentConfig.Profiles))
+	for _, schedulerProfile := range kubeSchedulerConfig.ComponentConfig.Profiles {
+		profileNames[schedulerProfile.SchedulerName] = true
+	}
 	kubeInformerFactory.Core().V1().Pods().Informer().AddEventHandler(
 		cache.FilteringResourceEventHandler{
 			FilterFunc: func(obj interface{}) bool {
-				if pod, ok := obj.(*corev1.Pod); ok && pod.Spec.SchedulerName == simontype.DefaultSchedulerName {
+				if pod, ok := obj.(*corev1.Pod); ok && profileNames[pod.Spec.SchedulerName] {
 					return true
 				}
 				return false
@@ -192,11 +278,17 @@ func NewSimulator(opts ...Option) (*Simulator, error) {
 			return simonplugin.NewSimonPlugin(sim.fakeclient, configuration, f)
 		},
 		simontype.OpenLocalPluginName: func(configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
-			return simonplugin.NewLocalPlugin(fakeClient, storagev1Informers, configuration, f)
+			return simonplugin.NewLocalPlugin(fakeClient, storagev1Informers, options.vgSelectionPolicy, configuration, f)
 		},
 		simontype.OpenGpuSharePluginName: func(configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
 			return simonplugin.NewGpuSharePlugin(fakeClient, configuration, f)
 		},
+		simontype.UtilizationCapPluginName: func(configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
+			return simonplugin.NewUtilizationCapPlugin(options.utilizationCaps, configuration, f)
+		},
+		simontype.NodeVolumeLimitPluginName: func(configuration runtime.Object, f framework.Handle) (framework.Plugin, error) {
+			return simonplugin.NewNodeVolumeLimitPlugin(configuration, f)
+		},
 	}
 	for name, plugin := range options.extraRegistry {
 		bindRegistry[name] = plugin
@@ -235,10 +327,39 @@ func (sim *Simulator) ScheduleApp(app AppResource) (*SimulateResult, error) {
 	if err != nil {
 		return nil, err
 	}
-	affinityPriority := algo.NewAffinityQueue(appPods)
-	sort.Sort(affinityPriority)
-	tolerationPriority := algo.NewTolerationQueue(appPods)
-	sort.Sort(tolerationPriority)
+	// a canary-style multiplier scales the app's requests without touching replicas
+	if multiplier, exist := sim.appRequestMultipliers[app.Name]; exist {
+		for _, pod := range appPods {
+			scalePodRequests(pod, multiplier)
+		}
+	}
+	// split off DaemonSet pods so they are always placed first; app pods with
+	// inter-pod affinity against them (e.g. a CSI node pod) can then be satisfied
+	var daemonPods []*corev1.Pod
+	restPods := make([]*corev1.Pod, 0, len(appPods))
+	for _, pod := range appPods {
+		if pod.Annotations[simontype.AnnoWorkloadKind] == simontype.DaemonSet {
+			daemonPods = append(daemonPods, pod)
+		} else {
+			restPods = append(restPods, pod)
+		}
+	}
+	appPods = restPods
+	// app-declared order keeps the manifests' own sequence, so the pre-sorts are skipped
+	if sim.podOrder != PodOrderAppDeclared {
+		affinityPriority := algo.NewAffinityQueue(appPods)
+		sort.Sort(affinityPriority)
+		tolerationPriority := algo.NewTolerationQueue(appPods)
+		sort.Sort(tolerationPriority)
+	}
+	if sim.prioritySort {
+		// applied last so pod priority dominates the affinity and toleration ordering
+		prioritySizePriority := algo.NewPrioritySizeQueue(appPods)
+		sort.Sort(prioritySizePriority)
+	}
+	if err := sim.applyPodOrder(appPods); err != nil {
+		return nil, err
+	}
 
 	if sim.kubeclient != nil {
 		for _, patchPods := range sim.patchPodFuncMap {
@@ -263,8 +384,23 @@ func (sim *Simulator) ScheduleApp(app AppResource) (*SimulateResult, error) {
 			return nil, err
 		}
 	}
+	for _, pv := range app.Resource.PersistentVolumes {
+		if _, err := sim.fakeclient.CoreV1().PersistentVolumes().Create(context.Background(), pv, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+	}
+	for _, pvc := range app.Resource.PersistentVolumeClaims {
+		if _, err := sim.fakeclient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(context.Background(), pvc, metav1.CreateOptions{}); err != nil {
+			return nil, err
+		}
+	}
 
-	failedPod, err := sim.schedulePods(appPods)
+	allPods := append(daemonPods, appPods...)
+	failedPod, err := sim.schedulePods(allPods)
+	if err != nil {
+		return nil, err
+	}
+	failedPod, err = sim.enforceGangScheduling(allPods, failedPod)
 	if err != nil {
 		return nil, err
 	}
@@ -307,6 +443,7 @@ func (sim *Simulator) runScheduler() {
 
 // Run starts to schedule pods
 func (sim *Simulator) schedulePods(pods []*corev1.Pod) ([]UnscheduledPod, error) {
+	pods = sim.filterIgnoredPods(pods)
 	var failedPods []UnscheduledPod
 	var progressBar *pterm.ProgressbarPrinter
 	if !sim.disablePTerm {
@@ -320,6 +457,22 @@ func (sim *Simulator) schedulePods(pods []*corev1.Pod) ([]UnscheduledPod, error)
 			// Update the title of the progressbar.
 			progressBar.UpdateTitle(fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
 		}
+		sim.injectNamespaceNodeSelector(pod)
+		applyRequestDefaults(pod)
+		applySidecarContainers(pod)
+		sim.applyPodOverhead(pod)
+		applyEmptyDirStorage(pod)
+		// a pending pod aimed at a scheduler name no profile serves would wait forever
+		if _, exist := sim.scheduler.Profiles[pod.Spec.SchedulerName]; !exist && pod.Spec.NodeName == "" {
+			failedPods = append(failedPods, UnscheduledPod{
+				Pod:    pod,
+				Reason: fmt.Sprintf("no scheduler profile named %s", pod.Spec.SchedulerName),
+			})
+			if !sim.disablePTerm {
+				progressBar.Increment()
+			}
+			continue
+		}
 		if _, err := sim.fakeclient.CoreV1().Pods(pod.Namespace).Create(context.Background(), pod, metav1.CreateOptions{}); err != nil {
 			return nil, fmt.Errorf("%s %s/%s: %s", simontype.CreatePodError, pod.Namespace, pod.Name, err.Error())
 		}
@@ -334,11 +487,31 @@ func (sim *Simulator) schedulePods(pods []*corev1.Pod) ([]UnscheduledPod, error)
 			if err := sim.fakeclient.CoreV1().Pods(pod.Namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{}); err != nil {
 				return nil, fmt.Errorf("%s %s/%s: %s", simontype.DeletePodError, pod.Namespace, pod.Name, err.Error())
 			}
-			failedPods = append(failedPods, UnscheduledPod{
+			reason := sim.status.stopReason
+			if osReason := sim.podOSMismatchReason(pod); osReason != "" {
+				reason = fmt.Sprintf("%s: %s", osReason, reason)
+			} else if featureReason := sim.podFeatureLabelReason(pod); featureReason != "" {
+				reason = fmt.Sprintf("%s: %s", featureReason, reason)
+			}
+			if strings.Contains(reason, "Insufficient") {
+				if shortfall := sim.podResourceShortfall(pod); shortfall != "" {
+					reason = fmt.Sprintf("%s (%s)", reason, shortfall)
+				}
+			}
+			unscheduledPod := UnscheduledPod{
 				Pod:    pod,
-				Reason: sim.status.stopReason,
-			})
+				Reason: reason,
+			}
+			if sim.verboseFailures {
+				unscheduledPod.FilterFailures = sim.collectFilterFailures(pod)
+			}
+			failedPods = append(failedPods, unscheduledPod)
 			sim.status.stopReason = ""
+		} else if sim.scoreDumpPath != "" {
+			sim.recordPodScores(pod)
+		}
+		if sim.debugDumpPath != "" {
+			sim.recordPodDebug(pod)
 		}
 		if !sim.disablePTerm {
 			progressBar.Increment()
@@ -347,6 +520,344 @@ func (sim *Simulator) schedulePods(pods []*corev1.Pod) ([]UnscheduledPod, error)
 	return failedPods, nil
 }
 
+// enforceGangScheduling applies all-or-nothing semantics to pods sharing a gang label,
+// e.g. the workers of a distributed training job that needs all its GPUs at once. When
+// only part of a gang fits, the members that were placed are rolled back so they stop
+// holding capacity, and every placed member is reported as unschedulable.
+func (sim *Simulator) enforceGangScheduling(pods []*corev1.Pod, failedPods []UnscheduledPod) ([]UnscheduledPod, error) {
+	gangSize := make(map[string]int)
+	for _, pod := range pods {
+		if gang, exist := pod.Labels[simontype.LabelGangName]; exist {
+			gangSize[gang]++
+		}
+	}
+	if len(gangSize) == 0 {
+		return failedPods, nil
+	}
+
+	gangFailed := make(map[string]int)
+	alreadyFailed := make(map[string]bool, len(failedPods))
+	for _, failedPod := range failedPods {
+		alreadyFailed[fmt.Sprintf("%s/%s", failedPod.Pod.Namespace, failedPod.Pod.Name)] = true
+		if gang, exist := failedPod.Pod.Labels[simontype.LabelGangName]; exist {
+			gangFailed[gang]++
+		}
+	}
+
+	for _, pod := range pods {
+		gang, exist := pod.Labels[simontype.LabelGangName]
+		if !exist || gangFailed[gang] == 0 || alreadyFailed[fmt.Sprintf("%s/%s", pod.Namespace, pod.Name)] {
+			continue
+		}
+		// roll back the placed member so the incomplete gang frees its capacity
+		if err := sim.fakeclient.CoreV1().Pods(pod.Namespace).Delete(context.Background(), pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%s %s/%s: %s", simontype.DeletePodError, pod.Namespace, pod.Name, err.Error())
+		}
+		failedPods = append(failedPods, UnscheduledPod{
+			Pod:    pod,
+			Reason: fmt.Sprintf("gang %s could not be scheduled: only %d of %d pods fit", gang, gangSize[gang]-gangFailed[gang], gangSize[gang]),
+		})
+	}
+	return failedPods, nil
+}
+
+// filterIgnoredPods drops every pod matched by the ignore-pods selector before it reaches
+// the scheduler, so it neither gets placed nor consumes capacity in the report
+func (sim *Simulator) filterIgnoredPods(pods []*corev1.Pod) []*corev1.Pod {
+	if sim.ignorePodsSelector == nil {
+		return pods
+	}
+	kept := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if sim.ignorePodsSelector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		kept = append(kept, pod)
+	}
+	if skipped := len(pods) - len(kept); skipped != 0 && !sim.disablePTerm {
+		pterm.FgYellow.Printf("ignore %d pod(s) matching the ignore-pods selector\n", skipped)
+	}
+	return kept
+}
+
+// applyRequestDefaults mirrors the API-server defaulting that sets a container request to
+// its limit when only the limit is declared. The fake client never runs admission, so
+// without this a limit-only Burstable container would be scheduled with a zero request.
+func applyRequestDefaults(pod *corev1.Pod) {
+	defaultRequests := func(containers []corev1.Container) {
+		for i := range containers {
+			resources := &containers[i].Resources
+			for name, limit := range resources.Limits {
+				if _, exist := resources.Requests[name]; exist {
+					continue
+				}
+				if resources.Requests == nil {
+					resources.Requests = corev1.ResourceList{}
+				}
+				resources.Requests[name] = limit.DeepCopy()
+			}
+		}
+	}
+	defaultRequests(pod.Spec.InitContainers)
+	defaultRequests(pod.Spec.Containers)
+}
+
+// applySidecarContainers moves native sidecars (init containers that keep running for
+// the whole pod lifetime, Kubernetes 1.28+) into the regular containers, so their
+// requests count towards steady-state demand instead of only the init maximum. The
+// vendored API predates the restartPolicy field on containers, so sidecars are named in
+// the simon/sidecar-containers annotation as a comma-separated list.
+func applySidecarContainers(pod *corev1.Pod) {
+	names, exist := pod.Annotations[simontype.AnnoPodSidecarContainers]
+	if !exist {
+		return
+	}
+	sidecars := make(map[string]struct{})
+	for _, name := range strings.Split(names, ",") {
+		sidecars[strings.TrimSpace(name)] = struct{}{}
+	}
+	var initContainers []corev1.Container
+	for _, container := range pod.Spec.InitContainers {
+		if _, sidecar := sidecars[container.Name]; sidecar {
+			pod.Spec.Containers = append(pod.Spec.Containers, container)
+			continue
+		}
+		initContainers = append(initContainers, container)
+	}
+	pod.Spec.InitContainers = initContainers
+}
+
+// scalePodRequests multiplies the resource requests of every container of the pod,
+// modeling a canary that runs at a fraction of its production requests. Limits are left
+// alone; a request a multiplier above 1 would push past its declared limit is clamped
+// to that limit, since the apiserver admits no pod requesting more than it is limited to.
+func scalePodRequests(pod *corev1.Pod, multiplier float64) {
+	scaleRequests := func(containers []corev1.Container) {
+		for i := range containers {
+			for name, request := range containers[i].Resources.Requests {
+				scaled := *resource.NewMilliQuantity(int64(float64(request.MilliValue())*multiplier), request.Format)
+				if limit, exist := containers[i].Resources.Limits[name]; exist && scaled.Cmp(limit) > 0 {
+					scaled = limit.DeepCopy()
+				}
+				containers[i].Resources.Requests[name] = scaled
+			}
+		}
+	}
+	scaleRequests(pod.Spec.InitContainers)
+	scaleRequests(pod.Spec.Containers)
+}
+
+// applyPodOverhead stamps the configured per-pod overhead onto pods that do not declare
+// one of their own, so the scheduler also accounts for kubelet and container-runtime
+// overhead that never shows up in container requests
+func (sim *Simulator) applyPodOverhead(pod *corev1.Pod) {
+	if len(sim.podOverhead) == 0 || pod.Spec.Overhead != nil {
+		return
+	}
+	pod.Spec.Overhead = sim.podOverhead.DeepCopy()
+}
+
+// applyEmptyDirStorage counts the sizeLimit of disk-backed emptyDir volumes against node
+// ephemeral storage. The kubelet only enforces the limit at runtime and the scheduler
+// only sees container requests, so the summed limits are folded into the pod overhead
+// where the scheduler does account them.
+func applyEmptyDirStorage(pod *corev1.Pod) {
+	demand := resource.Quantity{}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir == nil || volume.EmptyDir.SizeLimit == nil {
+			continue
+		}
+		// memory-backed emptyDirs live in tmpfs and do not consume disk
+		if volume.EmptyDir.Medium == corev1.StorageMediumMemory {
+			continue
+		}
+		demand.Add(*volume.EmptyDir.SizeLimit)
+	}
+	if demand.IsZero() {
+		return
+	}
+	if pod.Spec.Overhead == nil {
+		pod.Spec.Overhead = corev1.ResourceList{}
+	}
+	overhead := pod.Spec.Overhead[corev1.ResourceEphemeralStorage]
+	overhead.Add(demand)
+	pod.Spec.Overhead[corev1.ResourceEphemeralStorage] = overhead
+}
+
+// waitForSchedulerCacheNodes blocks until the scheduler cache holds at least nodeCount nodes
+func (sim *Simulator) waitForSchedulerCacheNodes(nodeCount int) error {
+	return wait.PollImmediate(10*time.Millisecond, 10*time.Second, func() (bool, error) {
+		return sim.scheduler.SchedulerCache.NodeCount() >= nodeCount, nil
+	})
+}
+
+// injectNamespaceNodeSelector merges the default node selector configured for the pod's
+// namespace into the pod, mimicking the PodNodeSelector admission plugin. Keys already
+// set on the pod win over the namespace default.
+func (sim *Simulator) injectNamespaceNodeSelector(pod *corev1.Pod) {
+	defaultSelector, exist := sim.namespaceNodeSelectors[pod.Namespace]
+	if !exist {
+		return
+	}
+	if pod.Spec.NodeSelector == nil {
+		pod.Spec.NodeSelector = make(map[string]string, len(defaultSelector))
+	}
+	for key, value := range defaultSelector {
+		if _, exist := pod.Spec.NodeSelector[key]; !exist {
+			pod.Spec.NodeSelector[key] = value
+		}
+	}
+}
+
+// podOSMismatchReason returns a distinct unscheduled reason when the pod requires an
+// operating system that no node in the cluster provides, and "" otherwise
+func (sim *Simulator) podOSMismatchReason(pod *corev1.Pod) string {
+	requiredOS := utils.GetPodRequiredOS(pod)
+	if requiredOS == "" {
+		return ""
+	}
+	nodes, err := sim.fakeclient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return ""
+	}
+	for _, node := range nodes.Items {
+		if utils.GetNodeOS(&node) == requiredOS {
+			return ""
+		}
+	}
+	return fmt.Sprintf("pod requires OS %s but no node in the cluster provides it", requiredOS)
+}
+
+// podFeatureLabelReason spots the pods that require a node label, e.g. an NFD feature
+// label, that no node in the cluster carries with a matching value. The generic "didn't
+// match node selector" event hides which label is missing, so the reason names it.
+func (sim *Simulator) podFeatureLabelReason(pod *corev1.Pod) string {
+	required := make(map[string][]string)
+	for key, value := range pod.Spec.NodeSelector {
+		required[key] = []string{value}
+	}
+	if affinity := pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil &&
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil {
+		for _, term := range affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+			for _, expr := range term.MatchExpressions {
+				if expr.Operator == corev1.NodeSelectorOpIn || expr.Operator == corev1.NodeSelectorOpExists {
+					required[expr.Key] = expr.Values
+				}
+			}
+		}
+	}
+	if len(required) == 0 {
+		return ""
+	}
+
+	nodes, err := sim.fakeclient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return ""
+	}
+	for key, values := range required {
+		satisfied := false
+		for _, node := range nodes.Items {
+			nodeValue, exist := node.Labels[key]
+			if !exist {
+				continue
+			}
+			if len(values) == 0 {
+				satisfied = true
+				break
+			}
+			for _, value := range values {
+				if nodeValue == value {
+					satisfied = true
+					break
+				}
+			}
+			if satisfied {
+				break
+			}
+		}
+		if !satisfied {
+			return fmt.Sprintf("no node with required feature label %s", key)
+		}
+	}
+	return ""
+}
+
+// podResourceShortfall quantifies, for a pod rejected on resources, how much cpu and
+// memory the pod lacks on the best-fit node, e.g. "needs 2 more cpu and 4Gi more memory
+// on the best-fit node simon-00". It returns "" when the gap cannot be determined.
+func (sim *Simulator) podResourceShortfall(pod *corev1.Pod) string {
+	podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+	nodes, err := sim.fakeclient.CoreV1().Nodes().List(context.Background(), metav1.ListOptions{})
+	if err != nil || len(nodes.Items) == 0 {
+		return ""
+	}
+	allPods, err := sim.fakeclient.CoreV1().Pods(corev1.NamespaceAll).List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		return ""
+	}
+
+	// free capacity per node, i.e. allocatable minus the requests of the pods placed there
+	bestNode := ""
+	var bestFree corev1.ResourceList
+	bestShortfall := int64(-1)
+	for _, node := range nodes.Items {
+		free := corev1.ResourceList{
+			corev1.ResourceCPU:    node.Status.Allocatable[corev1.ResourceCPU].DeepCopy(),
+			corev1.ResourceMemory: node.Status.Allocatable[corev1.ResourceMemory].DeepCopy(),
+		}
+		for _, scheduledPod := range allPods.Items {
+			if scheduledPod.Spec.NodeName != node.Name {
+				continue
+			}
+			scheduledReqs, _ := resourcehelper.PodRequestsAndLimits(&scheduledPod)
+			for name, quantity := range free {
+				used := scheduledReqs[name]
+				quantity.Sub(used)
+				free[name] = quantity
+			}
+		}
+		// rank nodes by the cpu shortfall in milli units plus the memory shortfall in Mi,
+		// the node with the smallest combined gap is the best fit
+		shortfall := int64(0)
+		for name, quantity := range free {
+			req := podReqs[name]
+			gap := req.DeepCopy()
+			gap.Sub(quantity)
+			if gap.Sign() <= 0 {
+				continue
+			}
+			if name == corev1.ResourceCPU {
+				shortfall += gap.MilliValue()
+			} else {
+				shortfall += gap.Value() / (1024 * 1024)
+			}
+		}
+		if bestShortfall == -1 || shortfall < bestShortfall {
+			bestShortfall = shortfall
+			bestNode = node.Name
+			bestFree = free
+		}
+	}
+	if bestShortfall <= 0 {
+		return ""
+	}
+
+	var gaps []string
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		req := podReqs[name]
+		gap := req.DeepCopy()
+		free := bestFree[name]
+		gap.Sub(free)
+		if gap.Sign() > 0 {
+			gaps = append(gaps, fmt.Sprintf("%s more %s", gap.String(), name))
+		}
+	}
+	if len(gaps) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("needs %s on the best-fit node %s", strings.Join(gaps, " and "), bestNode)
+}
+
 func (sim *Simulator) Close() {
 	sim.scheduleOneCancelFunc()
 	testpod := test.MakeFakePod("test", "test", "", "")
@@ -364,11 +875,20 @@ func (sim *Simulator) Close() {
 
 func (sim *Simulator) syncClusterResourceList(resourceList ResourceTypes) (*SimulateResult, error) {
 	//sync node
+	reserved := reservedResourcePerNode(resourceList)
 	for _, item := range resourceList.Nodes {
+		if quantities, exist := reserved[item.Name]; exist {
+			item = applyCapacityReservation(item, quantities)
+		}
 		if _, err := sim.fakeclient.CoreV1().Nodes().Create(context.TODO(), item, metav1.CreateOptions{}); err != nil {
 			return nil, fmt.Errorf("unable to copy node: %v", err)
 		}
 	}
+	// wait until the scheduler cache has observed the synced nodes, otherwise pods
+	// scheduled right afterwards may be rejected with "no nodes available"
+	if err := sim.waitForSchedulerCacheNodes(len(resourceList.Nodes)); err != nil {
+		return nil, err
+	}
 
 	//sync pdb
 	for _, item := range resourceList.PodDisruptionBudgets {
@@ -391,6 +911,13 @@ func (sim *Simulator) syncClusterResourceList(resourceList ResourceTypes) (*Simu
 		}
 	}
 
+	//sync pv
+	for _, item := range resourceList.PersistentVolumes {
+		if _, err := sim.fakeclient.CoreV1().PersistentVolumes().Create(context.TODO(), item, metav1.CreateOptions{}); err != nil {
+			return nil, fmt.Errorf("unable to copy pv: %v", err)
+		}
+	}
+
 	//sync pvc
 	for _, item := range resourceList.PersistentVolumeClaims {
 		if _, err := sim.fakeclient.CoreV1().PersistentVolumeClaims(item.Namespace).Create(context.TODO(), item, metav1.CreateOptions{}); err != nil {
@@ -434,8 +961,22 @@ func (sim *Simulator) syncClusterResourceList(resourceList ResourceTypes) (*Simu
 	}
 
 	// sync pods
-	pterm.FgYellow.Printf("sync %d pod(s) to fake cluster\n", len(resourceList.Pods))
-	failedPods, err := sim.schedulePods(resourceList.Pods)
+	clusterPods := resourceList.Pods
+	if sim.ignorePendingPods {
+		scheduledPods := make([]*corev1.Pod, 0, len(clusterPods))
+		for _, pod := range clusterPods {
+			if pod.Spec.NodeName == "" {
+				continue
+			}
+			scheduledPods = append(scheduledPods, pod)
+		}
+		if skipped := len(clusterPods) - len(scheduledPods); skipped != 0 {
+			pterm.FgYellow.Printf("ignore %d pending pod(s) from the cluster snapshot\n", skipped)
+		}
+		clusterPods = scheduledPods
+	}
+	pterm.FgYellow.Printf("sync %d pod(s) to fake cluster\n", len(clusterPods))
+	failedPods, err := sim.schedulePods(clusterPods)
 	if err != nil {
 		return nil, err
 	}
@@ -446,6 +987,58 @@ func (sim *Simulator) syncClusterResourceList(resourceList ResourceTypes) (*Simu
 	}, nil
 }
 
+// reservedResourcePerNode accumulates the reserved resources of all capacity reservations
+// by node name. A reservation without an explicit node list applies to every node.
+func reservedResourcePerNode(resourceList ResourceTypes) map[string]corev1.ResourceList {
+	reserved := make(map[string]corev1.ResourceList)
+	addReserved := func(nodeName string, resources corev1.ResourceList) {
+		quantities, exist := reserved[nodeName]
+		if !exist {
+			quantities = corev1.ResourceList{}
+			reserved[nodeName] = quantities
+		}
+		for name, quantity := range resources {
+			if value, exist := quantities[name]; exist {
+				value.Add(quantity)
+				quantities[name] = value
+			} else {
+				quantities[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	for _, reservation := range resourceList.CapacityReservations {
+		if len(reservation.Nodes) == 0 {
+			for _, node := range resourceList.Nodes {
+				addReserved(node.Name, reservation.Resources)
+			}
+			continue
+		}
+		for _, nodeName := range reservation.Nodes {
+			addReserved(nodeName, reservation.Resources)
+		}
+	}
+	return reserved
+}
+
+// applyCapacityReservation subtracts the reserved resources from the allocatable of the node
+// and records the reservation in an annotation so reports can show reserved vs available
+func applyCapacityReservation(node *corev1.Node, reserved corev1.ResourceList) *corev1.Node {
+	newNode := node.DeepCopy()
+	for name, quantity := range reserved {
+		if value, exist := newNode.Status.Allocatable[name]; exist {
+			value.Sub(quantity)
+			if value.Sign() < 0 {
+				value.Set(0)
+			}
+			newNode.Status.Allocatable[name] = value
+		}
+	}
+	if b, err := json.Marshal(reserved); err == nil {
+		metav1.SetMetaDataAnnotation(&newNode.ObjectMeta, simontype.AnnoNodeReservedResource, string(b))
+	}
+	return newNode
+}
+
 func (sim *Simulator) update(pod *corev1.Pod) {
 	var stop bool = false
 	var stopReason string
@@ -487,6 +1080,76 @@ func WithExtraRegistry(extraRegistry frameworkruntime.Registry) Option {
 	}
 }
 
+// WithIgnorePendingPods controls how pods from the cluster snapshot that are still
+// Pending (no node assigned) are handled. By default they are scheduled like the rest of
+// the snapshot so the report reflects what the cluster is trying to run; with ignore set
+// they are dropped. Pods belonging to the separately provided apps are always scheduled.
+func WithIgnorePendingPods(ignorePendingPods bool) Option {
+	return func(o *simulatorOptions) {
+		o.ignorePendingPods = ignorePendingPods
+	}
+}
+
+// WithIgnorePodsSelector drops every pod whose labels match the given selector from
+// scheduling and accounting, e.g. debug or one-off pods that should not count in
+// capacity planning. The capacity those pods would have used stays free for others.
+func WithIgnorePodsSelector(ignorePodsSelector string) Option {
+	return func(o *simulatorOptions) {
+		o.ignorePodsSelector = ignorePodsSelector
+	}
+}
+
+// WithPodOverhead sets a per-pod resource tax that is stamped onto every scheduled pod
+// as pod overhead, making the simulated headroom more conservative
+func WithPodOverhead(podOverhead corev1.ResourceList) Option {
+	return func(o *simulatorOptions) {
+		o.podOverhead = podOverhead
+	}
+}
+
+// WithPrioritySort feeds pods to the scheduler ordered by pod priority first and
+// resource size second, mirroring scheduler admission on priority-heavy clusters
+func WithPrioritySort(prioritySort bool) Option {
+	return func(o *simulatorOptions) {
+		o.prioritySort = prioritySort
+	}
+}
+
+// WithVGSelectionPolicy sets the VG selection policy for open-local LVM volumes
+// (first-fit, least-allocated or most-allocated), so storage planners can model how
+// different packing strategies fill up the VGs of a node
+func WithVGSelectionPolicy(vgSelectionPolicy string) Option {
+	return func(o *simulatorOptions) {
+		o.vgSelectionPolicy = vgSelectionPolicy
+	}
+}
+
+// WithAppRequestMultipliers scales the resource requests of the named apps by the given
+// factors without changing replica counts, so a canary running at a fraction of its
+// production requests can be modeled
+func WithAppRequestMultipliers(appRequestMultipliers map[string]float64) Option {
+	return func(o *simulatorOptions) {
+		o.appRequestMultipliers = appRequestMultipliers
+	}
+}
+
+// WithUtilizationCaps sets per-resource node utilization caps in percent, e.g. cpu: 80.
+// The scheduler then refuses to place a pod on a node it would push above a cap, so
+// packing leaves headroom and scale-out happens earlier.
+func WithUtilizationCaps(utilizationCaps map[corev1.ResourceName]int64) Option {
+	return func(o *simulatorOptions) {
+		o.utilizationCaps = utilizationCaps
+	}
+}
+
+// WithNamespaceNodeSelectors sets default node selectors that are merged into the pods of
+// the given namespaces before scheduling, like the PodNodeSelector admission plugin does
+func WithNamespaceNodeSelectors(namespaceNodeSelectors map[string]map[string]string) Option {
+	return func(o *simulatorOptions) {
+		o.namespaceNodeSelectors = namespaceNodeSelectors
+	}
+}
+
 func WithPatchPodsFuncMap(patchPodsFuncMap PatchPodsFuncMap) Option {
 	return func(o *simulatorOptions) {
 		o.patchPodFuncMap = patchPodsFuncMap
@@ -617,3 +1280,24 @@ func CreateClusterResourceFromClusterConfig(path string) (ResourceTypes, error)
 
 	return resource, nil
 }
+
+// CreateClusterResourceFromSnapshotDir assembles the cluster from a directory of YAML
+// dumps, e.g. `kubectl get nodes,pods -o yaml` exports, so air-gapped environments can
+// simulate without a reachable API server. Objects wrapped in a v1 List are unwrapped
+// before decoding.
+func CreateClusterResourceFromSnapshotDir(path string) (ResourceTypes, error) {
+	var resource ResourceTypes
+	var content []string
+	var err error
+
+	if content, err = utils.GetYamlContentFromDirectory(path); err != nil {
+		return ResourceTypes{}, fmt.Errorf("failed to get the yaml content from the snapshot directory(%s): %v", path, err)
+	}
+	if resource, err = GetObjectFromYamlContent(flattenListManifests(content)); err != nil {
+		return resource, err
+	}
+
+	MatchAndSetLocalStorageAnnotationOnNode(resource.Nodes, path)
+
+	return resource, nil
+}
@@ -29,6 +29,9 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework/plugins/defaultbinder"
 	"k8s.io/kubernetes/pkg/scheduler/profile"
 
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+
 	simontype "github.com/alibaba/open-simulator/pkg/type"
 	"github.com/alibaba/open-simulator/pkg/utils"
 )
@@ -70,6 +73,19 @@ func GenerateValidPodsFromAppResources(client externalclientset.Interface, appna
 		metav1.SetMetaDataLabel(&pod.ObjectMeta, simontype.LabelAppName, appname)
 	}
 
+	// tag every pod with a name that is stable across runs; generated pod names carry
+	// a random suffix, so repeated simulations could otherwise not recognize the same
+	// pod again
+	ordinals := make(map[string]int)
+	for _, pod := range pods {
+		base := pod.Name
+		if pod.GenerateName != "" {
+			base = pod.GenerateName
+		}
+		metav1.SetMetaDataAnnotation(&pod.ObjectMeta, simontype.AnnoPodStableName, fmt.Sprintf("%s-%d", base, ordinals[base]))
+		ordinals[base]++
+	}
+
 	return pods, nil
 }
 
@@ -254,6 +270,8 @@ func GetObjectFromYamlContent(ymlStr []string) (ResourceTypes, error) {
 				resources.Services = append(resources.Services, o)
 			case *corev1.PersistentVolumeClaim:
 				resources.PersistentVolumeClaims = append(resources.PersistentVolumeClaims, o)
+			case *corev1.PersistentVolume:
+				resources.PersistentVolumes = append(resources.PersistentVolumes, o)
 			case *apps.ReplicaSet:
 				resources.ReplicaSets = append(resources.ReplicaSets, o)
 			case *batchv1.Job:
@@ -265,6 +283,7 @@ func GetObjectFromYamlContent(ymlStr []string) (ResourceTypes, error) {
 			case *v1beta1.PodDisruptionBudget:
 				resources.PodDisruptionBudgets = append(resources.PodDisruptionBudgets, o)
 			default:
+				utils.AddWarning("decode", "unsupported kind %T is ignored", o)
 				log.Debugf("unknown type(%T): %s\n", o, ymlStr)
 				continue
 			}
@@ -274,6 +293,25 @@ func GetObjectFromYamlContent(ymlStr []string) (ResourceTypes, error) {
 	return resources, nil
 }
 
+// flattenListManifests unwraps objects dumped inside a v1 List, the shape kubectl get -o
+// yaml produces, so the regular object decoding sees plain manifests
+func flattenListManifests(ymlStr []string) []string {
+	var flattened []string
+	for _, res := range ymlStr {
+		for _, manifest := range releaseutil.SplitManifests(res) {
+			var list corev1.List
+			if err := yaml.Unmarshal([]byte(manifest), &list); err != nil || list.Kind != "List" {
+				flattened = append(flattened, manifest)
+				continue
+			}
+			for _, item := range list.Items {
+				flattened = append(flattened, string(item.Raw))
+			}
+		}
+	}
+	return flattened
+}
+
 func InitKubeSchedulerConfiguration(opts *schedoptions.Options) (*schedconfig.CompletedConfig, error) {
 	c := &schedconfig.Config{}
 	// clear out all unnecessary options so no port is bound
@@ -339,6 +377,14 @@ func GetAndSetSchedulerConfig(schedulerConfig string) (*config.CompletedConfig,
 			{
 				Name: simontype.OpenGpuSharePluginName,
 			},
+			{
+				// a no-op unless utilization caps are configured on the simulator
+				Name: simontype.UtilizationCapPluginName,
+			},
+			{
+				// a no-op unless nodes carry a volume attach limit annotation
+				Name: simontype.NodeVolumeLimitPluginName,
+			},
 		},
 	}
 	kcfg.Profiles[0].Plugins.Reserve = &kubeschedulerconfig.PluginSet{
@@ -377,9 +423,37 @@ func GetAndSetSchedulerConfig(schedulerConfig string) (*config.CompletedConfig,
 	if err != nil {
 		return nil, fmt.Errorf("failed to init kube scheduler configuration: %v ", err)
 	}
+	ensureSimonBindPlugin(cc.ComponentConfig.Profiles)
 	return cc, nil
 }
 
+// ensureSimonBindPlugin makes every scheduler profile bind through the Simon plugin
+// instead of the default binder, so a config file can add extra profiles without
+// repeating the simulator plumbing and pods routed to them stay inside the simulation
+func ensureSimonBindPlugin(profiles []kubeschedulerconfig.KubeSchedulerProfile) {
+	for i := range profiles {
+		if profiles[i].Plugins == nil {
+			profiles[i].Plugins = &kubeschedulerconfig.Plugins{}
+		}
+		plugins := profiles[i].Plugins
+		if plugins.Bind == nil {
+			plugins.Bind = &kubeschedulerconfig.PluginSet{}
+		}
+		simonBound := false
+		for _, plugin := range plugins.Bind.Enabled {
+			if plugin.Name == simontype.SimonPluginName {
+				simonBound = true
+				break
+			}
+		}
+		if simonBound {
+			continue
+		}
+		plugins.Bind.Enabled = append(plugins.Bind.Enabled, kubeschedulerconfig.Plugin{Name: simontype.SimonPluginName})
+		plugins.Bind.Disabled = append(plugins.Bind.Disabled, kubeschedulerconfig.Plugin{Name: defaultbinder.Name})
+	}
+}
+
 // MatchAndSetLocalStorageAnnotationOnNode add storage information configured by json file, belonging to the node that matches
 // the json file name, to annotation of this node
 func MatchAndSetLocalStorageAnnotationOnNode(nodes []*corev1.Node, dir string) {
@@ -0,0 +1,384 @@
+package simulator
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// ConstraintViolation records a scheduled pod whose placement breaks a required
+// pod affinity or anti-affinity term. Such a violation indicates a bug or an
+// unsupported constraint rather than a legal scheduling result.
+type ConstraintViolation struct {
+	Pod     *corev1.Pod `json:"pod"`
+	Node    string      `json:"node"`
+	Message string      `json:"message"`
+}
+
+// ValidateAffinityConstraints checks every scheduled pod in the result against its required
+// pod affinity/anti-affinity terms and returns the violations it finds. It is a self-check on
+// the simulation result: an empty return value means all placements are consistent with the
+// declared constraints.
+func ValidateAffinityConstraints(nodeStatuses []NodeStatus) []ConstraintViolation {
+	var violations []ConstraintViolation
+
+	nodeMap := make(map[string]*corev1.Node, len(nodeStatuses))
+	var allPods []*corev1.Pod
+	for _, status := range nodeStatuses {
+		nodeMap[status.Node.Name] = status.Node
+		allPods = append(allPods, status.Pods...)
+	}
+
+	for _, status := range nodeStatuses {
+		for _, pod := range status.Pods {
+			if pod.Spec.Affinity == nil {
+				continue
+			}
+			if podAffinity := pod.Spec.Affinity.PodAffinity; podAffinity != nil {
+				for _, term := range podAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+					matched, err := matchPodsInTopologyDomain(pod, status.Node, term, nodeMap, allPods)
+					if err != nil {
+						violations = append(violations, ConstraintViolation{
+							Pod:     pod,
+							Node:    status.Node.Name,
+							Message: err.Error(),
+						})
+						continue
+					}
+					if !matched {
+						violations = append(violations, ConstraintViolation{
+							Pod:     pod,
+							Node:    status.Node.Name,
+							Message: fmt.Sprintf("required pod affinity term (topologyKey: %s) has no matching pod in the same topology domain", term.TopologyKey),
+						})
+					}
+				}
+			}
+			if podAntiAffinity := pod.Spec.Affinity.PodAntiAffinity; podAntiAffinity != nil {
+				for _, term := range podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+					matched, err := matchPodsInTopologyDomain(pod, status.Node, term, nodeMap, allPods)
+					if err != nil {
+						violations = append(violations, ConstraintViolation{
+							Pod:     pod,
+							Node:    status.Node.Name,
+							Message: err.Error(),
+						})
+						continue
+					}
+					if matched {
+						violations = append(violations, ConstraintViolation{
+							Pod:     pod,
+							Node:    status.Node.Name,
+							Message: fmt.Sprintf("required pod anti-affinity term (topologyKey: %s) matches a peer pod in the same topology domain", term.TopologyKey),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// matchPodsInTopologyDomain reports whether any pod other than the given one matches the
+// affinity term within the topology domain the pod's node belongs to
+func matchPodsInTopologyDomain(pod *corev1.Pod, node *corev1.Node, term corev1.PodAffinityTerm, nodeMap map[string]*corev1.Node, allPods []*corev1.Pod) (bool, error) {
+	count, err := countPodsInTopologyDomain(pod, node, term, nodeMap, allPods)
+	return count > 0, err
+}
+
+// countPodsInTopologyDomain counts the pods other than the given one that match the
+// affinity term within the topology domain the pod's node belongs to
+func countPodsInTopologyDomain(pod *corev1.Pod, node *corev1.Node, term corev1.PodAffinityTerm, nodeMap map[string]*corev1.Node, allPods []*corev1.Pod) (int, error) {
+	selector, err := metav1.LabelSelectorAsSelector(term.LabelSelector)
+	if err != nil {
+		return 0, fmt.Errorf("invalid label selector in affinity term: %v", err)
+	}
+
+	domainValue, exist := node.Labels[term.TopologyKey]
+	if !exist {
+		// a node without the topology key belongs to no domain, so nothing can match
+		return 0, nil
+	}
+
+	namespaces := term.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{pod.Namespace}
+	}
+	namespaceSet := make(map[string]struct{}, len(namespaces))
+	for _, ns := range namespaces {
+		namespaceSet[ns] = struct{}{}
+	}
+
+	count := 0
+	for _, peer := range allPods {
+		if peer.UID == pod.UID {
+			continue
+		}
+		if _, exist := namespaceSet[peer.Namespace]; !exist {
+			continue
+		}
+		peerNode, exist := nodeMap[peer.Spec.NodeName]
+		if !exist {
+			continue
+		}
+		if peerNode.Labels[term.TopologyKey] != domainValue {
+			continue
+		}
+		if selector.Matches(labels.Set(peer.Labels)) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// AntiAffinityStatus summarizes the placement outcome of one scheduled pod that declares
+// pod anti-affinity. Required-term violations are hard failures the scheduler must never
+// produce; preferred-term violations are legal trades the scheduler was allowed to make,
+// listed so HA setups can see how many replicas ended up co-located anyway.
+type AntiAffinityStatus struct {
+	Pod  string `json:"pod"`
+	Node string `json:"node"`
+	// RequiredViolations lists required anti-affinity terms that still match a peer in
+	// the pod's topology domain
+	RequiredViolations []string `json:"requiredViolations,omitempty"`
+	// PreferredViolations lists preferred anti-affinity terms a co-located peer matches
+	PreferredViolations []string `json:"preferredViolations,omitempty"`
+	// CoLocatedPeers is the total number of peers sharing a topology domain against the
+	// pod's preferred terms
+	CoLocatedPeers int `json:"coLocatedPeers"`
+}
+
+// Satisfied reports whether every anti-affinity term of the pod, required and preferred,
+// held in the final placement
+func (status AntiAffinityStatus) Satisfied() bool {
+	return len(status.RequiredViolations) == 0 && len(status.PreferredViolations) == 0
+}
+
+// AnalyzeAntiAffinity checks every scheduled pod that declares pod anti-affinity against
+// the final placement and returns one status per such pod, keeping the soft
+// preferred-term violations separate from the hard required-term failures
+func AnalyzeAntiAffinity(nodeStatuses []NodeStatus) []AntiAffinityStatus {
+	var statuses []AntiAffinityStatus
+
+	nodeMap := make(map[string]*corev1.Node, len(nodeStatuses))
+	var allPods []*corev1.Pod
+	for _, status := range nodeStatuses {
+		nodeMap[status.Node.Name] = status.Node
+		allPods = append(allPods, status.Pods...)
+	}
+
+	for _, nodeStatus := range nodeStatuses {
+		for _, pod := range nodeStatus.Pods {
+			if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+				continue
+			}
+			podAntiAffinity := pod.Spec.Affinity.PodAntiAffinity
+			status := AntiAffinityStatus{
+				Pod:  fmt.Sprintf("%s/%s", pod.Namespace, pod.Name),
+				Node: nodeStatus.Node.Name,
+			}
+			for _, term := range podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+				count, err := countPodsInTopologyDomain(pod, nodeStatus.Node, term, nodeMap, allPods)
+				if err != nil {
+					status.RequiredViolations = append(status.RequiredViolations, err.Error())
+					continue
+				}
+				if count > 0 {
+					status.RequiredViolations = append(status.RequiredViolations,
+						fmt.Sprintf("required term (topologyKey: %s) matches %d peer pod(s) in the same topology domain", term.TopologyKey, count))
+				}
+			}
+			for _, weightedTerm := range podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+				count, err := countPodsInTopologyDomain(pod, nodeStatus.Node, weightedTerm.PodAffinityTerm, nodeMap, allPods)
+				if err != nil {
+					status.PreferredViolations = append(status.PreferredViolations, err.Error())
+					continue
+				}
+				if count > 0 {
+					status.PreferredViolations = append(status.PreferredViolations,
+						fmt.Sprintf("preferred term (topologyKey: %s, weight: %d) is co-located with %d peer pod(s)", weightedTerm.PodAffinityTerm.TopologyKey, weightedTerm.Weight, count))
+					status.CoLocatedPeers += count
+				}
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	return statuses
+}
+
+// TopologySpreadViolation records a declared topologySpreadConstraint whose pods ended up
+// more unevenly distributed across the domains of its topology key than the constraint
+// allows
+type TopologySpreadViolation struct {
+	// TopologyKey is the node label the constraint spreads over
+	TopologyKey string `json:"topologyKey"`
+	// Namespace and Selector identify the group of pods the constraint applies to
+	Namespace string `json:"namespace"`
+	Selector  string `json:"selector"`
+	MaxSkew   int32  `json:"maxSkew"`
+	// Skew is the observed difference between the fullest and the emptiest domain
+	Skew    int32  `json:"skew"`
+	Message string `json:"message"`
+}
+
+// ValidateTopologySpread checks every topologySpreadConstraint declared by a scheduled pod
+// against the final placement and returns the constraints whose observed skew exceeds
+// their maxSkew. Identical constraints shared by the replicas of one workload are checked
+// once. Soft constraints (whenUnsatisfiable: ScheduleAnyway) are checked as well, since
+// the report is about the outcome, not about what the scheduler was allowed to do.
+func ValidateTopologySpread(nodeStatuses []NodeStatus) []TopologySpreadViolation {
+	var violations []TopologySpreadViolation
+
+	checked := make(map[string]struct{})
+	for _, status := range nodeStatuses {
+		for _, pod := range status.Pods {
+			for _, constraint := range pod.Spec.TopologySpreadConstraints {
+				selector, err := metav1.LabelSelectorAsSelector(constraint.LabelSelector)
+				if err != nil {
+					violations = append(violations, TopologySpreadViolation{
+						TopologyKey: constraint.TopologyKey,
+						Namespace:   pod.Namespace,
+						MaxSkew:     constraint.MaxSkew,
+						Message:     fmt.Sprintf("invalid label selector in topology spread constraint: %v", err),
+					})
+					continue
+				}
+				key := fmt.Sprintf("%s/%s/%s/%d", pod.Namespace, constraint.TopologyKey, selector.String(), constraint.MaxSkew)
+				if _, exist := checked[key]; exist {
+					continue
+				}
+				checked[key] = struct{}{}
+
+				// every domain of the topology key counts, including ones the group
+				// placed no pod in
+				domainPods := make(map[string]int32)
+				for _, domainStatus := range nodeStatuses {
+					domain, exist := domainStatus.Node.Labels[constraint.TopologyKey]
+					if !exist {
+						continue
+					}
+					if _, exist := domainPods[domain]; !exist {
+						domainPods[domain] = 0
+					}
+					for _, peer := range domainStatus.Pods {
+						if peer.Namespace != pod.Namespace || !selector.Matches(labels.Set(peer.Labels)) {
+							continue
+						}
+						domainPods[domain]++
+					}
+				}
+				if len(domainPods) == 0 {
+					continue
+				}
+				var min, max int32
+				first := true
+				for _, count := range domainPods {
+					if first || count < min {
+						min = count
+					}
+					if first || count > max {
+						max = count
+					}
+					first = false
+				}
+				if skew := max - min; skew > constraint.MaxSkew {
+					violations = append(violations, TopologySpreadViolation{
+						TopologyKey: constraint.TopologyKey,
+						Namespace:   pod.Namespace,
+						Selector:    selector.String(),
+						MaxSkew:     constraint.MaxSkew,
+						Skew:        skew,
+						Message: fmt.Sprintf("pods matching %q in namespace %s spread over %s with skew %d, more than the allowed %d (whenUnsatisfiable: %s)",
+							selector.String(), pod.Namespace, constraint.TopologyKey, skew, constraint.MaxSkew, constraint.WhenUnsatisfiable),
+					})
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// ServiceSpreadRequirement caps the share of a service's pods that may be placed in a
+// single failure domain, e.g. "no more than 50% of the replicas of default/frontend in
+// one zone". The scheduler itself does not guarantee such limits.
+type ServiceSpreadRequirement struct {
+	// Service is the namespace/name of the service whose endpoints are grouped
+	Service string `json:"service"`
+	// TopologyKey is the node label defining the failure domain, defaults to topology.kubernetes.io/zone
+	TopologyKey string `json:"topologyKey,omitempty"`
+	// MaxPercent is the maximum share of the service's pods allowed in one domain, in percent
+	MaxPercent int `json:"maxPercent"`
+}
+
+// SpreadViolation records a failure domain that holds a larger share of a service's pods
+// than its spread requirement allows
+type SpreadViolation struct {
+	Service    string `json:"service"`
+	Domain     string `json:"domain"`
+	Pods       int    `json:"pods"`
+	TotalPods  int    `json:"totalPods"`
+	MaxPercent int    `json:"maxPercent"`
+	Message    string `json:"message"`
+}
+
+// ValidateServiceSpread checks the scheduled pods of each service named by a requirement
+// against its per-domain concentration limit and returns the violations it finds. Pods on
+// nodes without the topology key are grouped into an unnamed domain and counted as well.
+func ValidateServiceSpread(nodeStatuses []NodeStatus, services []*corev1.Service, requirements []ServiceSpreadRequirement) []SpreadViolation {
+	var violations []SpreadViolation
+
+	serviceMap := make(map[string]*corev1.Service, len(services))
+	for _, svc := range services {
+		serviceMap[fmt.Sprintf("%s/%s", svc.Namespace, svc.Name)] = svc
+	}
+
+	for _, requirement := range requirements {
+		svc, exist := serviceMap[requirement.Service]
+		if !exist || len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		topologyKey := requirement.TopologyKey
+		if topologyKey == "" {
+			topologyKey = corev1.LabelTopologyZone
+		}
+		selector := labels.SelectorFromSet(svc.Spec.Selector)
+
+		total := 0
+		domainPods := make(map[string]int)
+		for _, status := range nodeStatuses {
+			domain := status.Node.Labels[topologyKey]
+			for _, pod := range status.Pods {
+				if pod.Namespace != svc.Namespace || !selector.Matches(labels.Set(pod.Labels)) {
+					continue
+				}
+				total++
+				domainPods[domain]++
+			}
+		}
+		if total == 0 {
+			continue
+		}
+		for domain, count := range domainPods {
+			if count*100 <= requirement.MaxPercent*total {
+				continue
+			}
+			violations = append(violations, SpreadViolation{
+				Service:    requirement.Service,
+				Domain:     domain,
+				Pods:       count,
+				TotalPods:  total,
+				MaxPercent: requirement.MaxPercent,
+				Message: fmt.Sprintf("domain %q of %s holds %d of %d pods (%d%%), more than the allowed %d%%",
+					domain, topologyKey, count, total, count*100/total, requirement.MaxPercent),
+			})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,62 @@
+package simulator
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// collectFilterFailures re-runs the filter plugins of the pod's scheduling profile
+// against every node and returns, per node, which plugin rejected the pod with which
+// message. Like the score and debug dumps the plugins run right after the pod failed, so
+// the verdicts reflect the cluster state the scheduler saw.
+func (sim *Simulator) collectFilterFailures(pod *corev1.Pod) map[string]map[string]string {
+	fwk, ok := sim.scheduler.Profiles[pod.Spec.SchedulerName]
+	if !ok {
+		return nil
+	}
+	nodeList, err := sim.fakeclient.CoreV1().Nodes().List(sim.ctx, metav1.ListOptions{})
+	if err != nil || len(nodeList.Items) == 0 {
+		return nil
+	}
+	podList, err := sim.fakeclient.CoreV1().Pods(corev1.NamespaceAll).List(sim.ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+	podsByNode := make(map[string][]*corev1.Pod)
+	for i := range podList.Items {
+		nodePod := &podList.Items[i]
+		podsByNode[nodePod.Spec.NodeName] = append(podsByNode[nodePod.Spec.NodeName], nodePod)
+	}
+
+	state := framework.NewCycleState()
+	if status := fwk.RunPreFilterPlugins(sim.ctx, state, pod); !status.IsSuccess() {
+		return nil
+	}
+
+	failures := make(map[string]map[string]string)
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		nodeInfo := framework.NewNodeInfo(podsByNode[node.Name]...)
+		nodeInfo.SetNode(node)
+		for plugin, status := range fwk.RunFilterPlugins(sim.ctx, state, pod, nodeInfo) {
+			if status.IsSuccess() {
+				continue
+			}
+			if failures[node.Name] == nil {
+				failures[node.Name] = make(map[string]string)
+			}
+			failures[node.Name][plugin] = status.Message()
+		}
+	}
+	return failures
+}
+
+// WithVerboseFailures attaches to every unscheduled pod a per-node map of the filter
+// plugins that rejected it, so placement problems can be debugged without re-running the
+// simulation under a debug dump
+func WithVerboseFailures(verboseFailures bool) Option {
+	return func(o *simulatorOptions) {
+		o.verboseFailures = verboseFailures
+	}
+}
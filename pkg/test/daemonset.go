@@ -64,3 +64,9 @@ func WithDaemonSetNodeSelector(nodeSelector map[string]string) FakeDaemonSetOpti
 		ds.Spec.Template.Spec.NodeSelector = nodeSelector
 	}
 }
+
+func WithDaemonSetPodLabels(labels map[string]string) FakeDaemonSetOption {
+	return func(ds *appsv1.DaemonSet) {
+		ds.Spec.Template.ObjectMeta.Labels = labels
+	}
+}
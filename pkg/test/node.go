@@ -53,6 +53,17 @@ func WithNodeLabels(labels map[string]string) FakeNodeOption {
 	}
 }
 
+// WithNodeResources merges extra resources, e.g. ephemeral-storage, into the node's
+// capacity and allocatable
+func WithNodeResources(resources corev1.ResourceList) FakeNodeOption {
+	return func(node *corev1.Node) {
+		for name, quantity := range resources {
+			node.Status.Capacity[name] = quantity
+			node.Status.Allocatable[name] = quantity
+		}
+	}
+}
+
 // WithNodeTaints
 func WithNodeTaints(taints []corev1.Taint) FakeNodeOption {
 	return func(node *corev1.Node) {
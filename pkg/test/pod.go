@@ -76,8 +76,26 @@ func WithPodAffinity(affinity *corev1.Affinity) FakePodOption {
 	}
 }
 
+func WithPodVolumes(volumes []corev1.Volume) FakePodOption {
+	return func(pod *corev1.Pod) {
+		pod.Spec.Volumes = volumes
+	}
+}
+
 func WithPodNodeSelector(nodeSelector map[string]string) FakePodOption {
 	return func(pod *corev1.Pod) {
 		pod.Spec.NodeSelector = nodeSelector
 	}
 }
+
+func WithPodLimits(limits corev1.ResourceList) FakePodOption {
+	return func(pod *corev1.Pod) {
+		pod.Spec.Containers[0].Resources.Limits = limits
+	}
+}
+
+func WithPodInitContainers(initContainers []corev1.Container) FakePodOption {
+	return func(pod *corev1.Pod) {
+		pod.Spec.InitContainers = initContainers
+	}
+}
@@ -5,30 +5,40 @@ import (
 )
 
 const (
-	SimonPluginName        = "Simon"
-	OpenLocalPluginName    = "Open-Local"
-	OpenGpuSharePluginName = "Open-Gpu-Share"
-	NewNodeNamePrefix      = "simon"
-	DefaultSchedulerName   = corev1.DefaultSchedulerName
+	SimonPluginName           = "Simon"
+	OpenLocalPluginName       = "Open-Local"
+	OpenGpuSharePluginName    = "Open-Gpu-Share"
+	UtilizationCapPluginName  = "UtilizationCap"
+	NodeVolumeLimitPluginName = "NodeVolumeLimit"
+	NewNodeNamePrefix         = "simon"
+	DefaultSchedulerName      = corev1.DefaultSchedulerName
+	MaxNumNewNode             = 100
 
 	StopReasonSuccess   = "everything is ok"
 	StopReasonDoNotStop = "do not stop"
 	CreatePodError      = "failed to create pod"
 	DeletePodError      = "failed to delete pod"
 
-	AnnoWorkloadKind      = "simon/workload-kind"
-	AnnoWorkloadName      = "simon/workload-name"
-	AnnoWorkloadNamespace = "simon/workload-namespace"
-	AnnoNodeLocalStorage  = "simon/node-local-storage"
-	AnnoPodLocalStorage   = "simon/pod-local-storage"
-	AnnoNodeGpuShare      = "simon/node-gpu-share"
-
-	LabelNewNode = "simon/new-node"
-	LabelAppName = "simon/app-name"
+	AnnoWorkloadKind         = "simon/workload-kind"
+	AnnoWorkloadName         = "simon/workload-name"
+	AnnoWorkloadNamespace    = "simon/workload-namespace"
+	AnnoNodeLocalStorage     = "simon/node-local-storage"
+	AnnoPodLocalStorage      = "simon/pod-local-storage"
+	AnnoNodeGpuShare         = "simon/node-gpu-share"
+	AnnoNodeReservedResource = "simon/node-reserved-resource"
+	AnnoPodStableName        = "simon/pod-stable-name"
+	AnnoPodVolumeVGs         = "simon/pod-volume-vgs"
+	AnnoNodeVolumeLimit      = "simon/node-volume-limit"
+	AnnoPodSidecarContainers = "simon/sidecar-containers"
+
+	LabelNewNode  = "simon/new-node"
+	LabelAppName  = "simon/app-name"
+	LabelGangName = "simon/gang-name"
 
 	EnvMaxCPU    = "MaxCPU"
 	EnvMaxMemory = "MaxMemory"
 	EnvMaxVG     = "MaxVG"
+	EnvMaxGPUMem = "MaxGPUMem"
 
 	Pod         = "Pod"
 	Deployment  = "Deployment"
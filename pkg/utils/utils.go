@@ -22,7 +22,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/clientcmd"
@@ -273,9 +275,11 @@ func SetStorageAnnotationOnPods(pods []*corev1.Pod, volumeClaimTemplates []corev
 				}
 				volumes.Volumes = append(volumes.Volumes, volume)
 			} else {
+				AddWarning("storage", "unsupported storage class: %s", *pvc.Spec.StorageClassName)
 				log.Errorf("unsupported storage class: %s", *pvc.Spec.StorageClassName)
 			}
 		} else {
+			AddWarning("storage", "empty storageClassName in volumeTemplate of statefulset %s is not supported", stsName)
 			log.Errorf("empty storageClassName in volumeTemplate of statefulset %s is not supported", stsName)
 		}
 	}
@@ -590,6 +594,7 @@ func GetPodLocalPVCs(pod *corev1.Pod) ([]*corev1.PersistentVolumeClaim, []*corev
 		if volume.Kind == "LVM" || volume.Kind == "HDD" || volume.Kind == "SSD" {
 			scName = volume.StorageClassName
 		} else {
+			AddWarning("storage", "unsupported volume kind: %s", volume.Kind)
 			log.Errorf("unsupported volume kind: %s", volume.Kind)
 			continue
 		}
@@ -828,6 +833,154 @@ func GetGpuNodeInfoFromAnnotation(node *corev1.Node) (*gpusharecache.NodeGpuInfo
 	return nodeGpuInfo, nil
 }
 
+// isExtendedResourceName reports whether the resource name is an extended resource,
+// i.e. not one of the resources every node advertises
+func isExtendedResourceName(name corev1.ResourceName) bool {
+	switch name {
+	case corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage, corev1.ResourcePods, corev1.ResourceStorage:
+		return false
+	}
+	if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+		return false
+	}
+	if strings.HasPrefix(string(name), corev1.ResourceAttachableVolumesPrefix) {
+		return false
+	}
+	return true
+}
+
+// CheckExtendedResourcesOfNodeTemplate returns an error when one of the pods requests an
+// extended resource (e.g. GPU) that the node template does not advertise in its allocatable.
+// Adding more nodes of such a template can never make these pods schedulable, so the caller
+// should fail fast instead of scaling out further.
+func CheckExtendedResourcesOfNodeTemplate(node *corev1.Node, pods []*corev1.Pod) error {
+	for _, pod := range pods {
+		podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+		for name := range podReqs {
+			if !isExtendedResourceName(name) {
+				continue
+			}
+			if _, exist := node.Status.Allocatable[name]; !exist {
+				return fmt.Errorf("pod %s/%s requests extended resource %s that the new node template does not advertise ", pod.Namespace, pod.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckNodeTemplateFitsPods returns an error when a pod requests more cpu or memory
+// than any of the new-node templates has allocatable. Such a pod can never be hosted no
+// matter how many nodes are added, so the caller should fail fast instead of looping up
+// to the scale-out limit.
+func CheckNodeTemplateFitsPods(templates []*corev1.Node, pods []*corev1.Pod) error {
+	for _, pod := range pods {
+		podReqs, _ := resourcehelper.PodRequestsAndLimits(pod)
+		fits := false
+		for _, template := range templates {
+			if podReqs.Cpu().MilliValue() <= template.Status.Allocatable.Cpu().MilliValue() &&
+				podReqs.Memory().Value() <= template.Status.Allocatable.Memory().Value() {
+				fits = true
+				break
+			}
+		}
+		if !fits {
+			return fmt.Errorf("pod %s/%s requests %s cpu and %s memory, more than any new-node template has allocatable ", pod.Namespace, pod.Name, podReqs.Cpu().String(), podReqs.Memory().String())
+		}
+	}
+	return nil
+}
+
+// GetNodeOS returns the operating system a node advertises via the kubernetes.io/os
+// label, falling back to the deprecated beta label
+func GetNodeOS(node *corev1.Node) string {
+	if os, exist := node.Labels[corev1.LabelOSStable]; exist {
+		return os
+	}
+	return node.Labels["beta.kubernetes.io/os"]
+}
+
+// GetPodRequiredOS returns the operating system a pod requires via the kubernetes.io/os
+// nodeSelector or required node affinity, or "" when the pod does not care about the OS
+func GetPodRequiredOS(pod *corev1.Pod) string {
+	if os, exist := pod.Spec.NodeSelector[corev1.LabelOSStable]; exist {
+		return os
+	}
+	if os, exist := pod.Spec.NodeSelector["beta.kubernetes.io/os"]; exist {
+		return os
+	}
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil || pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	for _, term := range pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, expr := range term.MatchExpressions {
+			if (expr.Key == corev1.LabelOSStable || expr.Key == "beta.kubernetes.io/os") &&
+				expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+				return expr.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+// isSupportedQoSResource reports whether a resource participates in QoS classification
+func isSupportedQoSResource(name corev1.ResourceName) bool {
+	return name == corev1.ResourceCPU || name == corev1.ResourceMemory
+}
+
+// GetPodQoSClass derives the QoS class (Guaranteed/Burstable/BestEffort) of a pod from
+// the cpu and memory requests and limits of its containers, following the rules the
+// kubelet uses to classify pods
+func GetPodQoSClass(pod *corev1.Pod) corev1.PodQOSClass {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	zeroQuantity := resource.MustParse("0")
+	isGuaranteed := true
+	allContainers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range allContainers {
+		for name, quantity := range container.Resources.Requests {
+			if !isSupportedQoSResource(name) || quantity.Cmp(zeroQuantity) != 1 {
+				continue
+			}
+			delta := quantity.DeepCopy()
+			if value, exist := requests[name]; exist {
+				delta.Add(value)
+			}
+			requests[name] = delta
+		}
+		qosLimitsFound := sets.NewString()
+		for name, quantity := range container.Resources.Limits {
+			if !isSupportedQoSResource(name) || quantity.Cmp(zeroQuantity) != 1 {
+				continue
+			}
+			qosLimitsFound.Insert(string(name))
+			delta := quantity.DeepCopy()
+			if value, exist := limits[name]; exist {
+				delta.Add(value)
+			}
+			limits[name] = delta
+		}
+		if !qosLimitsFound.HasAll(string(corev1.ResourceCPU), string(corev1.ResourceMemory)) {
+			isGuaranteed = false
+		}
+	}
+	if len(requests) == 0 && len(limits) == 0 {
+		return corev1.PodQOSBestEffort
+	}
+	// for a pod to be guaranteed, requests and limits must be specified for all resources and be equal
+	if isGuaranteed {
+		for name, req := range requests {
+			if lim, exist := limits[name]; !exist || lim.Cmp(req) != 0 {
+				isGuaranteed = false
+				break
+			}
+		}
+	}
+	if isGuaranteed && len(requests) == len(limits) {
+		return corev1.PodQOSGuaranteed
+	}
+	return corev1.PodQOSBurstable
+}
+
 func OwnedByDaemonset(refs []metav1.OwnerReference) bool {
 	for _, ref := range refs {
 		if ref.Kind == simontype.DaemonSet {
@@ -882,14 +1035,61 @@ func OwnedByWorkload(refs []metav1.OwnerReference, workload runtime.Object) bool
 	return false
 }
 
+// NodeNaming controls the naming scheme of fake nodes generated by NewFakeNodes.
+// An empty Prefix falls back to simontype.NewNodeNamePrefix and a non-positive
+// Width falls back to DefaultNodeNamingWidth.
+type NodeNaming struct {
+	Prefix string
+	Width  int
+}
+
+// DefaultNodeNamingWidth is the minimum number of digits in the ordinal suffix of a fake node name
+const DefaultNodeNamingWidth = 2
+
+func (naming NodeNaming) nodeName(ordinal int) string {
+	prefix := naming.Prefix
+	if prefix == "" {
+		prefix = simontype.NewNodeNamePrefix
+	}
+	width := naming.Width
+	if width <= 0 {
+		width = DefaultNodeNamingWidth
+	}
+	return fmt.Sprintf("%s-%0*d", prefix, width, ordinal)
+}
+
 func NewFakeNodes(node *corev1.Node, nodeCount int) ([]*corev1.Node, error) {
+	return NewFakeNodesWithNaming(node, nodeCount, NodeNaming{}, nil)
+}
+
+// NewFakeNodesWithNaming makes fake nodes named by the given naming scheme. Generated names are
+// guaranteed not to collide with each other nor with the names of existingNodes, so the fake
+// nodes can be added safely to a cluster snapshot.
+func NewFakeNodesWithNaming(node *corev1.Node, nodeCount int, naming NodeNaming, existingNodes []*corev1.Node) ([]*corev1.Node, error) {
 	if node == nil && nodeCount != 0 {
 		return nil, fmt.Errorf("new node is nil when adding node to cluster, please check whether newNode in configuration file is empty")
 	}
+	takenNames := make(map[string]struct{}, len(existingNodes))
+	for _, existingNode := range existingNodes {
+		takenNames[existingNode.Name] = struct{}{}
+	}
 	var nodes []*corev1.Node
 	// make fake nodes
+	ordinal := 0
 	for i := 0; i < nodeCount; i++ {
-		hostname := fmt.Sprintf("%s-%s", simontype.NewNodeNamePrefix, rand.String(5))
+		hostname := naming.nodeName(ordinal)
+		for {
+			if _, taken := takenNames[hostname]; !taken {
+				break
+			}
+			ordinal++
+			hostname = naming.nodeName(ordinal)
+		}
+		if errs := utilvalidation.IsDNS1123Subdomain(hostname); len(errs) != 0 {
+			return nil, fmt.Errorf("generated node name %s is not a valid DNS subdomain: %s", hostname, strings.Join(errs, ", "))
+		}
+		takenNames[hostname] = struct{}{}
+		ordinal++
 		validNode, err := MakeValidNodeByNode(node, hostname)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func makeNodeTemplate(name string) *corev1.Node {
+	res := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("8"),
+		corev1.ResourceMemory: resource.MustParse("16Gi"),
+		corev1.ResourcePods:   *resource.NewQuantity(110, resource.DecimalSI),
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: corev1.NodeStatus{
+			Capacity:    res,
+			Allocatable: res,
+		},
+	}
+}
+
+func TestNewFakeNodesWithNaming(t *testing.T) {
+	// simulate a snapshot that already contains nodes named like fake nodes
+	existingNodes := []*corev1.Node{
+		makeNodeTemplate("simon-00"),
+		makeNodeTemplate("simon-42"),
+		makeNodeTemplate("worker-1"),
+	}
+
+	nodeCount := 150
+	nodes, err := NewFakeNodesWithNaming(makeNodeTemplate("template"), nodeCount, NodeNaming{}, existingNodes)
+	if err != nil {
+		t.Fatalf("NewFakeNodesWithNaming() error = %v", err)
+	}
+	if len(nodes) != nodeCount {
+		t.Fatalf("NewFakeNodesWithNaming() generated %d nodes, want %d", len(nodes), nodeCount)
+	}
+
+	seen := make(map[string]struct{}, len(existingNodes))
+	for _, node := range existingNodes {
+		seen[node.Name] = struct{}{}
+	}
+	for _, node := range nodes {
+		if _, exist := seen[node.Name]; exist {
+			t.Errorf("node name %s collides with an existing or generated node", node.Name)
+		}
+		seen[node.Name] = struct{}{}
+	}
+}
+
+func TestNewFakeNodesPreserveExtendedResources(t *testing.T) {
+	template := makeNodeTemplate("gpu-template")
+	template.Status.Allocatable["nvidia.com/gpu"] = resource.MustParse("2")
+	template.Status.Capacity["nvidia.com/gpu"] = resource.MustParse("2")
+
+	nodes, err := NewFakeNodes(template, 2)
+	if err != nil {
+		t.Fatalf("NewFakeNodes() error = %v", err)
+	}
+	for _, node := range nodes {
+		gpu, exist := node.Status.Allocatable["nvidia.com/gpu"]
+		if !exist || gpu.Value() != 2 {
+			t.Errorf("node %s lost the extended resource allocatable of the template", node.Name)
+		}
+	}
+}
+
+func TestCheckExtendedResourcesOfNodeTemplate(t *testing.T) {
+	gpuPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu-pod", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "container",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							"nvidia.com/gpu": resource.MustParse("1"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	plainTemplate := makeNodeTemplate("template")
+	if err := CheckExtendedResourcesOfNodeTemplate(plainTemplate, []*corev1.Pod{gpuPod}); err == nil {
+		t.Errorf("CheckExtendedResourcesOfNodeTemplate() expected an error for a template without GPU")
+	}
+
+	gpuTemplate := makeNodeTemplate("gpu-template")
+	gpuTemplate.Status.Allocatable["nvidia.com/gpu"] = resource.MustParse("2")
+	if err := CheckExtendedResourcesOfNodeTemplate(gpuTemplate, []*corev1.Pod{gpuPod}); err != nil {
+		t.Errorf("CheckExtendedResourcesOfNodeTemplate() error = %v", err)
+	}
+}
+
+func makePodWithResources(requests, limits corev1.ResourceList) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "container",
+					Resources: corev1.ResourceRequirements{
+						Requests: requests,
+						Limits:   limits,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGetPodQoSClass(t *testing.T) {
+	cpuAndMemory := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	}
+	cpuOnly := corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("1"),
+	}
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want corev1.PodQOSClass
+	}{
+		{"no requests or limits", makePodWithResources(nil, nil), corev1.PodQOSBestEffort},
+		{"requests equal limits", makePodWithResources(cpuAndMemory, cpuAndMemory), corev1.PodQOSGuaranteed},
+		{"requests without limits", makePodWithResources(cpuAndMemory, nil), corev1.PodQOSBurstable},
+		{"cpu limit only", makePodWithResources(cpuOnly, cpuOnly), corev1.PodQOSBurstable},
+	}
+	for _, tt := range tests {
+		if got := GetPodQoSClass(tt.pod); got != tt.want {
+			t.Errorf("GetPodQoSClass() %s = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNodeNamingScheme(t *testing.T) {
+	tests := []struct {
+		naming  NodeNaming
+		ordinal int
+		want    string
+	}{
+		{NodeNaming{}, 0, "simon-00"},
+		{NodeNaming{}, 100, "simon-100"},
+		{NodeNaming{Prefix: "pool-a", Width: 3}, 7, "pool-a-007"},
+	}
+	for _, tt := range tests {
+		if got := tt.naming.nodeName(tt.ordinal); got != tt.want {
+			t.Errorf("nodeName(%d) = %s, want %s", tt.ordinal, got, tt.want)
+		}
+	}
+
+	// a prefix yielding names the apiserver would reject must surface as an error
+	if _, err := NewFakeNodesWithNaming(makeNodeTemplate("template"), 1, NodeNaming{Prefix: "Bad_Prefix"}, nil); err == nil {
+		t.Errorf("NewFakeNodesWithNaming() expected an error for a non-DNS prefix")
+	}
+	nodes, err := NewFakeNodesWithNaming(makeNodeTemplate("template"), 2, NodeNaming{Prefix: "pool-a"}, nil)
+	if err != nil {
+		t.Fatalf("NewFakeNodesWithNaming() error = %v", err)
+	}
+	if nodes[0].Name != "pool-a-00" || nodes[1].Name != "pool-a-01" {
+		t.Errorf("NewFakeNodesWithNaming() names = %s, %s, want pool-a-00 and pool-a-01", nodes[0].Name, nodes[1].Name)
+	}
+}
+
+func TestCheckNodeTemplateFitsPods(t *testing.T) {
+	template := makeNodeTemplate("template")
+
+	fittingPod := makePodWithResources(corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	}, nil)
+	if err := CheckNodeTemplateFitsPods([]*corev1.Node{template}, []*corev1.Pod{fittingPod}); err != nil {
+		t.Errorf("CheckNodeTemplateFitsPods() error = %v", err)
+	}
+
+	// the template has 16Gi allocatable, so a 32Gi pod can never be hosted
+	oversizedPod := makePodWithResources(corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("4"),
+		corev1.ResourceMemory: resource.MustParse("32Gi"),
+	}, nil)
+	if err := CheckNodeTemplateFitsPods([]*corev1.Node{template}, []*corev1.Pod{oversizedPod}); err == nil {
+		t.Errorf("CheckNodeTemplateFitsPods() expected an error for an oversized pod")
+	}
+}
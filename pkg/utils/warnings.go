@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Warning records a non-fatal problem hit while validating configs, decoding manifests or
+// simulating, e.g. an unsupported kind or a defaulted field. Warnings are collected
+// globally so strict mode can enumerate them at the end of a run.
+type Warning struct {
+	Source  string
+	Message string
+}
+
+var (
+	warningsMu sync.Mutex
+	warnings   []Warning
+)
+
+// AddWarning records a warning under the given source, e.g. "decode" or "storage"
+func AddWarning(source, format string, args ...interface{}) {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	warnings = append(warnings, Warning{
+		Source:  source,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// DrainWarnings returns all warnings collected so far and resets the collector
+func DrainWarnings() []Warning {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	drained := warnings
+	warnings = nil
+	return drained
+}